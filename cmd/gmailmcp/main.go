@@ -12,6 +12,7 @@ import (
 	"github.com/bttk/bttk-mcp/pkg/config"
 	"github.com/bttk/bttk-mcp/pkg/gmail"
 	"github.com/bttk/bttk-mcp/pkg/gmailmcp"
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
 	"github.com/mark3labs/mcp-go/server"
 )
 
@@ -39,34 +40,118 @@ func main() {
 		}
 	}
 
-	client, err := gmail.NewClient(cfg.Gmail.CredentialsFile, cfg.Gmail.TokenFile)
+	client, err := gmail.NewClient(cfg.Gmail.CredentialsFile, cfg.Gmail.TokenFile, cfg.Gmail.Scopes...)
 	if err != nil {
 		log.Fatalf("Failed to create Gmail client: %v", err)
 	}
+	client.MetadataHeaders = cfg.Gmail.SearchHeaders
 
-	s := server.NewMCPServer("gmailmcp", "1.0.0")
+	var serverOpts []server.ServerOption
+	if cfg.Gmail.Send.Enabled && cfg.Gmail.Send.Confirm {
+		serverOpts = append(serverOpts, server.WithElicitation())
+	}
+	s := server.NewMCPServer("gmailmcp", "1.0.0", serverOpts...)
 
 	s.AddTool(gmailmcp.GmailSearchTool(), gmailmcp.GmailSearchHandler(client))
+	s.AddTool(gmailmcp.GmailFindTool(), gmailmcp.GmailFindHandler(client))
 	s.AddTool(gmailmcp.GmailReadTool(), gmailmcp.GmailReadHandler(client))
+	s.AddTool(gmailmcp.GmailReadThreadTool(), gmailmcp.GmailReadThreadHandler(client))
+	s.AddTool(gmailmcp.GmailChangesSinceLastCheckTool(),
+		gmailmcp.GmailChangesSinceLastCheckHandler(gmail.NewSyncer(client, cfg.Gmail.SyncStateFile)))
+
+	s.AddTool(gmailmcp.GmailListLabelsTool(), gmailmcp.GmailListLabelsHandler(client, cfg.Gmail.ProtectedLabels))
+
+	attachmentOpts := attachmentOptions(cfg)
+	s.AddTool(gmailmcp.GmailGetAttachmentTool(attachmentOpts), gmailmcp.GmailGetAttachmentHandler(client, attachmentOpts))
+
+	// Drafts are never sent, but writing them needs a compose scope.
+	if gmail.CanCompose(cfg.Gmail.Scopes) {
+		s.AddTool(gmailmcp.GmailCreateDraftTool(), gmailmcp.GmailCreateDraftHandler(client))
+		s.AddTool(gmailmcp.GmailReplyDraftTool(), gmailmcp.GmailReplyDraftHandler(client))
+	}
+
+	if gmail.CanModify(cfg.Gmail.Scopes) {
+		s.AddTool(gmailmcp.GmailModifyLabelsTool(), gmailmcp.GmailModifyLabelsHandler(client, cfg.Gmail.ProtectedLabels))
+		s.AddTool(gmailmcp.GmailArchiveTool(), gmailmcp.GmailArchiveHandler(client, cfg.Gmail.ProtectedLabels))
+	}
+
+	if cfg.Gmail.Send.Enabled {
+		if !gmail.CanSend(cfg.Gmail.Scopes) {
+			log.Fatal("gmail.send is enabled, but gmail.scopes has no scope that allows sending")
+		}
+		sendOpts := gmailmcp.SendOptions{
+			Allowlist:  cfg.Gmail.Send.Allowlist,
+			DailyQuota: cfg.Gmail.Send.DailyQuota,
+			AuditLog:   cfg.Gmail.Send.AuditLog,
+		}
+		if cfg.Gmail.Send.Confirm {
+			sendOpts.Confirm = gmailmcp.ElicitConfirmation
+		}
+		s.AddTool(gmailmcp.GmailSendTool(sendOpts), gmailmcp.GmailSendHandler(client, sendOpts))
+	}
 
 	if err := serveStdio(s); err != nil {
 		fmt.Printf("Server error: %v\n", err)
 	}
 }
 
+// attachmentOptions configures where gmail_get_attachment may save files.
+// Saving to the vault needs the Obsidian settings of the config.
+func attachmentOptions(cfg *config.Config) gmailmcp.AttachmentOptions {
+	opts := gmailmcp.AttachmentOptions{
+		MaxBytes: cfg.Gmail.Attachments.MaxBytes,
+		Dir:      cfg.Gmail.Attachments.Dir,
+	}
+	if cfg.Gmail.Attachments.VaultFolder == "" || cfg.Obsidian.URL == "" {
+		return opts
+	}
+
+	var obsidianOpts []obsidian.Option
+	if cfg.Obsidian.Cert != "" {
+		obsidianOpts = append(obsidianOpts, obsidian.WithCertificate(cfg.Obsidian.Cert))
+	} else {
+		obsidianOpts = append(obsidianOpts, obsidian.WithInsecureTLS())
+	}
+	vault, err := obsidian.NewClient(cfg.Obsidian.URL, cfg.Obsidian.APIKey, obsidianOpts...)
+	if err != nil {
+		log.Fatalf("Failed to create Obsidian client: %v", err)
+	}
+	opts.Vault = vault.Vault
+	opts.VaultFolder = cfg.Gmail.Attachments.VaultFolder
+	return opts
+}
+
 func runAuth(cfg *config.Config) {
 	fmt.Println("Checking Gmail authentication...")
-	client, err := gmail.NewClient(cfg.Gmail.CredentialsFile, cfg.Gmail.TokenFile)
+	client, err := gmail.NewClient(cfg.Gmail.CredentialsFile, cfg.Gmail.TokenFile, cfg.Gmail.Scopes...)
 	if err != nil {
 		log.Fatalf("Failed to authenticate: %v", err)
 	}
 	fmt.Println("Authentication successful. Verifying API access...")
 
 	// Perform a simple search to verify the token works for API calls
-	_, err = client.SearchMessages("label:INBOX", 1)
+	_, err = client.SearchMessages("label:INBOX", "", 1)
 	if err != nil {
 		log.Fatalf("API verification failed: %v", err)
 	}
+	// A token saved before the scopes were configured lacks them.
+	granted, err := client.GrantedScopes()
+	if err != nil {
+		log.Fatalf("Scope verification failed: %v", err)
+	}
+	for _, access := range []struct {
+		name    string
+		allowed func(scopes []string) bool
+		needed  bool
+	}{
+		{"draft", gmail.CanCompose, gmail.CanCompose(cfg.Gmail.Scopes)},
+		{"label", gmail.CanModify, gmail.CanModify(cfg.Gmail.Scopes)},
+		{"send", gmail.CanSend, cfg.Gmail.Send.Enabled},
+	} {
+		if access.needed && !access.allowed(granted) {
+			log.Fatalf("The token has no %s access that gmail.scopes asks for (delete %s and run auth again)", access.name, cfg.Gmail.TokenFile)
+		}
+	}
 	fmt.Println("Gmail authentication and verification completed successfully!")
 }
 
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+func activeCommand() *command {
+	return &command{
+		name:    "active",
+		summary: "Work with the file that is open in Obsidian",
+		subcommands: []*command{
+			{name: "cat", summary: "Print the active file", setup: activeCat},
+			{name: "append", args: "[FILE]", summary: "Append the contents of FILE or stdin to the active file", setup: activeAppend},
+			{name: "patch", args: "[FILE]", summary: "Insert the contents of FILE or stdin relative to a heading, block or frontmatter field", setup: activePatch},
+		},
+	}
+}
+
+func activeCat(_ *flag.FlagSet) action {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := exactArgs(args, 0, "no arguments"); err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+
+		note, err := client.ActiveFile.GetNote(ctx)
+		if err != nil {
+			return err
+		}
+		if a.jsonOutput {
+			return a.printJSON(note)
+		}
+		_, err = fmt.Fprint(a.stdout, note.Content)
+		return err
+	}
+}
+
+func activeAppend(_ *flag.FlagSet) action {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := rangeArgs(args, 0, 1, "at most one FILE"); err != nil {
+			return err
+		}
+		content, err := a.readContent(optionalArg(args, 0))
+		if err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+		return client.ActiveFile.Append(ctx, content)
+	}
+}
+
+func activePatch(fs *flag.FlagSet) action {
+	pf := addPatchFlags(fs)
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := rangeArgs(args, 0, 1, "at most one FILE"); err != nil {
+			return err
+		}
+		op, targetType, err := pf.parse()
+		if err != nil {
+			return err
+		}
+		content, err := a.readContent(optionalArg(args, 0))
+		if err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+		return client.ActiveFile.Patch(ctx, op, targetType, *pf.target, content, pf.options()...)
+	}
+}
+
+func openCommand() *command {
+	return &command{
+		name:    "open",
+		summary: "Open files in Obsidian",
+		subcommands: []*command{
+			{name: "file", args: "PATH", summary: "Open a file in Obsidian, optionally at a heading or block", setup: openFile},
+		},
+	}
+}
+
+func openFile(fs *flag.FlagSet) action {
+	heading := fs.String("heading", "", "heading (\"Heading#Subheading\") or block reference (\"^id\") to scroll to")
+	newLeaf := fs.Bool("new-leaf", false, "open the file in a new tab")
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := exactArgs(args, 1, "PATH"); err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+		return client.Open.FileAt(ctx, args[0], *heading, *newLeaf)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bttk/bttk-mcp/pkg/config"
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+)
+
+// app holds the state shared by all commands.
+type app struct {
+	configPath string
+	jsonOutput bool
+
+	stdin  io.Reader
+	stdout io.Writer
+
+	cfg    *config.Config
+	client *obsidian.Client
+}
+
+// config returns the configuration, loading it on first use.
+func (a *app) config() (*config.Config, error) {
+	if a.cfg != nil {
+		return a.cfg, nil
+	}
+	cfg, err := config.Load(a.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	a.cfg = cfg
+	return cfg, nil
+}
+
+// obsidian returns the Obsidian client, creating it from the configuration on first use.
+func (a *app) obsidian() (*obsidian.Client, error) {
+	if a.client != nil {
+		return a.client, nil
+	}
+
+	cfg, err := a.config()
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []obsidian.Option
+	if cfg.Obsidian.Cert != "" {
+		opts = append(opts, obsidian.WithCertificate(cfg.Obsidian.Cert))
+	} else {
+		opts = append(opts, obsidian.WithInsecureTLS())
+	}
+	a.client, err = obsidian.NewClient(cfg.Obsidian.URL, cfg.Obsidian.APIKey, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return a.client, nil
+}
+
+// printJSON writes v to stdout as indented JSON.
+func (a *app) printJSON(v interface{}) error {
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// print writes text to stdout, adding a trailing newline if it is missing.
+func (a *app) print(text string) error {
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	_, err := io.WriteString(a.stdout, text)
+	return err
+}
+
+// readContent returns the contents of the named file, or of stdin if name is empty or "-".
+func (a *app) readContent(name string) (string, error) {
+	if name == "" || name == "-" {
+		data, err := io.ReadAll(a.stdin)
+		return string(data), err
+	}
+	data, err := os.ReadFile(name) //nolint:gosec // Intentional: the file is named by the user
+	return string(data), err
+}
+
+// argOrStdin returns the arguments joined by spaces, or the contents of stdin
+// if there are none (or the only argument is "-").
+func (a *app) argOrStdin(args []string) (string, error) {
+	if len(args) == 0 || (len(args) == 1 && args[0] == "-") {
+		content, err := a.readContent("-")
+		return strings.TrimSpace(content), err
+	}
+	return strings.Join(args, " "), nil
+}
+
+// exactArgs checks the number of positional arguments.
+func exactArgs(args []string, n int, names string) error {
+	if len(args) != n {
+		return fmt.Errorf("%w: expected %s", errUsage, names)
+	}
+	return nil
+}
+
+// rangeArgs checks that there are between lo and hi positional arguments.
+func rangeArgs(args []string, lo, hi int, names string) error {
+	if len(args) < lo || len(args) > hi {
+		return fmt.Errorf("%w: expected %s", errUsage, names)
+	}
+	return nil
+}
+
+// optionalArg returns args[i], or "" if there are fewer arguments.
+func optionalArg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
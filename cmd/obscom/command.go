@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+func commandCommand() *command {
+	return &command{
+		name:    "command",
+		summary: "List and run Obsidian commands",
+		subcommands: []*command{
+			{name: "list", args: "[FILTER]", summary: "List commands, optionally only those whose name or ID contains FILTER", setup: commandList},
+			{name: "run", args: "ID", summary: "Run a command by its ID", setup: commandRun},
+		},
+	}
+}
+
+func commandList(_ *flag.FlagSet) action {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := rangeArgs(args, 0, 1, "at most one FILTER"); err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+
+		commands, err := client.Commands.List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list commands: %w", err)
+		}
+		if filter := strings.ToLower(optionalArg(args, 0)); filter != "" {
+			filtered := commands[:0]
+			for _, c := range commands {
+				if strings.Contains(strings.ToLower(c.Name), filter) || strings.Contains(strings.ToLower(c.ID), filter) {
+					filtered = append(filtered, c)
+				}
+			}
+			commands = filtered
+		}
+
+		if a.jsonOutput {
+			return a.printJSON(map[string]interface{}{"commands": commands})
+		}
+		fmt.Fprintf(a.stdout, "%-30s %s\n", "NAME", "ID")
+		fmt.Fprintf(a.stdout, "%-30s %s\n", "----", "--")
+		for _, cmd := range commands {
+			fmt.Fprintf(a.stdout, "%-30s %s\n", cmd.Name, cmd.ID)
+		}
+		return nil
+	}
+}
+
+func commandRun(_ *flag.FlagSet) action {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := exactArgs(args, 1, "ID"); err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+		return client.Commands.Execute(ctx, args[0])
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+func completionCommand() *command {
+	return &command{
+		name:    "completion",
+		summary: "Generate shell completion scripts",
+		subcommands: []*command{
+			{name: "bash", summary: "Print the bash completion script (source <(obscom completion bash))", setup: completionScript(writeBashCompletion)},
+			{name: "zsh", summary: "Print the zsh completion script (source <(obscom completion zsh))", setup: completionScript(writeZshCompletion)},
+		},
+	}
+}
+
+func completionScript(write func(w io.Writer, root *command)) func(*flag.FlagSet) action {
+	return func(_ *flag.FlagSet) action {
+		return func(_ context.Context, a *app, args []string) error {
+			if err := exactArgs(args, 0, "no arguments"); err != nil {
+				return err
+			}
+			write(a.stdout, rootCommand())
+			return nil
+		}
+	}
+}
+
+// completionCase is a case of the generated completion function: the words
+// to offer after the given command path.
+type completionCase struct {
+	path  string
+	leaf  bool
+	words []string
+}
+
+// completionCases walks the command tree. Groups offer their subcommands,
+// leaf commands offer their flags.
+func completionCases(root *command) []completionCase {
+	var cases []completionCase
+	var walk func(c *command, path string)
+	walk = func(c *command, path string) {
+		if c.setup != nil {
+			fs := flag.NewFlagSet(path, flag.ContinueOnError)
+			fs.String("config", "", "")
+			fs.Bool("json", false, "")
+			c.setup(fs)
+			var flags []string
+			fs.VisitAll(func(f *flag.Flag) {
+				flags = append(flags, "-"+f.Name)
+			})
+			sort.Strings(flags)
+			cases = append(cases, completionCase{path: path, leaf: true, words: flags})
+			return
+		}
+
+		words := make([]string, 0, len(c.subcommands))
+		for _, sub := range c.subcommands {
+			words = append(words, sub.name)
+		}
+		cases = append(cases, completionCase{path: path, words: words})
+		for _, sub := range c.subcommands {
+			walk(sub, strings.TrimSpace(path+" "+sub.name))
+		}
+	}
+	walk(root, "")
+	return cases
+}
+
+func writeBashCompletion(w io.Writer, root *command) {
+	fmt.Fprintf(w, `# bash completion for %[1]s
+
+_%[1]s() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" path="" words="" i
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${COMP_WORDS[i]}" in
+            -*) ;;
+            *) path="${path:+$path }${COMP_WORDS[i]}" ;;
+        esac
+    done
+
+    case "$path" in
+`, root.name)
+	for _, c := range completionCases(root) {
+		pattern := fmt.Sprintf("%q", c.path)
+		if c.leaf {
+			// Positional arguments of a leaf command extend the path.
+			pattern += fmt.Sprintf("|%q*", c.path+" ")
+		}
+		fmt.Fprintf(w, "        %s) words=%q ;;\n", pattern, strings.Join(c.words, " "))
+	}
+	fmt.Fprintf(w, `    esac
+
+    # Leaf commands complete flags only after "-", and files otherwise.
+    if [[ "$cur" == -* || " $words " != *" -json "* ]]; then
+        COMPREPLY=($(compgen -W "$words" -- "$cur"))
+    fi
+}
+
+complete -o default -F _%[1]s %[1]s
+`, root.name)
+}
+
+func writeZshCompletion(w io.Writer, root *command) {
+	fmt.Fprintf(w, "#compdef %s\n\n", root.name)
+	fmt.Fprintln(w, "autoload -U +X bashcompinit && bashcompinit")
+	fmt.Fprintln(w)
+	writeBashCompletion(w, root)
+}
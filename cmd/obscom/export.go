@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+)
+
+func exportCommand() *command {
+	return &command{
+		name:    "export",
+		summary: "Export notes as HTML, plain text or JSON",
+		subcommands: []*command{
+			{name: "note", args: "PATH", summary: "Export a note, with its embeds, as a standalone document", setup: exportNote},
+			{name: "folder", args: "[DIR]", summary: "Export the notes of a folder (default the whole vault) as one document", setup: exportFolder},
+		},
+	}
+}
+
+// exportFlags are the flags shared by the export commands.
+type exportFlags struct {
+	format *string
+	output *string
+}
+
+func addExportFlags(fs *flag.FlagSet) exportFlags {
+	return exportFlags{
+		format: fs.String("format", obsidian.ExportHTML, "output format: "+strings.Join(obsidian.ExportFormats(), ", ")),
+		output: fs.String("o", "", "write the export to this file instead of stdout"),
+	}
+}
+
+func (f exportFlags) check() error {
+	if !slices.Contains(obsidian.ExportFormats(), *f.format) {
+		return fmt.Errorf("%w: unknown -format %q", errUsage, *f.format)
+	}
+	return nil
+}
+
+// write prints the export, or saves it to the -o file. With -json, the
+// export and its metadata are printed as JSON.
+func (f exportFlags) write(a *app, export *obsidian.Export) error {
+	if *f.output != "" {
+		if err := os.WriteFile(*f.output, []byte(export.Content), 0o600); err != nil {
+			return err
+		}
+		if a.jsonOutput {
+			export.Content = ""
+			return a.printJSON(export)
+		}
+		return a.print(fmt.Sprintf("exported %d notes to %s", len(export.Notes), *f.output))
+	}
+	if a.jsonOutput {
+		return a.printJSON(export)
+	}
+	return a.print(export.Content)
+}
+
+func exportNote(fs *flag.FlagSet) action {
+	flags := addExportFlags(fs)
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := exactArgs(args, 1, "PATH"); err != nil {
+			return err
+		}
+		if err := flags.check(); err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+
+		export, err := client.Export.Note(ctx, args[0], *flags.format)
+		if err != nil {
+			return err
+		}
+		return flags.write(a, export)
+	}
+}
+
+func exportFolder(fs *flag.FlagSet) action {
+	flags := addExportFlags(fs)
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := rangeArgs(args, 0, 1, "at most one DIR"); err != nil {
+			return err
+		}
+		if err := flags.check(); err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+
+		export, err := client.Export.Folder(ctx, optionalArg(args, 0), *flags.format)
+		if err != nil {
+			return err
+		}
+		return flags.write(a, export)
+	}
+}
@@ -1,53 +1,178 @@
+// Command obscom is a command-line client for the Obsidian Local REST API.
+//
+// Usage:
+//
+//	obscom <command> <subcommand> [flags] [args]
+//
+// Run "obscom help" for the list of commands.
 package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
-
-	"github.com/bttk/bttk-mcp/pkg/config"
-	"github.com/bttk/bttk-mcp/pkg/obsidian"
-	"github.com/rs/zerolog/log"
+	"os/signal"
+	"strings"
 )
 
+// errUsage is returned by commands that were called with wrong arguments.
+var errUsage = errors.New("invalid arguments")
+
+// action runs a leaf command with its positional arguments.
+type action func(ctx context.Context, a *app, args []string) error
+
+// command is a node in the command tree. Leaf commands have setup, groups
+// have subcommands.
+type command struct {
+	name    string
+	args    string
+	summary string
+
+	subcommands []*command
+	// setup registers the command's flags and returns the function that runs it.
+	setup func(fs *flag.FlagSet) action
+}
+
+func rootCommand() *command {
+	return &command{
+		name: "obscom",
+		subcommands: []*command{
+			vaultCommand(),
+			exportCommand(),
+			activeCommand(),
+			searchCommand(),
+			periodicCommand(),
+			commandCommand(),
+			openCommand(),
+			completionCommand(),
+		},
+	}
+}
+
+func (c *command) find(name string) *command {
+	for _, sub := range c.subcommands {
+		if sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
 func main() {
-	if len(os.Args) < 3 || os.Args[1] != "command" || os.Args[2] != "list" {
-		fmt.Println("Usage: obscom command list")
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	a := &app{stdin: os.Stdin, stdout: os.Stdout}
+	if err := run(ctx, a, os.Args[1:], os.Stderr); err != nil {
+		if !errors.Is(err, errUsage) && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "obscom: %v\n", err)
+		}
+		stop()
+		os.Exit(exitCode(err))
 	}
+}
 
-	fs := flag.NewFlagSet("obscom", flag.ExitOnError)
-	configPath := fs.String("config", "", "path to the configuration file (default: ~/.config/bttk-mcp/config.json)")
-	_ = fs.Parse(os.Args[3:])
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2 //nolint:mnd
+	default:
+		return 1
+	}
+}
 
-	// Initialize zerolog
-	// log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+// run resolves the command named by args and runs it. Usage and flag errors
+// are written to stderr.
+func run(ctx context.Context, a *app, args []string, stderr io.Writer) error {
+	root := rootCommand()
+	path := []string{root.name}
+	cmd := root
 
-	cfg, err := config.Load(*configPath)
-	if err != nil {
-		log.Fatal().Err(err).Msg("failed to load config")
+	for cmd.setup == nil {
+		if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			printGroupUsage(stderr, cmd, path)
+			if len(args) == 0 {
+				return errUsage
+			}
+			return flag.ErrHelp
+		}
+		sub := cmd.find(args[0])
+		if sub == nil {
+			fmt.Fprintf(stderr, "unknown command %q\n\n", strings.Join(append(path[1:], args[0]), " "))
+			printGroupUsage(stderr, cmd, path)
+			return errUsage
+		}
+		cmd = sub
+		path = append(path, sub.name)
+		args = args[1:]
 	}
 
-	var opts []obsidian.Option
-	if cfg.Obsidian.Cert != "" {
-		opts = append(opts, obsidian.WithCertificate(cfg.Obsidian.Cert))
-	} else {
-		opts = append(opts, obsidian.WithInsecureTLS())
+	fs := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&a.configPath, "config", "", "path to the configuration file (default: ~/.config/bttk-mcp/config.json)")
+	fs.BoolVar(&a.jsonOutput, "json", false, "print the output as JSON")
+	act := cmd.setup(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s [flags] %s\n\n%s\n\nFlags:\n", fs.Name(), cmd.args, cmd.summary)
+		fs.PrintDefaults()
 	}
-	client, err := obsidian.NewClient(cfg.Obsidian.URL, cfg.Obsidian.APIKey, opts...)
+
+	positional, err := parseInterspersed(fs, args)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to create client")
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
 	}
 
-	commands, err := client.Commands.List(context.Background())
-	if err != nil {
-		log.Fatal().Err(err).Msg("failed to list commands")
+	err = act(ctx, a, positional)
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(stderr, "%s: %v\n\n", fs.Name(), err)
+		fs.Usage()
 	}
+	return err
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments, and returns the positional arguments. Arguments
+// after "--" are positional, even if they start with "-".
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if parsed := len(args) - len(rest); parsed > 0 && args[parsed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		args = rest
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
 
-	fmt.Printf("%-30s %s\n", "NAME", "ID")
-	fmt.Printf("%-30s %s\n", "----", "--")
-	for _, cmd := range commands {
-		fmt.Printf("%-30s %s\n", cmd.Name, cmd.ID)
+func printGroupUsage(w io.Writer, cmd *command, path []string) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags] [args]\n\nCommands:\n", strings.Join(path, " "))
+	var walk func(c *command, prefix string)
+	walk = func(c *command, prefix string) {
+		for _, sub := range c.subcommands {
+			name := strings.TrimSpace(prefix + " " + sub.name)
+			if sub.setup != nil {
+				fmt.Fprintf(w, "  %-36s %s\n", strings.TrimSpace(name+" "+sub.args), sub.summary)
+				continue
+			}
+			walk(sub, name)
+		}
 	}
+	walk(cmd, "")
+	fmt.Fprintf(w, "\nEvery command accepts -config and -json. Run \"%s <command> -h\" for its flags.\n", strings.Join(path, " "))
 }
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bttk/bttk-mcp/pkg/config"
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runCLI runs obscom with args against a fake Obsidian server and returns stdout.
+func runCLI(t *testing.T, handler http.HandlerFunc, stdin string, args ...string) (string, error) {
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	client, err := obsidian.NewClient(ts.URL, "test-token")
+	require.NoError(t, err)
+
+	var stdout bytes.Buffer
+	a := &app{stdin: strings.NewReader(stdin), stdout: &stdout, cfg: &config.Config{}, client: client}
+	err = run(context.Background(), a, args, io.Discard)
+	return stdout.String(), err
+}
+
+func TestVaultPut_Stdin(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/vault/notes/new.md", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "# New\n", string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	_, err := runCLI(t, handler, "# New\n", "vault", "put", "notes/new.md")
+	require.NoError(t, err)
+}
+
+func TestVaultPut_DoubleDash(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/vault/-draft.md", r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	// Arguments after "--" are positional, even after another positional one.
+	_, err := runCLI(t, handler, "# Draft\n", "vault", "put", "--", "-draft.md")
+	require.NoError(t, err)
+
+	fs := flag.NewFlagSet("put", flag.ContinueOnError)
+	fs.Bool("json", false, "")
+	args, err := parseInterspersed(fs, []string{"a", "-json", "--", "-b", "--"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "-b", "--"}, args)
+}
+
+func TestVaultPatch(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/vault/note.md", r.URL.Path)
+		assert.Equal(t, "prepend", r.Header.Get("Operation"))
+		assert.Equal(t, "heading", r.Header.Get("Target-Type"))
+		assert.Equal(t, "Tasks", r.Header.Get("Target"))
+		w.WriteHeader(http.StatusOK)
+	}
+
+	// Flags may come after the positional arguments.
+	_, err := runCLI(t, handler, "- [ ] item\n", "vault", "patch", "note.md", "-op", "prepend", "-target", "Tasks")
+	require.NoError(t, err)
+
+	_, err = runCLI(t, handler, "", "vault", "patch", "note.md", "-op", "upsert", "-target", "Tasks")
+	assert.ErrorIs(t, err, errUsage)
+}
+
+func TestVaultLs_JSON(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/vault/Projects/", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"files": ["a.md", "sub/"]}`)
+	}
+
+	out, err := runCLI(t, handler, "", "vault", "ls", "-json", "Projects")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"files": ["a.md", "sub/"]}`, out)
+
+	out, err = runCLI(t, handler, "", "vault", "ls", "Projects")
+	require.NoError(t, err)
+	assert.Equal(t, "a.md\nsub/\n", out)
+}
+
+func TestSearchJSONLogic_Stdin(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search/", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"filename": "a.md", "result": true}]`)
+	}
+
+	out, err := runCLI(t, handler, `{"glob": ["*.md", {"var": "path"}]}`, "search", "jsonlogic")
+	require.NoError(t, err)
+	assert.Equal(t, "a.md\n", out)
+
+	_, err = runCLI(t, handler, `{"var": "title"}`, "search", "jsonlogic")
+	assert.ErrorIs(t, err, obsidian.ErrInvalidQuery)
+}
+
+func TestCommandRun(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/commands/editor:save-file/", r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	_, err := runCLI(t, handler, "", "command", "run", "editor:save-file")
+	require.NoError(t, err)
+}
+
+func TestUnknownCommand(t *testing.T) {
+	_, err := runCLI(t, nil, "", "vault", "mv")
+	assert.ErrorIs(t, err, errUsage)
+}
+
+func TestCompletionBash(t *testing.T) {
+	out, err := runCLI(t, nil, "", "completion", "bash")
+	require.NoError(t, err)
+	assert.Contains(t, out, `"vault") words="ls cat put append patch rm resolve dupes lint" ;;`)
+	assert.Contains(t, out, `"vault patch"|"vault patch "*) words="-config -create -json -op -target -type" ;;`)
+	assert.Contains(t, out, "complete -o default -F _obscom obscom")
+}
+
+func TestVaultDupes(t *testing.T) {
+	note := strings.Repeat("notes from the design review of the sync protocol ", 3)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"files": ["a.md", "b.md"]}`)
+		default:
+			fmt.Fprint(w, note)
+		}
+	}
+
+	out, err := runCLI(t, handler, "", "vault", "dupes", "-min-words", "5")
+	require.NoError(t, err)
+	assert.Equal(t, "exact:\n  a.md (27 words)\n  b.md (27 words)\n1 clusters in 2 notes\n", out)
+}
+
+func TestVaultResolve(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vault/":
+			fmt.Fprint(w, `{"files": ["Q3 Planning.md", "Q4 Planning.md"]}`)
+		default:
+			fmt.Fprint(w, `{"content": "Plans."}`)
+		}
+	}
+
+	out, err := runCLI(t, handler, "", "vault", "resolve", "-limit", "1", "q3 planing")
+	require.NoError(t, err)
+	assert.Equal(t, "0.91\tQ3 Planning.md\t(filename: Q3 Planning)\n", out)
+}
+
+func TestVaultLint(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vault/":
+			fmt.Fprint(w, `{"files": ["a.md"]}`)
+		default:
+			fmt.Fprint(w, `{"path": "a.md", "content": "Nothing here but a link to [[b]]."}`)
+		}
+	}
+
+	out, err := runCLI(t, handler, "", "vault", "lint", "-checks", "broken_link,stub_note")
+	require.NoError(t, err)
+	assert.Equal(t, "a.md: stub_note: note has only 7 words\na.md:1: broken_link: link target \"b\" does not exist\n2 issues in 1 notes\n", out)
+
+	_, err = runCLI(t, handler, "", "vault", "lint", "-checks", "typos")
+	assert.ErrorIs(t, err, errUsage)
+}
+
+func TestExportNote(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vault/":
+			fmt.Fprint(w, `{"files": ["plan.md"]}`)
+		default:
+			fmt.Fprint(w, `{"path": "plan.md", "content": "---\nstatus: draft\n---\n- **ship** it\n"}`)
+		}
+	}
+
+	out, err := runCLI(t, handler, "", "export", "note", "plan.md", "-format", "text")
+	require.NoError(t, err)
+	assert.Equal(t, "- ship it\n", out)
+
+	file := filepath.Join(t.TempDir(), "plan.html")
+	out, err = runCLI(t, handler, "", "export", "note", "plan.md", "-o", file)
+	require.NoError(t, err)
+	assert.Equal(t, "exported 1 notes to "+file+"\n", out)
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<title>plan</title>")
+	assert.Contains(t, string(data), "<li><strong>ship</strong> it</li>")
+
+	_, err = runCLI(t, handler, "", "export", "note", "plan.md", "-format", "pdf")
+	assert.ErrorIs(t, err, errUsage)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+)
+
+func periodicCommand() *command {
+	return &command{
+		name:    "periodic",
+		summary: "Work with periodic (daily, weekly, ...) notes",
+		subcommands: []*command{
+			{name: "get", summary: "Print the current periodic note, or the note for -date", setup: periodicGet},
+			{name: "append", args: "[FILE]", summary: "Append the contents of FILE or stdin to the current periodic note", setup: periodicAppend},
+		},
+	}
+}
+
+func addPeriodFlag(fs *flag.FlagSet) *string {
+	return fs.String("period", "daily", "period: daily, weekly, monthly, quarterly or yearly")
+}
+
+func checkPeriod(period string) error {
+	switch period {
+	case "daily", "weekly", "monthly", "quarterly", "yearly":
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown -period %q", errUsage, period)
+	}
+}
+
+func periodicGet(fs *flag.FlagSet) action {
+	period := addPeriodFlag(fs)
+	date := fs.String("date", "", "date of the note (YYYY-MM-DD)")
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := exactArgs(args, 0, "no arguments"); err != nil {
+			return err
+		}
+		if err := checkPeriod(*period); err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+
+		var note *obsidian.Note
+		if *date == "" {
+			note, err = client.Periodic.GetCurrentNote(ctx, *period)
+		} else {
+			note, err = periodicNoteAt(ctx, client, *period, *date)
+		}
+		if err != nil {
+			return err
+		}
+
+		if a.jsonOutput {
+			return a.printJSON(note)
+		}
+		_, err = fmt.Fprint(a.stdout, note.Content)
+		return err
+	}
+}
+
+func periodicNoteAt(ctx context.Context, client *obsidian.Client, period, date string) (*obsidian.Note, error) {
+	t, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad -date %q, expected YYYY-MM-DD", errUsage, date)
+	}
+	content, err := client.Periodic.Get(ctx, period, t.Year(), int(t.Month()), t.Day())
+	if err != nil {
+		return nil, err
+	}
+	return &obsidian.Note{Content: content}, nil
+}
+
+func periodicAppend(fs *flag.FlagSet) action {
+	period := addPeriodFlag(fs)
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := rangeArgs(args, 0, 1, "at most one FILE"); err != nil {
+			return err
+		}
+		if err := checkPeriod(*period); err != nil {
+			return err
+		}
+		content, err := a.readContent(optionalArg(args, 0))
+		if err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+		return client.Periodic.AppendToCurrent(ctx, *period, content)
+	}
+}
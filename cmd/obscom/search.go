@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+)
+
+func searchCommand() *command {
+	return &command{
+		name:    "search",
+		summary: "Search the vault",
+		subcommands: []*command{
+			{name: "simple", args: "QUERY...", summary: "Search for text, ranked by relevance", setup: searchSimple},
+			{name: "jsonlogic", args: "[QUERY]", summary: "Search with a JsonLogic query (read from stdin if QUERY is omitted)", setup: searchJSONLogic},
+			{name: "dql", args: "[QUERY]", summary: "Search with a Dataview TABLE query (read from stdin if QUERY is omitted)", setup: searchDQL},
+		},
+	}
+}
+
+func searchSimple(fs *flag.FlagSet) action {
+	contextLength := fs.Int("context", 100, "number of characters of context around each match") //nolint:mnd
+	limit := fs.Int("limit", 0, "maximum number of files to print (0 for all)")
+	return func(ctx context.Context, a *app, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("%w: expected QUERY", errUsage)
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+
+		results, err := client.Search.Simple(ctx, strings.Join(args, " "), *contextLength)
+		if err != nil {
+			return err
+		}
+		for i := range results {
+			results[i].DedupeMatches(*contextLength)
+		}
+		obsidian.SortSearchResults(results)
+		if *limit > 0 && len(results) > *limit {
+			results = results[:*limit]
+		}
+
+		if a.jsonOutput {
+			return a.printJSON(map[string]interface{}{"results": results})
+		}
+		for _, r := range results {
+			if _, err := fmt.Fprintf(a.stdout, "%s (score %.2f)\n", r.Filename, r.Score); err != nil {
+				return err
+			}
+			for _, m := range r.Matches {
+				context := strings.Join(strings.Fields(m.Context), " ")
+				if _, err := fmt.Fprintf(a.stdout, "    %s\n", context); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func searchJSONLogic(_ *flag.FlagSet) action {
+	return func(ctx context.Context, a *app, args []string) error {
+		text, err := a.argOrStdin(args)
+		if err != nil {
+			return err
+		}
+		var query interface{}
+		if err := json.Unmarshal([]byte(text), &query); err != nil {
+			return fmt.Errorf("failed to parse query: %w", err)
+		}
+		if err := obsidian.ValidateJSONLogic(query); err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+
+		results, err := client.Search.JSONLogic(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		if a.jsonOutput {
+			return a.printJSON(map[string]interface{}{"results": results})
+		}
+		for _, r := range results {
+			if err := a.print(r.Filename); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func searchDQL(_ *flag.FlagSet) action {
+	return func(ctx context.Context, a *app, args []string) error {
+		dql, err := a.argOrStdin(args)
+		if err != nil {
+			return err
+		}
+		if dql == "" {
+			return fmt.Errorf("%w: expected QUERY", errUsage)
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+
+		table, err := client.Search.DataviewTable(ctx, dql)
+		if err != nil {
+			return obsidian.ExplainDataviewError(err)
+		}
+
+		if a.jsonOutput {
+			return a.printJSON(table)
+		}
+		return a.print(table.Markdown())
+	}
+}
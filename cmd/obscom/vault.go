@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+)
+
+func vaultCommand() *command {
+	return &command{
+		name:    "vault",
+		summary: "Manage files in the vault",
+		subcommands: []*command{
+			{name: "ls", args: "[DIR]", summary: "List files in a directory of the vault", setup: vaultLs},
+			{name: "cat", args: "PATH", summary: "Print a file", setup: vaultCat},
+			{name: "put", args: "PATH [FILE]", summary: "Create or replace a file with the contents of FILE or stdin", setup: vaultPut},
+			{name: "append", args: "PATH [FILE]", summary: "Append the contents of FILE or stdin to a file", setup: vaultAppend},
+			{name: "patch", args: "PATH [FILE]", summary: "Insert the contents of FILE or stdin relative to a heading, block or frontmatter field", setup: vaultPatch},
+			{name: "rm", args: "PATH", summary: "Delete a file", setup: vaultRm},
+			{name: "resolve", args: "NAME", summary: "Find notes by file name, alias or title", setup: vaultResolve},
+			{name: "dupes", summary: "Find duplicate and near-duplicate notes", setup: vaultDupes},
+			{name: "lint", summary: "Report broken links, empty notes, bad frontmatter and other problems", setup: vaultLint},
+		},
+	}
+}
+
+func vaultLs(_ *flag.FlagSet) action {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := rangeArgs(args, 0, 1, "at most one DIR"); err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+
+		dir := optionalArg(args, 0)
+		if dir != "" && dir[len(dir)-1] != '/' {
+			dir += "/"
+		}
+		files, err := client.Vault.List(ctx, dir)
+		if err != nil {
+			return err
+		}
+
+		if a.jsonOutput {
+			return a.printJSON(map[string]interface{}{"files": files})
+		}
+		for _, f := range files {
+			if err := a.print(f); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func vaultCat(_ *flag.FlagSet) action {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := exactArgs(args, 1, "PATH"); err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+
+		if a.jsonOutput {
+			note, err := client.Vault.GetNote(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return a.printJSON(note)
+		}
+		content, err := client.Vault.Get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(a.stdout, content)
+		return err
+	}
+}
+
+func vaultPut(_ *flag.FlagSet) action {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := rangeArgs(args, 1, 2, "PATH [FILE]"); err != nil {
+			return err
+		}
+		content, err := a.readContent(optionalArg(args, 1))
+		if err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+		return client.Vault.Create(ctx, args[0], content)
+	}
+}
+
+func vaultAppend(_ *flag.FlagSet) action {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := rangeArgs(args, 1, 2, "PATH [FILE]"); err != nil {
+			return err
+		}
+		content, err := a.readContent(optionalArg(args, 1))
+		if err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+		return client.Vault.Append(ctx, args[0], content)
+	}
+}
+
+// patchFlags are the flags shared by the patch commands.
+type patchFlags struct {
+	op         *string
+	targetType *string
+	target     *string
+	create     *bool
+}
+
+func addPatchFlags(fs *flag.FlagSet) patchFlags {
+	return patchFlags{
+		op:         fs.String("op", string(obsidian.PatchAppend), "operation: append, prepend or replace"),
+		targetType: fs.String("type", string(obsidian.TargetHeading), "target type: heading, block or frontmatter"),
+		target:     fs.String("target", "", "heading (\"H1::H2\" for nested headings), block reference or frontmatter field"),
+		create:     fs.Bool("create", false, "create the target if it does not exist"),
+	}
+}
+
+// options returns the patch options selected by the flags.
+func (f patchFlags) options() []obsidian.PatchOption {
+	if *f.create {
+		return []obsidian.PatchOption{obsidian.WithCreateTargetIfMissing()}
+	}
+	return nil
+}
+
+func (f patchFlags) parse() (obsidian.PatchOperation, obsidian.TargetType, error) {
+	op := obsidian.PatchOperation(*f.op)
+	switch op {
+	case obsidian.PatchAppend, obsidian.PatchPrepend, obsidian.PatchReplace:
+	default:
+		return "", "", fmt.Errorf("%w: unknown -op %q", errUsage, *f.op)
+	}
+
+	targetType := obsidian.TargetType(*f.targetType)
+	switch targetType {
+	case obsidian.TargetHeading, obsidian.TargetBlock, obsidian.TargetFrontmatter:
+	default:
+		return "", "", fmt.Errorf("%w: unknown -type %q", errUsage, *f.targetType)
+	}
+
+	if *f.target == "" {
+		return "", "", fmt.Errorf("%w: -target is required", errUsage)
+	}
+	return op, targetType, nil
+}
+
+func vaultPatch(fs *flag.FlagSet) action {
+	pf := addPatchFlags(fs)
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := rangeArgs(args, 1, 2, "PATH [FILE]"); err != nil {
+			return err
+		}
+		op, targetType, err := pf.parse()
+		if err != nil {
+			return err
+		}
+		content, err := a.readContent(optionalArg(args, 1))
+		if err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+		return client.Vault.Patch(ctx, args[0], op, targetType, *pf.target, content, pf.options()...)
+	}
+}
+
+func vaultRm(_ *flag.FlagSet) action {
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := exactArgs(args, 1, "PATH"); err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+		return client.Vault.Delete(ctx, args[0])
+	}
+}
+
+func vaultResolve(fs *flag.FlagSet) action {
+	limit := fs.Int("limit", obsidian.DefaultResolveLimit, "maximum number of matches")
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := exactArgs(args, 1, "NAME"); err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+
+		res, err := client.Analysis.ResolveNote(ctx, args[0], *limit)
+		if err != nil {
+			return err
+		}
+
+		if a.jsonOutput {
+			return a.printJSON(res)
+		}
+		for _, m := range res.Matches {
+			if err := a.print(fmt.Sprintf("%.2f\t%s\t(%s: %s)", m.Score, m.Path, m.Field, m.Matched)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func vaultDupes(fs *flag.FlagSet) action {
+	folder := fs.String("folder", "", "only compare notes in this folder, including subfolders")
+	threshold := fs.Float64("threshold", obsidian.DefaultDuplicateThreshold, "minimum similarity (0-1) of near-duplicates")
+	minWords := fs.Int("min-words", obsidian.DefaultMinWords, "skip notes with fewer words")
+	shingleSize := fs.Int("shingle-size", obsidian.DefaultShingleSize, "number of consecutive words compared as a unit")
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := exactArgs(args, 0, "no arguments"); err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+
+		report, err := client.Analysis.FindDuplicates(ctx, obsidian.DuplicateOptions{
+			Folder:      *folder,
+			Threshold:   *threshold,
+			MinWords:    *minWords,
+			ShingleSize: *shingleSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		if a.jsonOutput {
+			return a.printJSON(report)
+		}
+		for _, c := range report.Clusters {
+			kind := "exact"
+			if !c.Exact {
+				kind = fmt.Sprintf("similar %.0f%%", c.Similarity*100) //nolint:mnd
+			}
+			if err := a.print(fmt.Sprintf("%s:", kind)); err != nil {
+				return err
+			}
+			for _, f := range c.Files {
+				if err := a.print(fmt.Sprintf("  %s (%d words)", f.Path, f.Words)); err != nil {
+					return err
+				}
+			}
+		}
+		for _, f := range report.Failed {
+			if err := a.print(fmt.Sprintf("failed to read %s: %s", f.Path, f.Error)); err != nil {
+				return err
+			}
+		}
+		return a.print(fmt.Sprintf("%d clusters in %d notes", len(report.Clusters), report.Scanned))
+	}
+}
+
+func vaultLint(fs *flag.FlagSet) action {
+	folder := fs.String("folder", "", "only check notes in this folder, including subfolders")
+	checks := fs.String("checks", "", "comma-separated checks to run (default all): "+strings.Join(obsidian.LintChecks(), ", "))
+	return func(ctx context.Context, a *app, args []string) error {
+		if err := exactArgs(args, 0, "no arguments"); err != nil {
+			return err
+		}
+		cfg, err := a.config()
+		if err != nil {
+			return err
+		}
+		client, err := a.obsidian()
+		if err != nil {
+			return err
+		}
+
+		opts := obsidian.LintOptions{
+			Folder:             *folder,
+			StubWords:          cfg.Obsidian.Lint.StubWords,
+			RequiredProperties: cfg.Obsidian.Lint.RequiredProperties,
+		}
+		if *checks != "" {
+			opts.Checks = strings.Split(*checks, ",")
+			for _, c := range opts.Checks {
+				if !slices.Contains(obsidian.LintChecks(), c) {
+					return fmt.Errorf("%w: unknown check %q", errUsage, c)
+				}
+			}
+		}
+		report, err := client.Analysis.Lint(ctx, opts)
+		if err != nil {
+			return err
+		}
+
+		if a.jsonOutput {
+			return a.printJSON(report)
+		}
+		for _, issue := range report.Issues {
+			location := issue.Path
+			if issue.Line > 0 {
+				location = fmt.Sprintf("%s:%d", issue.Path, issue.Line)
+			}
+			if err := a.print(fmt.Sprintf("%s: %s: %s", location, issue.Check, issue.Message)); err != nil {
+				return err
+			}
+		}
+		for _, f := range report.Failed {
+			if err := a.print(fmt.Sprintf("%s: failed to read: %s", f.Path, f.Error)); err != nil {
+				return err
+			}
+		}
+		for _, check := range report.Skipped {
+			if err := a.print(fmt.Sprintf("%s: skipped because some notes could not be read", check)); err != nil {
+				return err
+			}
+		}
+		return a.print(fmt.Sprintf("%d issues in %d notes", len(report.Issues), report.Scanned))
+	}
+}
@@ -69,24 +69,78 @@ func main() {
 		"search_json_logic": func() {
 			s.AddTool(obsidianmcp.SearchJSONLogicTool(), obsidianmcp.SearchJSONLogicHandler(client))
 		},
+		"find_notes": func() {
+			s.AddTool(obsidianmcp.FindNotesTool(), obsidianmcp.FindNotesHandler(client))
+		},
 		"search_dql": func() {
 			s.AddTool(obsidianmcp.SearchDQLTool(), obsidianmcp.SearchDQLHandler(client))
 		},
+		"capture": func() {
+			s.AddTool(obsidianmcp.CaptureTool(), obsidianmcp.CaptureHandler(client, obsidianmcp.CaptureConfig{
+				Heading:         cfg.Obsidian.Capture.Heading,
+				Template:        cfg.Obsidian.Capture.Template,
+				TimestampFormat: cfg.Obsidian.Capture.TimestampFormat,
+			}))
+		},
+		"clip": func() {
+			s.AddTool(obsidianmcp.ClipTool(), obsidianmcp.ClipHandler(client, obsidianmcp.ClipConfig{
+				Folder: cfg.Obsidian.Clip.Folder,
+			}))
+		},
 		"get_daily_note": func() {
 			s.AddTool(obsidianmcp.GetDailyNoteTool(), obsidianmcp.GetDailyNoteHandler(client))
 		},
 		"get_file": func() {
 			s.AddTool(obsidianmcp.GetFileTool(), obsidianmcp.GetFileHandler(client))
 		},
+		"resolve_note": func() {
+			s.AddTool(obsidianmcp.ResolveNoteTool(), obsidianmcp.ResolveNoteHandler(client))
+		},
 		"list_files": func() {
 			s.AddTool(obsidianmcp.ListFilesTool(), obsidianmcp.ListFilesHandler(client))
 		},
 		"create_or_update_file": func() {
 			s.AddTool(obsidianmcp.CreateOrUpdateFileTool(), obsidianmcp.CreateOrUpdateFileHandler(client))
 		},
+		"edit_file": func() {
+			s.AddTool(obsidianmcp.EditFileTool(), obsidianmcp.EditFileHandler(client))
+		},
+		"list_bookmarks": func() {
+			s.AddTool(obsidianmcp.ListBookmarksTool(), obsidianmcp.ListBookmarksHandler(client))
+		},
+		"add_bookmark": func() {
+			s.AddTool(obsidianmcp.AddBookmarkTool(), obsidianmcp.AddBookmarkHandler(client))
+		},
+		"remove_bookmark": func() {
+			s.AddTool(obsidianmcp.RemoveBookmarkTool(), obsidianmcp.RemoveBookmarkHandler(client))
+		},
+		"export_note": func() {
+			s.AddTool(obsidianmcp.ExportNoteTool(), obsidianmcp.ExportNoteHandler(client))
+		},
+		"find_duplicates": func() {
+			s.AddTool(obsidianmcp.FindDuplicatesTool(), obsidianmcp.FindDuplicatesHandler(client))
+		},
+		"vault_health": func() {
+			s.AddTool(obsidianmcp.VaultHealthTool(), obsidianmcp.VaultHealthHandler(client, obsidianmcp.VaultHealthConfig{
+				StubWords:          cfg.Obsidian.Lint.StubWords,
+				RequiredProperties: cfg.Obsidian.Lint.RequiredProperties,
+			}))
+		},
 		"open_file": func() {
 			s.AddTool(obsidianmcp.OpenFileTool(), obsidianmcp.OpenFileHandler(client))
 		},
+		"open_at": func() {
+			s.AddTool(obsidianmcp.OpenAtTool(), obsidianmcp.OpenAtHandler(client))
+		},
+		"open_split": func() {
+			s.AddTool(obsidianmcp.OpenSplitTool(), obsidianmcp.OpenSplitHandler(client))
+		},
+		"reveal_file": func() {
+			s.AddTool(obsidianmcp.RevealFileTool(), obsidianmcp.RevealFileHandler(client))
+		},
+		"get_workspace": func() {
+			s.AddTool(obsidianmcp.GetWorkspaceTool(), obsidianmcp.GetWorkspaceHandler(client))
+		},
 	}
 
 	// Register tools based on config
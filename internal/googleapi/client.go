@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -19,16 +21,64 @@ import (
 )
 
 // GetClient handles the OAuth2 flow and returns an authenticated HTTP client.
-// It requests scopes for Calendar and Gmail (Read-Only).
-func GetClient(credentialsJSON []byte, tokenPath string) (*http.Client, error) {
+// It requests scopes for Calendar and Gmail (Read-Only), and any additional
+// scopes, like gmail.GmailComposeScope to write drafts.
+func GetClient(credentialsJSON []byte, tokenPath string, additionalScopes ...string) (*http.Client, error) {
 	// If modifying these scopes, delete your previously saved token.json.
-	config, err := google.ConfigFromJSON(credentialsJSON, calendar.CalendarScope, gmail.GmailReadonlyScope)
+	scopes := append([]string{calendar.CalendarScope, gmail.GmailReadonlyScope}, additionalScopes...)
+	config, err := google.ConfigFromJSON(credentialsJSON, scopes...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse client secret file to config: %w", err)
 	}
 	return getClient(config, tokenPath), nil
 }
 
+// tokenInfoURL is the endpoint that describes an access token.
+const tokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
+
+var (
+	// ErrNoToken is returned by GrantedScopes for a client that GetClient
+	// did not create.
+	ErrNoToken = errors.New("client has no OAuth token")
+	// ErrTokenInfo is returned when the token cannot be described.
+	ErrTokenInfo = errors.New("unable to get token info")
+)
+
+// GrantedScopes returns the scopes that the user granted to the token of a
+// client created by GetClient. A token saved before scopes were added to
+// the configuration lacks them until it is deleted and authorized again.
+func GrantedScopes(ctx context.Context, client *http.Client) ([]string, error) {
+	transport, ok := client.Transport.(*oauth2.Transport)
+	if !ok {
+		return nil, ErrNoToken
+	}
+	tok, err := transport.Source.Token()
+	if err != nil {
+		return nil, err
+	}
+	u := tokenInfoURL + "?access_token=" + url.QueryEscape(tok.AccessToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The token is in the URL; the client would add it again.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrTokenInfo, resp.Status)
+	}
+	var info struct {
+		Scope string `json:"scope"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, err
+	}
+	return strings.Fields(info.Scope), nil
+}
+
 // Retrieve a token, saves the token, then returns the generated client.
 func getClient(config *oauth2.Config, tokenPath string) *http.Client {
 	// The file token.json stores the user's access and refresh tokens, and is
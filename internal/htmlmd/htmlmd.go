@@ -0,0 +1,222 @@
+// Package htmlmd converts HTML documents to Markdown.
+//
+// It handles the HTML found in web pages and emails: headings, paragraphs,
+// emphasis, links, images, lists, block quotes, code and tables. Other
+// elements are reduced to their text. With Options.MainContent, it first
+// extracts the main content of a page (the article text without navigation,
+// sidebars and comments) with heuristics similar to Readability.
+package htmlmd
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Options configures Convert.
+type Options struct {
+	// BaseURL is used to resolve relative links and image sources. A <base>
+	// element in the document takes precedence.
+	BaseURL string
+	// MainContent keeps only the main content of the page.
+	MainContent bool
+}
+
+// Document is a converted HTML document.
+type Document struct {
+	// Title is the title of the page, from its metadata or its first heading.
+	Title    string
+	Markdown string
+}
+
+// Convert converts an HTML document (or fragment) to Markdown.
+func Convert(src string, opts Options) (*Document, error) {
+	root, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &converter{}
+	if opts.BaseURL != "" {
+		if c.base, err = url.Parse(opts.BaseURL); err != nil {
+			return nil, err
+		}
+	}
+	if href := attr(findFirst(root, atom.Base), "href"); href != "" {
+		c.base = c.resolveURL(href)
+	}
+
+	doc := &Document{Title: pageTitle(root)}
+	removeHidden(root)
+	content := findFirst(root, atom.Body)
+	if content == nil {
+		content = root
+	}
+	if opts.MainContent {
+		content = mainContent(content)
+	}
+	if doc.Title == "" {
+		doc.Title = strings.TrimSpace(collapseSpaces(textContent(findFirst(content, atom.H1))))
+	}
+
+	md := strings.Join(c.blocks(content), "\n\n")
+	if md != "" {
+		md += "\n"
+	}
+	doc.Markdown = md
+	return doc, nil
+}
+
+// pageTitle returns the og:title or the <title> of the page.
+func pageTitle(root *html.Node) string {
+	var title string
+	walk(root, func(n *html.Node) bool {
+		if n.DataAtom == atom.Meta && (attr(n, "property") == "og:title" || attr(n, "name") == "twitter:title") {
+			title = attr(n, "content")
+		}
+		return title == ""
+	})
+	if title == "" {
+		title = textContent(findFirst(root, atom.Title))
+	}
+	return strings.TrimSpace(collapseSpaces(title))
+}
+
+// resolveURL resolves a link or image source against the base URL.
+func (c *converter) resolveURL(ref string) *url.URL {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return nil
+	}
+	if c.base != nil {
+		u = c.base.ResolveReference(u)
+	}
+	return u
+}
+
+// walk calls fn for n and its descendants in document order, until fn returns false.
+func walk(n *html.Node, fn func(*html.Node) bool) bool {
+	if !fn(n) {
+		return false
+	}
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		if !walk(ch, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+func findFirst(root *html.Node, a atom.Atom) *html.Node {
+	var found *html.Node
+	walk(root, func(n *html.Node) bool {
+		if n.Type == html.ElementNode && n.DataAtom == a {
+			found = n
+		}
+		return found == nil
+	})
+	return found
+}
+
+func findAll(root *html.Node, a atom.Atom) []*html.Node {
+	var found []*html.Node
+	walk(root, func(n *html.Node) bool {
+		if n.Type == html.ElementNode && n.DataAtom == a {
+			found = append(found, n)
+		}
+		return true
+	})
+	return found
+}
+
+func attr(n *html.Node, key string) string {
+	if n == nil {
+		return ""
+	}
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// textContent returns the text of n and its descendants.
+func textContent(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	var sb strings.Builder
+	walk(n, func(d *html.Node) bool {
+		if d.Type == html.TextNode {
+			sb.WriteString(d.Data)
+		}
+		return true
+	})
+	return sb.String()
+}
+
+// collapseSpaces replaces runs of whitespace (including non-breaking
+// spaces) with a single space.
+func collapseSpaces(s string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' || r == '\u00a0' {
+			if !space {
+				sb.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// removeHidden removes elements that are never part of the visible text.
+func removeHidden(root *html.Node) {
+	var remove []*html.Node
+	walk(root, func(n *html.Node) bool {
+		if n.Type == html.CommentNode {
+			remove = append(remove, n)
+			return true
+		}
+		if n.Type != html.ElementNode {
+			return true
+		}
+		style := strings.ReplaceAll(strings.ToLower(attr(n, "style")), " ", "")
+		if isNonContent(n.DataAtom) || attr(n, "aria-hidden") == "true" || hasAttr(n, "hidden") ||
+			strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+			remove = append(remove, n)
+		}
+		return true
+	})
+	for _, n := range remove {
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+		}
+	}
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func isNonContent(a atom.Atom) bool {
+	switch a { //nolint:exhaustive // Only non-content elements are listed.
+	case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Iframe, atom.Frame, atom.Object, atom.Embed,
+		atom.Svg, atom.Canvas, atom.Button, atom.Input, atom.Select, atom.Textarea, atom.Link, atom.Meta, atom.Head:
+		return true
+	default:
+		return false
+	}
+}
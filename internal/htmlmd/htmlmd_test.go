@@ -0,0 +1,108 @@
+package htmlmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "inline formatting",
+			html: `<p>Some <b>bold</b>, <em>emphasis</em>, <del>old</del> and <code>x := 1</code> text with *stars*.</p>`,
+			want: "Some **bold**, *emphasis*, ~~old~~ and `x := 1` text with \\*stars\\*.\n",
+		},
+		{
+			name: "links and images",
+			html: `<p><a href="/docs">Docs</a>, <a href="#top">top</a>, <img src="a(1).png" alt="Logo"> <img src="data:image/png;base64,xx"></p>`,
+			want: "[Docs](https://example.com/docs), top, ![Logo](<https://example.com/blog/a(1).png>)\n",
+		},
+		{
+			name: "headings and breaks",
+			html: `<h2>Title</h2><div>Line one<br>Line two</div><hr><h3></h3>`,
+			want: "## Title\n\nLine one\nLine two\n\n---\n",
+		},
+		{
+			name: "lists",
+			html: `<ul><li>One</li><li>Two<ol><li>Nested</li><li>Again</li></ol></li></ul>`,
+			want: "- One\n- Two\n  1. Nested\n  2. Again\n",
+		},
+		{
+			name: "code block",
+			html: "<pre><code class=\"language-sh\">echo ```\nls\n</code></pre>",
+			want: "````sh\necho ```\nls\n````\n",
+		},
+		{
+			name: "quote",
+			html: `<blockquote><p>First.</p><p>Second.</p></blockquote>`,
+			want: "> First.\n>\n> Second.\n",
+		},
+		{
+			name: "data table",
+			html: `<table><thead><tr><th>Name</th><th>Value</th></tr></thead><tbody><tr><td>a|b</td></tr></tbody></table>`,
+			want: "| Name | Value |\n| --- | --- |\n| a\\|b |  |\n",
+		},
+		{
+			name: "layout table",
+			html: `<table><tr><td><p>Hello,</p><table><tr><td>Inner</td></tr></table></td><td><p>Side</p></td></tr></table>`,
+			want: "Hello,\n\nInner\n\nSide\n",
+		},
+		{
+			name: "hidden content",
+			html: `<p>Shown<span style="display: none">hidden</span></p><script>alert(1)</script><div hidden>no</div><!-- comment -->`,
+			want: "Shown\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Convert(tt.html, Options{BaseURL: "https://example.com/blog/post"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, doc.Markdown)
+		})
+	}
+}
+
+func TestConvert_MainContent(t *testing.T) {
+	page := `<html><head>
+<title>A Day Out - Example Blog</title>
+<base href="https://blog.example.com/2024/">
+</head><body>
+<header class="site-header"><a href="/">Example Blog</a></header>
+<nav><a href="/">Home</a> <a href="/about">About</a></nav>
+<div class="layout">
+  <div class="post-body">
+    <h1>A Day Out</h1>
+    <p>We took the early train to the coast, walked along the cliffs, and had lunch in the harbour.</p>
+    <p>In the afternoon, the weather turned, so we visited the <a href="museum.html">maritime museum</a>, which was better than expected.</p>
+  </div>
+  <div class="sidebar"><p>Subscribe to our newsletter for weekly updates, tips, and stories from the road.</p></div>
+</div>
+<div id="comments"><p>Lovely post, thanks for sharing, we went there last year, too.</p></div>
+<footer><p>Copyright 2024, all rights reserved, do not copy.</p></footer>
+</body></html>`
+
+	doc, err := Convert(page, Options{MainContent: true})
+	require.NoError(t, err)
+	assert.Equal(t, "A Day Out - Example Blog", doc.Title)
+	assert.Equal(t, "# A Day Out\n\n"+
+		"We took the early train to the coast, walked along the cliffs, and had lunch in the harbour.\n\n"+
+		"In the afternoon, the weather turned, so we visited the [maritime museum](https://blog.example.com/2024/museum.html), which was better than expected.\n",
+		doc.Markdown)
+}
+
+func TestConvert_Article(t *testing.T) {
+	page := `<body><div class="promo">Buy now</div>` +
+		`<article><h1>Short</h1></article>` +
+		`<article><h1 id="t">News</h1><p>The article text.</p><aside>Related stories</aside></article></body>`
+
+	doc, err := Convert(page, Options{MainContent: true})
+	require.NoError(t, err)
+	assert.Equal(t, "News", doc.Title, "the first heading of the content is the title")
+	assert.Equal(t, "# News\n\nThe article text.\n", doc.Markdown)
+}
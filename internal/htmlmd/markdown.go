@@ -0,0 +1,378 @@
+package htmlmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type converter struct {
+	base *url.URL
+}
+
+// blocks renders the children of n as Markdown blocks. Runs of inline
+// content between block elements become paragraphs.
+func (c *converter) blocks(n *html.Node) []string {
+	var out []string
+	var para strings.Builder
+	flush := func() {
+		if p := cleanParagraph(para.String()); p != "" {
+			out = append(out, p)
+		}
+		para.Reset()
+	}
+
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		if ch.Type == html.ElementNode && isBlock(ch.DataAtom) {
+			flush()
+			out = append(out, c.block(ch)...)
+			continue
+		}
+		para.WriteString(c.inline(ch))
+	}
+	flush()
+	return out
+}
+
+// block renders a block element.
+func (c *converter) block(n *html.Node) []string {
+	switch n.DataAtom { //nolint:exhaustive // Other block elements are containers.
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := cleanParagraph(c.inlineChildren(n))
+		if text == "" {
+			return nil
+		}
+		level := int(n.Data[1] - '0')
+		return []string{strings.Repeat("#", level) + " " + strings.ReplaceAll(text, "\n", " ")}
+	case atom.Ul, atom.Ol:
+		if list := c.list(n); list != "" {
+			return []string{list}
+		}
+		return nil
+	case atom.Pre:
+		return []string{codeBlock(n)}
+	case atom.Blockquote:
+		inner := strings.Join(c.blocks(n), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		return []string{prefixLines(inner, "> ", "> ")}
+	case atom.Hr:
+		return []string{"---"}
+	case atom.Table:
+		return c.table(n)
+	case atom.Figcaption:
+		if text := cleanParagraph(c.inlineChildren(n)); text != "" {
+			return []string{"*" + text + "*"}
+		}
+		return nil
+	default:
+		return c.blocks(n)
+	}
+}
+
+func isBlock(a atom.Atom) bool {
+	switch a { //nolint:exhaustive // Other elements are inline.
+	case atom.Address, atom.Article, atom.Aside, atom.Blockquote, atom.Body, atom.Center, atom.Dd, atom.Details,
+		atom.Dialog, atom.Dir, atom.Div, atom.Dl, atom.Dt, atom.Fieldset, atom.Figcaption, atom.Figure, atom.Footer,
+		atom.Form, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Header, atom.Hgroup, atom.Hr, atom.Html,
+		atom.Li, atom.Main, atom.Menu, atom.Nav, atom.Ol, atom.P, atom.Pre, atom.Section, atom.Summary, atom.Table,
+		atom.Tbody, atom.Td, atom.Tfoot, atom.Th, atom.Thead, atom.Tr, atom.Ul:
+		return true
+	default:
+		return false
+	}
+}
+
+// inline renders an inline node.
+func (c *converter) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return escape(collapseSpaces(n.Data))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.DataAtom { //nolint:exhaustive // Other elements are rendered as their content.
+	case atom.A:
+		return c.link(n)
+	case atom.Img:
+		return c.image(n)
+	case atom.Br:
+		return "\n"
+	case atom.Strong, atom.B:
+		return wrap(c.inlineChildren(n), "**")
+	case atom.Em, atom.I, atom.Cite:
+		return wrap(c.inlineChildren(n), "*")
+	case atom.Del, atom.S, atom.Strike:
+		return wrap(c.inlineChildren(n), "~~")
+	case atom.Mark:
+		return wrap(c.inlineChildren(n), "==")
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		return codeSpan(strings.TrimSpace(collapseSpaces(textContent(n))))
+	default:
+		return c.inlineChildren(n)
+	}
+}
+
+func (c *converter) inlineChildren(n *html.Node) string {
+	var sb strings.Builder
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		sb.WriteString(c.inline(ch))
+	}
+	return sb.String()
+}
+
+func (c *converter) link(n *html.Node) string {
+	text := c.inlineChildren(n)
+	href := strings.TrimSpace(attr(n, "href"))
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return text
+	}
+	u := c.resolveURL(href)
+	if u == nil {
+		return text
+	}
+	label := strings.TrimSpace(strings.ReplaceAll(text, "\n", " "))
+	if label == "" {
+		return ""
+	}
+	lead, trail := surroundingSpace(text)
+	return lead + "[" + label + "](" + markdownURL(u) + ")" + trail
+}
+
+func (c *converter) image(n *html.Node) string {
+	src := attr(n, "src")
+	// Lazy-loaded images keep the real source in a data attribute.
+	if lazy := attr(n, "data-src"); lazy != "" && (src == "" || strings.HasPrefix(src, "data:")) {
+		src = lazy
+	}
+	if src == "" || strings.HasPrefix(src, "data:") {
+		return ""
+	}
+	u := c.resolveURL(src)
+	if u == nil {
+		return ""
+	}
+	alt := escape(collapseSpaces(attr(n, "alt")))
+	return "![" + strings.TrimSpace(alt) + "](" + markdownURL(u) + ")"
+}
+
+// markdownURL formats a URL for a Markdown link destination.
+func markdownURL(u *url.URL) string {
+	s := u.String()
+	if strings.ContainsAny(s, " ()") {
+		return "<" + s + ">"
+	}
+	return s
+}
+
+func (c *converter) list(n *html.Node) string {
+	var items []string
+	num := 1
+	if start := attr(n, "start"); start != "" {
+		_, _ = fmt.Sscanf(start, "%d", &num)
+	}
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		if ch.Type != html.ElementNode || ch.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", num)
+			num++
+		}
+		content := strings.Join(c.blocks(ch), "\n")
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, prefixLines(content, marker, indent))
+	}
+	return strings.Join(items, "\n")
+}
+
+func codeBlock(pre *html.Node) string {
+	lang := language(pre)
+	if code := findFirst(pre, atom.Code); code != nil && lang == "" {
+		lang = language(code)
+	}
+	text := strings.TrimRight(textContent(pre), "\n")
+	text = strings.TrimPrefix(text, "\n")
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + text + "\n" + fence
+}
+
+// language returns the language of a code block from its "language-x" or "lang-x" class.
+func language(n *html.Node) string {
+	for _, class := range strings.Fields(attr(n, "class")) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if lang, ok := strings.CutPrefix(class, prefix); ok {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+func codeSpan(text string) string {
+	if text == "" {
+		return ""
+	}
+	delim := "`"
+	for strings.Contains(text, delim) {
+		delim += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+	return delim + text + delim
+}
+
+func (c *converter) table(n *html.Node) []string {
+	rows := tableRows(n)
+	cols := 0
+	layout := false
+	for _, row := range rows {
+		cols = max(cols, len(row))
+		for _, cell := range row {
+			layout = layout || hasBlockContent(cell)
+		}
+	}
+	if cols == 0 {
+		return nil
+	}
+	if cols == 1 || layout {
+		// Tables with a single column or with paragraphs, lists and nested
+		// tables in cells are used for layout, as in emails: render the
+		// contents of their cells in order.
+		var out []string
+		for _, row := range rows {
+			for _, cell := range row {
+				out = append(out, c.blocks(cell)...)
+			}
+		}
+		return out
+	}
+
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		cells := make([]string, cols)
+		for j, cell := range row {
+			cells[j] = c.tableCell(cell)
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", cols))
+		}
+	}
+	return []string{strings.Join(lines, "\n")}
+}
+
+// tableRows returns the cells of the rows of a table, without the rows of nested tables.
+func tableRows(table *html.Node) [][]*html.Node {
+	var rows [][]*html.Node
+	addRow := func(tr *html.Node) {
+		var row []*html.Node
+		for cell := tr.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+				row = append(row, cell)
+			}
+		}
+		rows = append(rows, row)
+	}
+	for ch := table.FirstChild; ch != nil; ch = ch.NextSibling {
+		switch ch.DataAtom { //nolint:exhaustive // Other children are not rows.
+		case atom.Tr:
+			addRow(ch)
+		case atom.Thead, atom.Tbody, atom.Tfoot:
+			for tr := ch.FirstChild; tr != nil; tr = tr.NextSibling {
+				if tr.DataAtom == atom.Tr {
+					addRow(tr)
+				}
+			}
+		}
+	}
+	return rows
+}
+
+// hasBlockContent reports whether n contains block elements.
+func hasBlockContent(n *html.Node) bool {
+	found := false
+	walk(n, func(d *html.Node) bool {
+		found = d != n && d.Type == html.ElementNode && isBlock(d.DataAtom)
+		return !found
+	})
+	return found
+}
+
+func (c *converter) tableCell(cell *html.Node) string {
+	text := strings.ReplaceAll(cleanParagraph(c.inlineChildren(cell)), "\n", " ")
+	return strings.ReplaceAll(text, "|", `\|`)
+}
+
+// escape escapes characters that Markdown would interpret in text.
+func escape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\', '*', '`', '[', ']', '<':
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// wrap wraps the text in a delimiter, keeping the surrounding spaces outside.
+func wrap(text, delim string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	lead, trail := surroundingSpace(text)
+	return lead + delim + trimmed + delim + trail
+}
+
+func surroundingSpace(text string) (string, string) {
+	lead, trail := "", ""
+	if strings.HasPrefix(text, " ") {
+		lead = " "
+	}
+	if strings.HasSuffix(text, " ") {
+		trail = " "
+	}
+	return lead, trail
+}
+
+// cleanParagraph trims the lines of a paragraph and drops empty lines.
+func cleanParagraph(s string) string {
+	lines := strings.Split(s, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// prefixLines prefixes the first line of s with first and the others with rest.
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		p := rest
+		if i == 0 {
+			p = first
+		}
+		if line == "" {
+			p = strings.TrimRight(p, " ")
+		}
+		lines[i] = p + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,197 @@
+package htmlmd
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Main content extraction, after Readability: elements whose class or id
+// suggest boilerplate are dropped, paragraphs score points for their length
+// and commas, which go to their parent and (halved) grandparent, and the
+// element with the best score, discounted by the share of its text in links,
+// is the main content. An <article> or <main> element is used directly.
+
+const (
+	minParagraphLength = 25
+	maxLengthBonus     = 3
+	classWeight        = 25
+	containerWeight    = 5
+	blockWeight        = 3
+)
+
+// unlikelyNames are class and id fragments of elements that are not content.
+func unlikelyNames() []string {
+	return []string{
+		"advert", "agegate", "banner", "breadcrumb", "comment", "community", "cookie", "disqus",
+		"footer", "header", "menu", "modal", "nav", "newsletter", "pager", "pagination", "popup", "promo",
+		"related", "remark", "replies", "share", "sharing", "sidebar", "skyscraper", "social", "sponsor",
+		"subscribe", "toolbar", "widget",
+	}
+}
+
+// likelyNames are class and id fragments of elements that are content.
+func likelyNames() []string {
+	return []string{"article", "blog", "body", "content", "entry", "main", "post", "story", "text"}
+}
+
+func matchesAny(s string, fragments []string) bool {
+	for _, f := range fragments {
+		if strings.Contains(s, f) {
+			return true
+		}
+	}
+	return false
+}
+
+func classAndID(n *html.Node) string {
+	return strings.ToLower(attr(n, "class") + " " + attr(n, "id"))
+}
+
+// mainContent returns the element with the main content under body.
+func mainContent(body *html.Node) *html.Node {
+	removeBoilerplate(body)
+
+	if best := largest(findAll(body, atom.Article)); best != nil {
+		return best
+	}
+	if main := findFirst(body, atom.Main); main != nil {
+		return main
+	}
+	var roleMain *html.Node
+	walk(body, func(n *html.Node) bool {
+		if attr(n, "role") == "main" {
+			roleMain = n
+		}
+		return roleMain == nil
+	})
+	if roleMain != nil {
+		return roleMain
+	}
+
+	if best := bestCandidate(body); best != nil {
+		return best
+	}
+	return body
+}
+
+// removeBoilerplate removes navigation, sidebars and other elements that
+// are not content.
+func removeBoilerplate(body *html.Node) {
+	var remove []*html.Node
+	walk(body, func(n *html.Node) bool {
+		if n.Type != html.ElementNode || n == body {
+			return true
+		}
+		switch n.DataAtom { //nolint:exhaustive // Other elements are checked by name.
+		case atom.Nav, atom.Aside, atom.Footer:
+			remove = append(remove, n)
+		case atom.Html, atom.Body, atom.Article, atom.Main:
+		default:
+			names := classAndID(n)
+			if matchesAny(names, unlikelyNames()) && !matchesAny(names, likelyNames()) {
+				remove = append(remove, n)
+			}
+		}
+		return true
+	})
+	for _, n := range remove {
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+		}
+	}
+}
+
+// largest returns the node with the longest text.
+func largest(nodes []*html.Node) *html.Node {
+	var best *html.Node
+	bestLen := 0
+	for _, n := range nodes {
+		if l := len(strings.TrimSpace(textContent(n))); l > bestLen {
+			best, bestLen = n, l
+		}
+	}
+	return best
+}
+
+// bestCandidate scores the ancestors of paragraphs and returns the best one.
+func bestCandidate(body *html.Node) *html.Node {
+	scores := make(map[*html.Node]float64)
+	var order []*html.Node
+	add := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+			order = append(order, n)
+		}
+		scores[n] += score
+	}
+
+	walk(body, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		switch n.DataAtom { //nolint:exhaustive // Only paragraph-like elements are scored.
+		case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		default:
+			return true
+		}
+		text := strings.TrimSpace(collapseSpaces(textContent(n)))
+		if len(text) < minParagraphLength {
+			return true
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text)/100), maxLengthBonus) //nolint:mnd
+		add(n.Parent, score)
+		if n.Parent != nil {
+			add(n.Parent.Parent, score/2) //nolint:mnd
+		}
+		return true
+	})
+
+	var best *html.Node
+	bestScore := 0.0
+	for _, n := range order {
+		if score := scores[n] * (1 - linkDensity(n)); score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	return best
+}
+
+func initialScore(n *html.Node) float64 {
+	score := 0.0
+	switch n.DataAtom { //nolint:exhaustive // Other elements start at 0.
+	case atom.Div, atom.Section:
+		score += containerWeight
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score += blockWeight
+	case atom.Ol, atom.Ul, atom.Li, atom.Form, atom.Dl, atom.Dd, atom.Dt:
+		score -= blockWeight
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score -= containerWeight
+	}
+	names := classAndID(n)
+	if matchesAny(names, likelyNames()) {
+		score += classWeight
+	}
+	if matchesAny(names, unlikelyNames()) {
+		score -= classWeight
+	}
+	return score
+}
+
+// linkDensity returns the share of the text of n that is in links.
+func linkDensity(n *html.Node) float64 {
+	total := len(strings.TrimSpace(collapseSpaces(textContent(n))))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	for _, a := range findAll(n, atom.A) {
+		links += len(strings.TrimSpace(collapseSpaces(textContent(a))))
+	}
+	return float64(links) / float64(total)
+}
@@ -0,0 +1,29 @@
+// Package toolargs converts the arguments of MCP tool calls.
+package toolargs
+
+import "strings"
+
+// StringSlice converts an array argument (or a comma-separated string) to
+// strings. Empty items are left out.
+func StringSlice(v interface{}) []string {
+	switch val := v.(type) {
+	case []interface{}:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case string:
+		var out []string
+		for _, s := range strings.Split(val, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
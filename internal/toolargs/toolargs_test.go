@@ -0,0 +1,14 @@
+package toolargs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringSlice(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, StringSlice([]interface{}{"a", "", 1, "b"}))
+	assert.Equal(t, []string{"a", "b c"}, StringSlice(" a, ,b c "))
+	assert.Nil(t, StringSlice(nil))
+	assert.Nil(t, StringSlice(42))
+}
@@ -14,11 +14,70 @@ type Config struct {
 		URL    string `json:"url"`
 		Cert   string `json:"cert"`
 		APIKey string `json:"apikey"`
+		// Capture configures the obsidian_capture tool.
+		Capture struct {
+			// Heading of the daily note that captures go under (default "Log").
+			Heading string `json:"heading"`
+			// Template is the vault path of the note used to create a missing daily note.
+			Template string `json:"template"`
+			// TimestampFormat is the Go time layout of the timestamp prefix (default "15:04").
+			TimestampFormat string `json:"timestamp_format"`
+		} `json:"capture"`
+		// Clip configures the obsidian_clip tool.
+		Clip struct {
+			// Folder that clippings are saved to (default "Clippings").
+			Folder string `json:"folder"`
+		} `json:"clip"`
+		// Lint configures the vault health checks of obscom vault lint and
+		// the obsidian_vault_health tool.
+		Lint struct {
+			// StubWords is the word count below which a note is a stub (default 10).
+			StubWords int `json:"stub_words"`
+			// RequiredProperties maps folders to the frontmatter properties
+			// their notes must have; "" applies to all notes.
+			RequiredProperties map[string][]string `json:"required_properties"`
+		} `json:"lint"`
 	} `json:"obsidian"`
 	Gmail struct {
 		Enabled         bool   `json:"enabled"`
 		CredentialsFile string `json:"credentials_file"`
 		TokenFile       string `json:"token_file"`
+		// Scopes are OAuth scopes requested in addition to read-only access,
+		// like "https://www.googleapis.com/auth/gmail.compose" to write drafts.
+		Scopes []string `json:"scopes"`
+		// SearchHeaders are the headers of the messages that gmail_search
+		// returns (default Subject, Date, From, To and Cc).
+		SearchHeaders []string `json:"search_headers"`
+		// ProtectedLabels are names or IDs of labels that the triage tools
+		// neither add nor remove, and whose messages they leave unchanged.
+		ProtectedLabels []string `json:"protected_labels"`
+		// SyncStateFile is where gmail_changes_since_last_check stores the
+		// history ID of its last check (default "gmail_sync_state.json" next
+		// to the config file).
+		SyncStateFile string `json:"sync_state_file"`
+		// Attachments configures the gmail_get_attachment tool.
+		Attachments struct {
+			// MaxBytes is the size of the largest attachment that is returned or saved (default 5 MB).
+			MaxBytes int64 `json:"max_bytes"`
+			// Dir is the local directory that attachments can be saved to; saving locally is disabled if empty.
+			Dir string `json:"dir"`
+			// VaultFolder is the Obsidian vault folder that attachments can be saved to; saving to the vault is disabled if empty.
+			VaultFolder string `json:"vault_folder"`
+		} `json:"attachments"`
+		// Send configures the gmail_send tool, which needs a send scope.
+		Send struct {
+			// Enabled registers the tool (default false).
+			Enabled bool `json:"enabled"`
+			// Allowlist holds the addresses and domains that messages may be sent to.
+			Allowlist []string `json:"allowlist"`
+			// DailyQuota is the number of messages sent a day (default 10).
+			DailyQuota int `json:"daily_quota"`
+			// Confirm asks the user to confirm each message through the MCP client.
+			Confirm bool `json:"confirm"`
+			// AuditLog is the JSON Lines file every attempt to send is logged to
+			// (default "gmail_send_audit.jsonl" next to the config file).
+			AuditLog string `json:"audit_log"`
+		} `json:"send"`
 	} `json:"gmail"`
 	Calendar struct {
 		Enabled         bool     `json:"enabled"`
@@ -62,6 +121,11 @@ func Load(path string) (*Config, error) {
 		return filepath.Abs(fullPath)
 	}
 
+	// Set defaults for Obsidian
+	if cfg.Obsidian.Clip.Folder == "" {
+		cfg.Obsidian.Clip.Folder = "Clippings"
+	}
+
 	// Set defaults for Gmail
 	if cfg.Gmail.CredentialsFile == "" {
 		cfg.Gmail.CredentialsFile = "credentials.json"
@@ -69,6 +133,12 @@ func Load(path string) (*Config, error) {
 	if cfg.Gmail.TokenFile == "" {
 		cfg.Gmail.TokenFile = "token.json"
 	}
+	if cfg.Gmail.Send.AuditLog == "" {
+		cfg.Gmail.Send.AuditLog = "gmail_send_audit.jsonl"
+	}
+	if cfg.Gmail.SyncStateFile == "" {
+		cfg.Gmail.SyncStateFile = "gmail_sync_state.json"
+	}
 
 	var errPath error
 	if cfg.Obsidian.Cert, errPath = resolve(cfg.Obsidian.Cert); errPath != nil {
@@ -80,6 +150,15 @@ func Load(path string) (*Config, error) {
 	if cfg.Gmail.TokenFile, errPath = resolve(cfg.Gmail.TokenFile); errPath != nil {
 		return nil, errPath
 	}
+	if cfg.Gmail.Attachments.Dir, errPath = resolve(cfg.Gmail.Attachments.Dir); errPath != nil {
+		return nil, errPath
+	}
+	if cfg.Gmail.Send.AuditLog, errPath = resolve(cfg.Gmail.Send.AuditLog); errPath != nil {
+		return nil, errPath
+	}
+	if cfg.Gmail.SyncStateFile, errPath = resolve(cfg.Gmail.SyncStateFile); errPath != nil {
+		return nil, errPath
+	}
 
 	// Set defaults for Calendar
 	if cfg.Calendar.CredentialsFile == "" {
@@ -2,13 +2,17 @@ package gmail
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
-	"log"
+	"net/http"
 	"os"
+	"slices"
+	"sync"
 
 	"github.com/bttk/bttk-mcp/internal/googleapi"
 	"google.golang.org/api/gmail/v1"
+	gapi "google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
@@ -23,30 +27,103 @@ var (
 	ErrListMessages = errors.New("unable to list messages")
 	// ErrGetMessage is returned when a message cannot be retrieved.
 	ErrGetMessage = errors.New("unable to get message")
+	// ErrGetThread is returned when a thread cannot be retrieved.
+	ErrGetThread = errors.New("unable to get thread")
+	// ErrGetAttachment is returned when an attachment cannot be retrieved.
+	ErrGetAttachment = errors.New("unable to get attachment")
+	// ErrCreateDraft is returned when a draft cannot be created.
+	ErrCreateDraft = errors.New("unable to create draft")
+	// ErrUpdateDraft is returned when a draft cannot be updated.
+	ErrUpdateDraft = errors.New("unable to update draft")
+	// ErrListDrafts is returned when the drafts cannot be listed.
+	ErrListDrafts = errors.New("unable to list drafts")
+	// ErrSendMessage is returned when a message cannot be sent.
+	ErrSendMessage = errors.New("unable to send message")
+	// ErrListLabels is returned when the labels cannot be listed.
+	ErrListLabels = errors.New("unable to list labels")
+	// ErrModifyLabels is returned when the labels of messages cannot be changed.
+	ErrModifyLabels = errors.New("unable to modify labels")
+	// ErrTrashMessage is returned when a message cannot be moved to the trash.
+	ErrTrashMessage = errors.New("unable to trash message")
+	// ErrGetProfile is returned when the profile of the mailbox cannot be retrieved.
+	ErrGetProfile = errors.New("unable to get profile")
+	// ErrListHistory is returned when the history of the mailbox cannot be listed.
+	ErrListHistory = errors.New("unable to list history")
+	// ErrHistoryExpired is returned by ListHistory when the start history ID
+	// is too old or invalid, and the mailbox must be synchronized in full.
+	ErrHistoryExpired = errors.New("history ID expired")
 )
 
 // Client is a wrapper around the Gmail API service.
 type Client struct {
 	Service *gmail.Service
+	// MetadataHeaders are the headers that SearchMessages fetches (default
+	// Subject, Date, From, To and Cc).
+	MetadataHeaders []string
+
+	httpClient *http.Client
 }
 
 // API defines the interface for interacting with Gmail.
 // This allows for mocking in tests.
 type API interface {
-	SearchMessages(query string, maxResults int64) ([]*gmail.Message, error)
+	SearchMessages(query, pageToken string, maxResults int64) (*SearchResult, error)
 	GetMessage(id string) (*gmail.Message, error)
+	GetThread(id string) (*gmail.Thread, error)
+	GetAttachment(messageID, attachmentID string) ([]byte, error)
+	CreateDraft(msg *Compose) (*gmail.Draft, error)
+	UpdateDraft(id string, msg *Compose) (*gmail.Draft, error)
+	ListDrafts(maxResults int64) ([]*gmail.Draft, error)
+	SendMessage(msg *Compose) (*gmail.Message, error)
+	ListLabels() ([]*gmail.Label, error)
+	ModifyLabels(messageIDs, addLabelIDs, removeLabelIDs []string) error
+	TrashMessage(id string) error
+	GetMetadata(messageIDs []string) *SearchResult
+	GetProfile() (*gmail.Profile, error)
+	ListHistory(startHistoryID uint64, labelID, pageToken string) (*gmail.ListHistoryResponse, error)
+}
+
+// searchWorkers is the number of messages or threads that GetMetadata
+// fetches at the same time.
+const searchWorkers = 10
+
+// SearchResult is a page of the messages matching a search.
+type SearchResult struct {
+	// Messages have the metadata format: their headers without a body.
+	Messages []*gmail.Message
+	// NextPageToken is the token of the next page, or empty on the last
+	// page.
+	NextPageToken string
+	// ResultSizeEstimate is the estimated number of matching messages.
+	ResultSizeEstimate int64
+	// Errors lists the messages that matched but could not be fetched.
+	Errors []MessageError
+	// ThreadSizes maps the IDs of the threads of the messages to their
+	// number of messages.
+	ThreadSizes map[string]int
 }
 
+// MessageError is the error of fetching one message of many.
+type MessageError struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// maxBatchModify is the number of messages that one BatchModify request
+// may change.
+const maxBatchModify = 1000
+
 // NewClient creates a new Gmail client.
-// It handles the OAuth2 flow if a valid token is not found.
-func NewClient(credentialsPath, tokenPath string) (*Client, error) {
+// It handles the OAuth2 flow if a valid token is not found. Scopes are
+// requested in addition to the read-only scope.
+func NewClient(credentialsPath, tokenPath string, scopes ...string) (*Client, error) {
 	ctx := context.Background()
 	b, err := os.ReadFile(credentialsPath)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrReadSecret, err)
 	}
 
-	client, err := googleapi.GetClient(b, tokenPath)
+	client, err := googleapi.GetClient(b, tokenPath, scopes...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrParseConfig, err)
 	}
@@ -56,32 +133,116 @@ func NewClient(credentialsPath, tokenPath string) (*Client, error) {
 		return nil, fmt.Errorf("%w: %w", ErrClientRetrieve, err)
 	}
 
-	return &Client{Service: srv}, nil
+	return &Client{Service: srv, httpClient: client}, nil
 }
 
-// SearchMessages searches for messages matching the query.
-// It returns a list of simplified message details.
-func (c *Client) SearchMessages(query string, maxResults int64) ([]*gmail.Message, error) {
+// GrantedScopes returns the OAuth scopes of the token of the client.
+func (c *Client) GrantedScopes() ([]string, error) {
+	if c.httpClient == nil {
+		return nil, googleapi.ErrNoToken
+	}
+	return googleapi.GrantedScopes(context.Background(), c.httpClient)
+}
+
+// SearchMessages searches for messages matching the query and returns one
+// page of results, starting at pageToken (or the first page if it is
+// empty). The metadata of the messages and the sizes of their threads are
+// fetched concurrently; messages that cannot be fetched are reported in the
+// Errors of the result.
+func (c *Client) SearchMessages(query, pageToken string, maxResults int64) (*SearchResult, error) {
 	user := "me"
-	r, err := c.Service.Users.Messages.List(user).Q(query).MaxResults(maxResults).Do()
+	call := c.Service.Users.Messages.List(user).Q(query).MaxResults(maxResults)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	r, err := call.Do()
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrListMessages, err)
 	}
 
-	messages := make([]*gmail.Message, 0, len(r.Messages))
-	for _, msg := range r.Messages {
-		m, err := c.Service.Users.Messages.Get(user, msg.Id).
+	result := c.GetMetadata(ids(r.Messages))
+	result.NextPageToken = r.NextPageToken
+	result.ResultSizeEstimate = r.ResultSizeEstimate
+	return result, nil
+}
+
+// GetMetadata fetches the metadata of messages and the sizes of their
+// threads concurrently. Messages that cannot be fetched are reported in the
+// Errors of the result; the others keep their order.
+func (c *Client) GetMetadata(messageIDs []string) *SearchResult {
+	user := "me"
+	headers := c.MetadataHeaders
+	if len(headers) == 0 {
+		headers = []string{"Subject", "Date", "From", "To", "Cc"}
+	}
+	messages := make([]*gmail.Message, len(messageIDs))
+	errs := make([]error, len(messageIDs))
+	forEach(len(messageIDs), func(i int) {
+		messages[i], errs[i] = c.Service.Users.Messages.Get(user, messageIDs[i]).
 			Format("metadata").
-			MetadataHeaders("To", "From").
-			Fields("id", "threadId", "snippet", "internalDate", "payload(headers)").
+			MetadataHeaders(headers...).
+			Fields("id", "threadId", "labelIds", "snippet", "internalDate", "payload(mimeType,headers)").
 			Do()
-		if err != nil {
-			log.Printf("failed to get message details for ID %s: %v", msg.Id, err)
+	})
+
+	result := &SearchResult{
+		Messages:    make([]*gmail.Message, 0, len(messages)),
+		ThreadSizes: map[string]int{},
+	}
+	var threadIDs []string
+	for i, m := range messages {
+		if errs[i] != nil {
+			result.Errors = append(result.Errors, MessageError{ID: messageIDs[i], Error: errs[i].Error()})
 			continue
 		}
-		messages = append(messages, m)
+		result.Messages = append(result.Messages, m)
+		if !slices.Contains(threadIDs, m.ThreadId) {
+			threadIDs = append(threadIDs, m.ThreadId)
+		}
+	}
+
+	// The size of a thread is only informative, so threads that cannot be
+	// fetched are left out.
+	sizes := make([]int, len(threadIDs))
+	forEach(len(threadIDs), func(i int) {
+		thread, err := c.Service.Users.Threads.Get(user, threadIDs[i]).Format("minimal").Fields("messages/id").Do()
+		if err == nil {
+			sizes[i] = len(thread.Messages)
+		}
+	})
+	for i, id := range threadIDs {
+		if sizes[i] > 0 {
+			result.ThreadSizes[id] = sizes[i]
+		}
+	}
+	return result
+}
+
+func ids(messages []*gmail.Message) []string {
+	out := make([]string, len(messages))
+	for i, m := range messages {
+		out[i] = m.Id
 	}
-	return messages, nil
+	return out
+}
+
+// forEach calls fn for the numbers from 0 to n-1, at most searchWorkers at
+// the same time.
+func forEach(n int, fn func(i int)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(searchWorkers, n) {
+		wg.Go(func() {
+			for i := range indexes {
+				fn(i)
+			}
+		})
+	}
+	for i := range n {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
 }
 
 // GetMessage retrieves the details of a specific message.
@@ -93,3 +254,169 @@ func (c *Client) GetMessage(id string) (*gmail.Message, error) {
 	}
 	return msg, nil
 }
+
+// GetThread retrieves a thread with the full content of its messages.
+func (c *Client) GetThread(id string) (*gmail.Thread, error) {
+	user := "me"
+	thread, err := c.Service.Users.Threads.Get(user, id).Format("full").Do()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetThread, err)
+	}
+	return thread, nil
+}
+
+// GetAttachment retrieves the decoded content of an attachment of a message.
+func (c *Client) GetAttachment(messageID, attachmentID string) ([]byte, error) {
+	user := "me"
+	body, err := c.Service.Users.Messages.Attachments.Get(user, messageID, attachmentID).Do()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetAttachment, err)
+	}
+	data, err := DecodeBody(body.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetAttachment, err)
+	}
+	return data, nil
+}
+
+// CreateDraft saves a new draft. It is never sent.
+func (c *Client) CreateDraft(msg *Compose) (*gmail.Draft, error) {
+	draft, err := newDraft(msg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCreateDraft, err)
+	}
+	user := "me"
+	d, err := c.Service.Users.Drafts.Create(user, draft).Do()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCreateDraft, err)
+	}
+	return d, nil
+}
+
+// UpdateDraft replaces the content of a draft.
+func (c *Client) UpdateDraft(id string, msg *Compose) (*gmail.Draft, error) {
+	draft, err := newDraft(msg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpdateDraft, err)
+	}
+	user := "me"
+	d, err := c.Service.Users.Drafts.Update(user, id, draft).Do()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpdateDraft, err)
+	}
+	return d, nil
+}
+
+// ListDrafts lists the drafts with the IDs of their messages and threads.
+func (c *Client) ListDrafts(maxResults int64) ([]*gmail.Draft, error) {
+	user := "me"
+	r, err := c.Service.Users.Drafts.List(user).MaxResults(maxResults).Do()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListDrafts, err)
+	}
+	return r.Drafts, nil
+}
+
+// SendMessage sends a message.
+func (c *Client) SendMessage(msg *Compose) (*gmail.Message, error) {
+	raw, err := msg.MIME()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSendMessage, err)
+	}
+	user := "me"
+	m, err := c.Service.Users.Messages.Send(user, &gmail.Message{
+		Raw:      base64.URLEncoding.EncodeToString(raw),
+		ThreadId: msg.ThreadID,
+	}).Do()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSendMessage, err)
+	}
+	return m, nil
+}
+
+func newDraft(msg *Compose) (*gmail.Draft, error) {
+	raw, err := msg.MIME()
+	if err != nil {
+		return nil, err
+	}
+	return &gmail.Draft{Message: &gmail.Message{
+		Raw:      base64.URLEncoding.EncodeToString(raw),
+		ThreadId: msg.ThreadID,
+	}}, nil
+}
+
+// ListLabels lists the system and user labels of the mailbox.
+func (c *Client) ListLabels() ([]*gmail.Label, error) {
+	user := "me"
+	r, err := c.Service.Users.Labels.List(user).Do()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrListLabels, err)
+	}
+	return r.Labels, nil
+}
+
+// ModifyLabels adds labels to and removes labels from messages, with one
+// BatchModify request for up to 1000 messages. Removing the INBOX label
+// archives messages, removing UNREAD marks them as read, and adding STARRED
+// stars them.
+func (c *Client) ModifyLabels(messageIDs, addLabelIDs, removeLabelIDs []string) error {
+	user := "me"
+	for ids := range slices.Chunk(messageIDs, maxBatchModify) {
+		err := c.Service.Users.Messages.BatchModify(user, &gmail.BatchModifyMessagesRequest{
+			Ids:            ids,
+			AddLabelIds:    addLabelIDs,
+			RemoveLabelIds: removeLabelIDs,
+		}).Do()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrModifyLabels, err)
+		}
+	}
+	return nil
+}
+
+// TrashMessage moves a message to the trash, where Gmail deletes it after
+// 30 days.
+func (c *Client) TrashMessage(id string) error {
+	user := "me"
+	if _, err := c.Service.Users.Messages.Trash(user, id).Do(); err != nil {
+		return fmt.Errorf("%w: %w", ErrTrashMessage, err)
+	}
+	return nil
+}
+
+// GetProfile retrieves the profile of the mailbox, with its address and
+// current history ID.
+func (c *Client) GetProfile() (*gmail.Profile, error) {
+	user := "me"
+	p, err := c.Service.Users.GetProfile(user).Do()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrGetProfile, err)
+	}
+	return p, nil
+}
+
+// ListHistory lists one page of the changes of the mailbox after
+// startHistoryID: added and deleted messages and added and removed labels.
+// If labelID is not empty, only changes of messages with that label are
+// listed. Gmail keeps the history for about a week; for older or invalid
+// history IDs the error wraps ErrHistoryExpired.
+func (c *Client) ListHistory(startHistoryID uint64, labelID, pageToken string) (*gmail.ListHistoryResponse, error) {
+	user := "me"
+	call := c.Service.Users.History.List(user).StartHistoryId(startHistoryID).
+		HistoryTypes("messageAdded", "messageDeleted", "labelAdded", "labelRemoved")
+	if labelID != "" {
+		call = call.LabelId(labelID)
+	}
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	r, err := call.Do()
+	if err != nil {
+		var apiErr *gapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %w", ErrHistoryExpired, err)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrListHistory, err)
+	}
+	return r, nil
+}
@@ -0,0 +1,70 @@
+package gmail
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+)
+
+func setupTestServer(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	srv, err := gmail.NewService(context.Background(), option.WithEndpoint(ts.URL), option.WithoutAuthentication())
+	require.NoError(t, err)
+	return &Client{Service: srv}
+}
+
+func TestSearchMessages(t *testing.T) {
+	client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if thread, ok := strings.CutPrefix(r.URL.Path, "/gmail/v1/users/me/threads/"); ok {
+			assert.Equal(t, "t1", thread)
+			fmt.Fprintln(w, `{"messages": [{"id": "m1"}, {"id": "m3"}]}`)
+			return
+		}
+		switch id := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me/messages"); id {
+		case "":
+			assert.Equal(t, "label:INBOX", r.URL.Query().Get("q"))
+			if r.URL.Query().Get("pageToken") == "p2" {
+				fmt.Fprintln(w, `{"messages": [{"id": "m4"}]}`)
+				return
+			}
+			fmt.Fprintln(w, `{"messages": [{"id": "m1"}, {"id": "m2"}, {"id": "m3"}], "nextPageToken": "p2", "resultSizeEstimate": 4}`)
+		case "/m2":
+			http.Error(w, `{"error": {"code": 500, "message": "backend error"}}`, http.StatusInternalServerError)
+		default:
+			assert.Equal(t, "metadata", r.URL.Query().Get("format"))
+			assert.Equal(t, []string{"Subject", "Date", "From", "To", "Cc"}, r.URL.Query()["metadataHeaders"])
+			fmt.Fprintf(w, `{"id": %q, "threadId": "t1", "snippet": "hello"}`, strings.TrimPrefix(id, "/"))
+		}
+	})
+
+	result, err := client.SearchMessages("label:INBOX", "", 3)
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 2)
+	// Messages keep the order of the search results.
+	assert.Equal(t, "m1", result.Messages[0].Id)
+	assert.Equal(t, "m3", result.Messages[1].Id)
+	assert.Equal(t, "p2", result.NextPageToken)
+	assert.Equal(t, int64(4), result.ResultSizeEstimate)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "m2", result.Errors[0].ID)
+	assert.Contains(t, result.Errors[0].Error, "backend error")
+	assert.Equal(t, map[string]int{"t1": 2}, result.ThreadSizes)
+
+	result, err = client.SearchMessages("label:INBOX", "p2", 3)
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, "m4", result.Messages[0].Id)
+	assert.Empty(t, result.NextPageToken)
+}
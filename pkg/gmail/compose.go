@@ -0,0 +1,210 @@
+package gmail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// ErrInvalidHeader is returned when a header of a composed message is not
+// valid, like an address list that cannot be parsed or a subject with a
+// line break.
+var ErrInvalidHeader = errors.New("invalid header")
+
+// Compose is a message to be saved as a draft.
+type Compose struct {
+	// To, Cc and Bcc are address lists, like
+	// "Jane Doe <jane@example.com>, bob@example.com".
+	To      string
+	Cc      string
+	Bcc     string
+	Subject string
+	// Text and HTML are the plain and HTML bodies. A message with both is
+	// sent as multipart/alternative.
+	Text string
+	HTML string
+	// InReplyTo and References are the Message-IDs of the message replied
+	// to and of its thread, so that mail clients thread the reply.
+	InReplyTo  string
+	References string
+	// ThreadID is the Gmail thread that the message is added to.
+	ThreadID string
+}
+
+// NewReply returns a reply to a message in its thread, without a body. It
+// is addressed to the sender of the message (or its Reply-To address), or
+// for the replies to one's own messages to their recipients. With all set,
+// the other recipients are copied, except for one's own address.
+func NewReply(msg *gmail.Message, all bool) (*Compose, error) {
+	reply := &Compose{
+		Subject:   replySubject(Header(msg.Payload, "Subject")),
+		InReplyTo: Header(msg.Payload, "Message-ID"),
+		ThreadID:  msg.ThreadId,
+	}
+	reply.References = strings.TrimSpace(Header(msg.Payload, "References") + " " + reply.InReplyTo)
+
+	sent := slices.Contains(msg.LabelIds, "SENT")
+	to := Header(msg.Payload, "Reply-To")
+	if to == "" {
+		to = Header(msg.Payload, "From")
+	}
+	if sent {
+		to = Header(msg.Payload, "To")
+	}
+	recipients, err := mail.ParseAddressList(to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: reply address %q: %w", ErrInvalidHeader, to, err)
+	}
+	reply.To = formatAddresses(recipients)
+	if !all {
+		return reply, nil
+	}
+
+	lists := []string{Header(msg.Payload, "Cc")}
+	if !sent {
+		lists = append(lists, Header(msg.Payload, "To"))
+	}
+	var skip []*mail.Address
+	if own, err := mail.ParseAddress(Header(msg.Payload, "Delivered-To")); err == nil {
+		skip = append(skip, own)
+	}
+	reply.Cc = formatAddresses(otherRecipients(lists, append(skip, recipients...)))
+	return reply, nil
+}
+
+// otherRecipients returns the addresses of the lists without duplicates and
+// without the skipped ones. Lists that cannot be parsed are ignored.
+func otherRecipients(lists []string, skip []*mail.Address) []*mail.Address {
+	seen := map[string]bool{}
+	for _, a := range skip {
+		seen[strings.ToLower(a.Address)] = true
+	}
+	var others []*mail.Address
+	for _, list := range lists {
+		addresses, err := mail.ParseAddressList(list)
+		if err != nil {
+			continue
+		}
+		for _, a := range addresses {
+			if !seen[strings.ToLower(a.Address)] {
+				seen[strings.ToLower(a.Address)] = true
+				others = append(others, a)
+			}
+		}
+	}
+	return others
+}
+
+// Recipients returns the addresses of all recipients of the message.
+func (c *Compose) Recipients() ([]string, error) {
+	var recipients []string
+	for _, h := range []struct{ name, value string }{{"To", c.To}, {"Cc", c.Cc}, {"Bcc", c.Bcc}} {
+		if strings.TrimSpace(h.value) == "" {
+			continue
+		}
+		addresses, err := mail.ParseAddressList(h.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidHeader, h.name, err)
+		}
+		for _, a := range addresses {
+			recipients = append(recipients, a.Address)
+		}
+	}
+	return recipients, nil
+}
+
+// replySubject adds "Re: " to a subject unless it is there already.
+func replySubject(subject string) string {
+	if strings.HasPrefix(strings.ToLower(subject), "re:") {
+		return subject
+	}
+	return "Re: " + subject
+}
+
+// MIME returns the message in the RFC 2822 format of raw Gmail messages.
+func (c *Compose) MIME() ([]byte, error) {
+	var b bytes.Buffer
+	for _, h := range []struct{ name, value string }{{"To", c.To}, {"Cc", c.Cc}, {"Bcc", c.Bcc}} {
+		if strings.TrimSpace(h.value) == "" {
+			continue
+		}
+		addresses, err := mail.ParseAddressList(h.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidHeader, h.name, err)
+		}
+		writeHeader(&b, h.name, formatAddresses(addresses))
+	}
+	for _, h := range []struct{ name, value string }{{"Subject", c.Subject}, {"In-Reply-To", c.InReplyTo}, {"References", c.References}} {
+		if strings.ContainsAny(h.value, "\r\n") {
+			return nil, fmt.Errorf("%w: %s contains a line break", ErrInvalidHeader, h.name)
+		}
+		if h.value != "" {
+			writeHeader(&b, h.name, mime.QEncoding.Encode("utf-8", h.value))
+		}
+	}
+	writeHeader(&b, "MIME-Version", "1.0")
+
+	if c.Text != "" && c.HTML != "" {
+		w := multipart.NewWriter(&b)
+		writeHeader(&b, "Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": w.Boundary()}))
+		b.WriteString("\r\n")
+		for _, part := range []struct{ mediaType, content string }{{"text/plain", c.Text}, {"text/html", c.HTML}} {
+			pw, err := w.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.mediaType + "; charset=UTF-8"},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := writeQuotedPrintable(pw, part.content); err != nil {
+				return nil, err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+
+	mediaType, content := "text/plain", c.Text
+	if c.HTML != "" {
+		mediaType, content = "text/html", c.HTML
+	}
+	writeHeader(&b, "Content-Type", mediaType+"; charset=UTF-8")
+	writeHeader(&b, "Content-Transfer-Encoding", "quoted-printable")
+	b.WriteString("\r\n")
+	if err := writeQuotedPrintable(&b, content); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func writeHeader(b *bytes.Buffer, name, value string) {
+	b.WriteString(name + ": " + value + "\r\n")
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(content, "\r\n", "\n"))); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func formatAddresses(addresses []*mail.Address) string {
+	formatted := make([]string, len(addresses))
+	for i, a := range addresses {
+		formatted[i] = a.String()
+	}
+	return strings.Join(formatted, ", ")
+}
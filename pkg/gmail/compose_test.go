@@ -0,0 +1,118 @@
+package gmail
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestComposeMIME_Plain(t *testing.T) {
+	c := &Compose{
+		To:         "Jane Doe <jane@example.com>, bob@example.com",
+		Subject:    "Grüße",
+		Text:       "Hello,\nsee you.",
+		InReplyTo:  "<a@example.com>",
+		References: "<root@example.com> <a@example.com>",
+	}
+	raw, err := c.MIME()
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, `"Jane Doe" <jane@example.com>, <bob@example.com>`, msg.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Grüße", subject)
+	assert.Equal(t, "<a@example.com>", msg.Header.Get("In-Reply-To"))
+	assert.Equal(t, "<root@example.com> <a@example.com>", msg.Header.Get("References"))
+	assert.Equal(t, "text/plain; charset=UTF-8", msg.Header.Get("Content-Type"))
+	body, err := io.ReadAll(msg.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello,\r\nsee you.", string(body))
+}
+
+func TestComposeMIME_Alternative(t *testing.T) {
+	c := &Compose{To: "jane@example.com", Subject: "Hi", Text: "Hi *there*", HTML: "<p>Hi <b>there</b></p>"}
+	raw, err := c.MIME()
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	var parts []string
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(p)
+		require.NoError(t, err)
+		parts = append(parts, p.Header.Get("Content-Type")+": "+string(content))
+	}
+	assert.Equal(t, []string{
+		"text/plain; charset=UTF-8: Hi *there*",
+		"text/html; charset=UTF-8: <p>Hi <b>there</b></p>",
+	}, parts)
+}
+
+func TestComposeMIME_InvalidHeaders(t *testing.T) {
+	_, err := (&Compose{To: "jane@example.com", Subject: "Hi\r\nBcc: eve@example.com"}).MIME()
+	require.ErrorIs(t, err, ErrInvalidHeader)
+	_, err = (&Compose{To: "not an address"}).MIME()
+	require.ErrorIs(t, err, ErrInvalidHeader)
+}
+
+func TestNewReply(t *testing.T) {
+	msg := &gmail.Message{
+		ThreadId: "t1",
+		Payload: &gmail.MessagePart{Headers: []*gmail.MessagePartHeader{
+			{Name: "From", Value: "Jane <jane@example.com>"},
+			{Name: "To", Value: "me@example.com, \"Doe, Bob\" <bob@example.com>"},
+			{Name: "Cc", Value: "jane@example.com, carol@example.com"},
+			{Name: "Delivered-To", Value: "me@example.com"},
+			{Name: "Subject", Value: "Launch"},
+			{Name: "Message-ID", Value: "<b@example.com>"},
+			{Name: "References", Value: "<a@example.com>"},
+		}},
+	}
+
+	reply, err := NewReply(msg, false)
+	require.NoError(t, err)
+	assert.Equal(t, &Compose{
+		To:         `"Jane" <jane@example.com>`,
+		Subject:    "Re: Launch",
+		InReplyTo:  "<b@example.com>",
+		References: "<a@example.com> <b@example.com>",
+		ThreadID:   "t1",
+	}, reply)
+
+	reply, err = NewReply(msg, true)
+	require.NoError(t, err)
+	assert.Equal(t, `<carol@example.com>, "Doe, Bob" <bob@example.com>`, reply.Cc)
+
+	// Replies to one's own messages go to their recipients.
+	msg.LabelIds = []string{"SENT"}
+	msg.Payload.Headers[4].Value = "Re: Launch"
+	reply, err = NewReply(msg, false)
+	require.NoError(t, err)
+	assert.Equal(t, `<me@example.com>, "Doe, Bob" <bob@example.com>`, reply.To)
+	assert.Equal(t, "Re: Launch", reply.Subject)
+}
+
+func TestComposeRecipients(t *testing.T) {
+	recipients, err := (&Compose{To: "Jane <jane@example.com>", Bcc: "bob@example.com, carol@example.com"}).Recipients()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jane@example.com", "bob@example.com", "carol@example.com"}, recipients)
+}
@@ -0,0 +1,171 @@
+package gmail
+
+import (
+	"html"
+	"net/mail"
+	"strings"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// Message is a message reduced to what a reader needs: its key headers,
+// one plain-text body and the list of its attachments.
+type Message struct {
+	ID       string   `json:"id"`
+	ThreadID string   `json:"threadId"`
+	LabelIDs []string `json:"labelIds,omitempty"`
+	Snippet  string   `json:"snippet,omitempty"`
+	From     string   `json:"from"`
+	To       string   `json:"to,omitempty"`
+	Cc       string   `json:"cc,omitempty"`
+	Subject  string   `json:"subject"`
+	// Date is the Date header in RFC 3339 format, or the time Gmail
+	// received the message if the header is missing or invalid.
+	Date string `json:"date"`
+	// MessageID is the Message-ID header, used to reply in the thread.
+	MessageID   string       `json:"messageId,omitempty"`
+	Body        string       `json:"body"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a file attached to a message. Its content is fetched
+// separately by its ID.
+type Attachment struct {
+	ID       string `json:"attachmentId"`
+	Filename string `json:"filename"`
+	MimeType string `json:"mimeType"`
+	// Size is the size of the attachment in bytes.
+	Size int64 `json:"size"`
+	// Inline is set for files shown within the body, like images in HTML
+	// messages.
+	Inline bool `json:"inline,omitempty"`
+}
+
+// NewMessage normalizes a message of the Gmail API that was retrieved in
+// the "full" format. The body is the text/plain part of the message, or
+// its HTML part converted to text.
+func NewMessage(msg *gmail.Message) *Message {
+	m := &Message{
+		ID:        msg.Id,
+		ThreadID:  msg.ThreadId,
+		LabelIDs:  msg.LabelIds,
+		Snippet:   msg.Snippet,
+		From:      Header(msg.Payload, "From"),
+		To:        Header(msg.Payload, "To"),
+		Cc:        Header(msg.Payload, "Cc"),
+		Subject:   Header(msg.Payload, "Subject"),
+		MessageID: Header(msg.Payload, "Message-ID"),
+		Body:      BodyText(msg.Payload),
+		Date:      messageDate(msg),
+	}
+	m.Attachments = appendAttachments(nil, msg.Payload)
+	return m
+}
+
+// SearchHit is a compact summary of a message found by a search.
+type SearchHit struct {
+	ID       string `json:"id"`
+	ThreadID string `json:"threadId"`
+	Date     string `json:"date,omitempty"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Cc       string `json:"cc,omitempty"`
+	Subject  string `json:"subject,omitempty"`
+	Snippet  string `json:"snippet,omitempty"`
+	// Labels are the names of the labels of the message.
+	Labels []string `json:"labels,omitempty"`
+	// HasAttachments is set for multipart/mixed messages, the type that
+	// mail clients use for messages with attachments.
+	HasAttachments bool `json:"hasAttachments,omitempty"`
+	// ThreadSize is the number of messages of the thread, if known.
+	ThreadSize int `json:"threadSize,omitempty"`
+	// Headers holds the other headers that were fetched.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// NewSearchHit summarizes a message that was retrieved in the "metadata"
+// format. Label IDs are replaced by their names in labelNames, if found.
+func NewSearchHit(msg *gmail.Message, labelNames map[string]string, threadSize int) SearchHit {
+	hit := SearchHit{
+		ID:         msg.Id,
+		ThreadID:   msg.ThreadId,
+		Date:       messageDate(msg),
+		Snippet:    html.UnescapeString(msg.Snippet),
+		ThreadSize: threadSize,
+	}
+	for _, id := range msg.LabelIds {
+		if name, ok := labelNames[id]; ok {
+			id = name
+		}
+		hit.Labels = append(hit.Labels, id)
+	}
+	if msg.Payload == nil {
+		return hit
+	}
+	hit.HasAttachments = strings.EqualFold(msg.Payload.MimeType, "multipart/mixed")
+	for _, h := range msg.Payload.Headers {
+		switch {
+		case strings.EqualFold(h.Name, "From"):
+			hit.From = h.Value
+		case strings.EqualFold(h.Name, "To"):
+			hit.To = h.Value
+		case strings.EqualFold(h.Name, "Cc"):
+			hit.Cc = h.Value
+		case strings.EqualFold(h.Name, "Subject"):
+			hit.Subject = h.Value
+		case strings.EqualFold(h.Name, "Date"):
+		default:
+			if hit.Headers == nil {
+				hit.Headers = map[string]string{}
+			}
+			hit.Headers[h.Name] = h.Value
+		}
+	}
+	return hit
+}
+
+// messageDate returns the Date header of a message in RFC 3339 format, or
+// the time Gmail received the message if the header is missing or invalid.
+func messageDate(msg *gmail.Message) string {
+	if date, err := mail.ParseDate(Header(msg.Payload, "Date")); err == nil {
+		return date.Format(time.RFC3339)
+	}
+	if msg.InternalDate != 0 {
+		return time.UnixMilli(msg.InternalDate).UTC().Format(time.RFC3339)
+	}
+	return ""
+}
+
+// Header returns the value of the first header of a message part with the
+// name, ignoring case.
+func Header(part *gmail.MessagePart, name string) string {
+	if part == nil {
+		return ""
+	}
+	for _, h := range part.Headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+func appendAttachments(attachments []Attachment, part *gmail.MessagePart) []Attachment {
+	if part == nil {
+		return attachments
+	}
+	if part.Filename != "" && part.Body != nil {
+		attachments = append(attachments, Attachment{
+			ID:       part.Body.AttachmentId,
+			Filename: part.Filename,
+			MimeType: part.MimeType,
+			Size:     part.Body.Size,
+			Inline:   strings.HasPrefix(strings.ToLower(Header(part, "Content-Disposition")), "inline"),
+		})
+	}
+	for _, p := range part.Parts {
+		attachments = appendAttachments(attachments, p)
+	}
+	return attachments
+}
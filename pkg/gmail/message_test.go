@@ -0,0 +1,93 @@
+package gmail
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestNewMessage(t *testing.T) {
+	msg := &gmail.Message{
+		Id:           "m1",
+		ThreadId:     "t1",
+		LabelIds:     []string{"INBOX", "UNREAD"},
+		Snippet:      "Report attached",
+		InternalDate: 1709542800000,
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/mixed",
+			Headers: []*gmail.MessagePartHeader{
+				{Name: "From", Value: "Ann <ann@example.com>"},
+				{Name: "To", Value: "bob@example.com"},
+				{Name: "Subject", Value: "Report"},
+				{Name: "Date", Value: "Mon, 4 Mar 2024 10:15:00 +0100"},
+				{Name: "Message-Id", Value: "<abc@example.com>"},
+				{Name: "X-Mailer", Value: "Mail"},
+			},
+			Parts: []*gmail.MessagePart{
+				{MimeType: "multipart/related", Parts: []*gmail.MessagePart{
+					{MimeType: "text/html", Body: encodeBody("<p>Report <i>attached</i>.</p><img src=\"cid:logo\">")},
+					{
+						MimeType: "image/png", Filename: "logo.png",
+						Headers: []*gmail.MessagePartHeader{{Name: "Content-Disposition", Value: "inline; filename=logo.png"}},
+						Body:    &gmail.MessagePartBody{AttachmentId: "a1", Size: 512},
+					},
+				}},
+				{MimeType: "application/pdf", Filename: "report.pdf", Body: &gmail.MessagePartBody{AttachmentId: "a2", Size: 20480}},
+			},
+		},
+	}
+
+	assert.Equal(t, &Message{
+		ID:        "m1",
+		ThreadID:  "t1",
+		LabelIDs:  []string{"INBOX", "UNREAD"},
+		Snippet:   "Report attached",
+		From:      "Ann <ann@example.com>",
+		To:        "bob@example.com",
+		Subject:   "Report",
+		Date:      "2024-03-04T10:15:00+01:00",
+		MessageID: "<abc@example.com>",
+		Body:      "Report *attached*.",
+		Attachments: []Attachment{
+			{ID: "a1", Filename: "logo.png", MimeType: "image/png", Size: 512, Inline: true},
+			{ID: "a2", Filename: "report.pdf", MimeType: "application/pdf", Size: 20480},
+		},
+	}, NewMessage(msg))
+
+	// Without a Date header, the date is when Gmail received the message.
+	msg.Payload.Headers = nil
+	assert.Equal(t, "2024-03-04T09:00:00Z", NewMessage(msg).Date)
+}
+
+func TestNewSearchHit(t *testing.T) {
+	msg := &gmail.Message{
+		Id:           "m1",
+		ThreadId:     "t1",
+		LabelIds:     []string{"INBOX", "Label_7"},
+		Snippet:      "Here&#39;s the report",
+		InternalDate: 1709542800000,
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/mixed",
+			Headers: []*gmail.MessagePartHeader{
+				{Name: "From", Value: "Ann <ann@example.com>"},
+				{Name: "To", Value: "bob@example.com"},
+				{Name: "Subject", Value: "Report"},
+				{Name: "List-Id", Value: "<reports.example.com>"},
+			},
+		},
+	}
+	assert.Equal(t, SearchHit{
+		ID:             "m1",
+		ThreadID:       "t1",
+		Date:           "2024-03-04T09:00:00Z",
+		From:           "Ann <ann@example.com>",
+		To:             "bob@example.com",
+		Subject:        "Report",
+		Snippet:        "Here's the report",
+		Labels:         []string{"INBOX", "Reports"},
+		HasAttachments: true,
+		ThreadSize:     3,
+		Headers:        map[string]string{"List-Id": "<reports.example.com>"},
+	}, NewSearchHit(msg, map[string]string{"Label_7": "Reports"}, 3))
+}
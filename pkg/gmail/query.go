@@ -0,0 +1,166 @@
+package gmail
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const daysPerWeek = 7
+
+// ErrInvalidQuery is returned when a field of a Query cannot be compiled.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// relativeDatePattern matches relative dates: "last 7 days", "past week",
+// "3 months ago" and the short form "7d".
+var relativeDatePattern = regexp.MustCompile(
+	`^(?:(?:last|past)\s+(?:(\d+)\s+)?(day|week|month|year)s?|(\d+)\s+(day|week|month|year)s?\s+ago|(\d+)([dwmy]))$`)
+
+// sizePattern matches sizes in bytes with an optional K or M unit: "500",
+// "100K", "5 MB".
+var sizePattern = regexp.MustCompile(`^(\d+)\s*([km]?)b?$`)
+
+// Query is a search with structured fields, compiled to the Gmail search
+// syntax by Compile. Empty fields are not part of the search.
+type Query struct {
+	From    string
+	To      string
+	Subject string
+	// Label is the name of a label, like "Work/Projects".
+	Label         string
+	HasAttachment *bool
+	Unread        *bool
+	// LargerThan is a size in bytes with an optional unit, like "5M".
+	LargerThan string
+	// After and Before are dates ("2024-03-01" or "2024/03/01") or dates
+	// relative to now ("today", "yesterday", "last 7 days", "2 weeks ago").
+	After  string
+	Before string
+	// Text is free text, searched in the whole message.
+	Text string
+}
+
+// Compile returns the Gmail search string of the query, with relative dates
+// resolved against now.
+func (q *Query) Compile(now time.Time) (string, error) {
+	var terms []string
+	for _, f := range []struct{ operator, value string }{{"from", q.From}, {"to", q.To}, {"subject", q.Subject}} {
+		if v := strings.TrimSpace(f.value); v != "" {
+			terms = append(terms, f.operator+":"+quoteTerm(v))
+		}
+	}
+	if label := strings.TrimSpace(q.Label); label != "" {
+		// Gmail writes spaces and the slashes of nested labels as dashes.
+		terms = append(terms, "label:"+quoteTerm(strings.NewReplacer(" ", "-", "/", "-").Replace(label)))
+	}
+	if q.HasAttachment != nil {
+		if *q.HasAttachment {
+			terms = append(terms, "has:attachment")
+		} else {
+			terms = append(terms, "-has:attachment")
+		}
+	}
+	if q.Unread != nil {
+		if *q.Unread {
+			terms = append(terms, "is:unread")
+		} else {
+			terms = append(terms, "is:read")
+		}
+	}
+	if q.LargerThan != "" {
+		size, err := compileSize(q.LargerThan)
+		if err != nil {
+			return "", err
+		}
+		terms = append(terms, "larger:"+size)
+	}
+	for _, f := range []struct{ operator, value string }{{"after", q.After}, {"before", q.Before}} {
+		if strings.TrimSpace(f.value) == "" {
+			continue
+		}
+		date, err := ParseDate(f.value, now)
+		if err != nil {
+			return "", err
+		}
+		terms = append(terms, f.operator+":"+date.Format("2006/01/02"))
+	}
+	if text := strings.TrimSpace(q.Text); text != "" {
+		terms = append(terms, text)
+	}
+	if len(terms) == 0 {
+		return "", fmt.Errorf("%w: no fields are set", ErrInvalidQuery)
+	}
+	return strings.Join(terms, " "), nil
+}
+
+// ParseDate parses an absolute date ("2024-03-01", "2024/03/01" or an RFC
+// 3339 time) or a date relative to now ("today", "yesterday",
+// "last 7 days", "past month", "2 weeks ago", "7d").
+func ParseDate(value string, now time.Time) (time.Time, error) {
+	trimmed := strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02", "2006/01/02", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, trimmed, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	v := strings.ToLower(trimmed)
+	switch v {
+	case "today":
+		return now, nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1), nil
+	}
+
+	m := relativeDatePattern.FindStringSubmatch(v)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("%w: date %q", ErrInvalidQuery, value)
+	}
+	// One of the three alternatives of the pattern matched.
+	number, unit := m[1], m[2]
+	if m[4] != "" {
+		number, unit = m[3], m[4]
+	} else if m[6] != "" {
+		number, unit = m[5], m[6]
+	}
+	n := 1
+	if number != "" {
+		var err error
+		if n, err = strconv.Atoi(number); err != nil {
+			return time.Time{}, fmt.Errorf("%w: date %q: %w", ErrInvalidQuery, value, err)
+		}
+	}
+	switch unit[0] {
+	case 'd':
+		return now.AddDate(0, 0, -n), nil
+	case 'w':
+		return now.AddDate(0, 0, -daysPerWeek*n), nil
+	case 'm':
+		return now.AddDate(0, -n, 0), nil
+	default:
+		return now.AddDate(-n, 0, 0), nil
+	}
+}
+
+// compileSize converts a size to the Gmail syntax: bytes, or a number
+// followed by K or M.
+func compileSize(value string) (string, error) {
+	m := sizePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	if m == nil {
+		return "", fmt.Errorf("%w: size %q", ErrInvalidQuery, value)
+	}
+	return m[1] + strings.ToUpper(m[2]), nil
+}
+
+// quoteTerm quotes a value that contains spaces or characters that have a
+// meaning in the search syntax. Gmail has no escape for quotes, so they are
+// removed.
+func quoteTerm(value string) string {
+	value = strings.ReplaceAll(value, `"`, "")
+	if strings.ContainsAny(value, " \t(){}:") {
+		return `"` + value + `"`
+	}
+	return value
+}
@@ -0,0 +1,96 @@
+package gmail
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCompile(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	yes, no := true, false
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{
+			name:  "addresses and subject",
+			query: Query{From: "Jane Doe", To: "team@example.com", Subject: "Q1 report (draft)"},
+			want:  `from:"Jane Doe" to:team@example.com subject:"Q1 report (draft)"`,
+		},
+		{
+			name:  "nested label",
+			query: Query{Label: "Work/Big Projects"},
+			want:  "label:Work-Big-Projects",
+		},
+		{
+			name:  "flags and size",
+			query: Query{HasAttachment: &yes, Unread: &no, LargerThan: "5 MB"},
+			want:  "has:attachment is:read larger:5M",
+		},
+		{
+			name:  "no attachment",
+			query: Query{HasAttachment: &no, Unread: &yes},
+			want:  "-has:attachment is:unread",
+		},
+		{
+			name:  "dates and text",
+			query: Query{After: "last 7 days", Before: "2024-03-14", Text: "invoice OR receipt"},
+			want:  "after:2024/03/08 before:2024/03/14 invoice OR receipt",
+		},
+		{
+			name:  "quotes are removed",
+			query: Query{Subject: `say "hi"`},
+			want:  `subject:"say hi"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.query.Compile(now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQueryCompile_Errors(t *testing.T) {
+	now := time.Now()
+	for _, q := range []Query{
+		{},
+		{From: "  "},
+		{After: "next tuesday"},
+		{LargerThan: "big"},
+	} {
+		_, err := q.Compile(now)
+		assert.ErrorIs(t, err, ErrInvalidQuery)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	tests := map[string]string{
+		"today":                     "2024-03-15",
+		"Yesterday":                 "2024-03-14",
+		"last 7 days":               "2024-03-08",
+		"past week":                 "2024-03-08",
+		"last 2 months":             "2024-01-15",
+		"3 weeks ago":               "2024-02-23",
+		"1 year ago":                "2023-03-15",
+		"10d":                       "2024-03-05",
+		"2024/02/29":                "2024-02-29",
+		"2024-02-01":                "2024-02-01",
+		"2024-03-01T10:00:00Z":      "2024-03-01",
+		"2024-03-01T23:30:00-05:00": "2024-03-01",
+	}
+	for value, want := range tests {
+		got, err := ParseDate(value, now)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, got.Format("2006-01-02"), value)
+	}
+
+	_, err := ParseDate("last 99999999999999999999 days", now)
+	require.ErrorIs(t, err, ErrInvalidQuery)
+}
@@ -0,0 +1,27 @@
+package gmail
+
+import (
+	"slices"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// CanCompose reports whether the OAuth scopes allow writing drafts.
+func CanCompose(scopes []string) bool {
+	return slices.ContainsFunc(scopes, func(scope string) bool {
+		return scope == gmail.GmailComposeScope || scope == gmail.GmailModifyScope || scope == gmail.MailGoogleComScope
+	})
+}
+
+// CanSend reports whether the OAuth scopes allow sending messages.
+func CanSend(scopes []string) bool {
+	return CanCompose(scopes) || slices.Contains(scopes, gmail.GmailSendScope)
+}
+
+// CanModify reports whether the OAuth scopes allow changing the labels of
+// messages and moving them to the trash.
+func CanModify(scopes []string) bool {
+	return slices.ContainsFunc(scopes, func(scope string) bool {
+		return scope == gmail.GmailModifyScope || scope == gmail.MailGoogleComScope
+	})
+}
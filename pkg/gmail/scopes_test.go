@@ -0,0 +1,18 @@
+package gmail
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestScopes(t *testing.T) {
+	assert.False(t, CanCompose(nil))
+	assert.True(t, CanCompose([]string{gmail.GmailComposeScope}))
+	assert.False(t, CanCompose([]string{gmail.GmailSendScope}))
+	assert.True(t, CanSend([]string{gmail.GmailSendScope}))
+	assert.True(t, CanSend([]string{gmail.GmailComposeScope}))
+	assert.False(t, CanModify([]string{gmail.GmailComposeScope}))
+	assert.True(t, CanModify([]string{gmail.GmailModifyScope}))
+}
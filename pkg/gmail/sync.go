@@ -0,0 +1,290 @@
+package gmail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+const (
+	// firstSyncWindow is how far back the first check looks for messages.
+	firstSyncWindow = 24 * time.Hour
+	// DefaultMaxSyncMessages is the number of added messages whose metadata
+	// a check returns.
+	DefaultMaxSyncMessages = 100
+)
+
+// ErrSyncState is returned when the sync state file cannot be read or
+// written.
+var ErrSyncState = errors.New("unable to access sync state")
+
+// SyncState is what a Syncer stores between checks.
+type SyncState struct {
+	HistoryID uint64    `json:"historyId"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// LabelChange lists the label IDs added to and removed from a message.
+type LabelChange struct {
+	ID      string   `json:"id"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Changes are the changes of the mailbox since the previous check.
+type Changes struct {
+	// Since is the time of the previous check, or zero on the first check.
+	Since time.Time
+	// FullResync is set when there was no history to continue from, and
+	// the added messages were found by searching for messages received
+	// since the previous check (or in the last day on the first check).
+	// Deleted messages and label changes are unknown then.
+	FullResync bool
+	// Messages are the added messages, newest first, in the metadata
+	// format.
+	Messages []*gmail.Message
+	// More is set when more than MaxMessages messages were added; only the
+	// newest are returned.
+	More bool
+	// Errors lists the added messages that could not be fetched.
+	Errors []MessageError
+	// ThreadSizes maps the IDs of the threads of the messages to their
+	// number of messages.
+	ThreadSizes map[string]int
+	// Deleted are the IDs of deleted messages.
+	Deleted []string
+	// LabelChanges are the label changes of messages that were neither
+	// added nor deleted.
+	LabelChanges []LabelChange
+}
+
+// Syncer returns the changes of the mailbox since its previous check. It
+// stores the history ID of the mailbox in a local file and lists the
+// history after it, or searches for recent messages when the history ID
+// has expired.
+type Syncer struct {
+	Client API
+	// StatePath is the JSON file that the SyncState is stored in.
+	StatePath string
+	// LabelID limits the changes to messages with a system label, like
+	// INBOX (the default of NewSyncer). Empty includes all messages.
+	LabelID string
+	// MaxMessages is the number of added messages whose metadata is
+	// returned (default DefaultMaxSyncMessages).
+	MaxMessages int
+
+	// now returns the current time; it is replaced in tests.
+	now func() time.Time
+	mu  sync.Mutex
+}
+
+// NewSyncer creates a Syncer of the inbox.
+func NewSyncer(client API, statePath string) *Syncer {
+	return &Syncer{Client: client, StatePath: statePath, LabelID: "INBOX"}
+}
+
+// Changes returns the changes since the previous call and stores the
+// current history ID for the next one.
+func (s *Syncer) Changes() (*Changes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.loadState()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if s.now != nil {
+		now = s.now()
+	}
+
+	var changes *Changes
+	var historyID uint64
+	if state.HistoryID != 0 {
+		changes, historyID, err = s.history(state.HistoryID)
+		if err != nil && !errors.Is(err, ErrHistoryExpired) {
+			return nil, err
+		}
+	}
+	if changes == nil {
+		since := state.CheckedAt
+		if since.IsZero() {
+			since = now.Add(-firstSyncWindow)
+		}
+		changes, historyID, err = s.resync(since)
+		if err != nil {
+			return nil, err
+		}
+	}
+	changes.Since = state.CheckedAt
+
+	if err := s.saveState(SyncState{HistoryID: historyID, CheckedAt: now}); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// history lists the changes after startHistoryID and returns them with the
+// current history ID.
+func (s *Syncer) history(startHistoryID uint64) (*Changes, uint64, error) {
+	var added, deleted []string
+	var changedIDs []string
+	labelChanges := map[string]*LabelChange{}
+	change := func(id string) *LabelChange {
+		c, ok := labelChanges[id]
+		if !ok {
+			c = &LabelChange{ID: id}
+			labelChanges[id] = c
+			changedIDs = append(changedIDs, id)
+		}
+		return c
+	}
+
+	historyID := startHistoryID
+	pageToken := ""
+	for {
+		r, err := s.Client.ListHistory(startHistoryID, s.LabelID, pageToken)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, h := range r.History {
+			for _, m := range h.MessagesAdded {
+				if !slices.Contains(added, m.Message.Id) {
+					added = append(added, m.Message.Id)
+				}
+			}
+			for _, m := range h.MessagesDeleted {
+				if !slices.Contains(deleted, m.Message.Id) {
+					deleted = append(deleted, m.Message.Id)
+				}
+			}
+			for _, l := range h.LabelsAdded {
+				c := change(l.Message.Id)
+				c.Removed, c.Added = toggle(c.Removed, c.Added, l.LabelIds)
+			}
+			for _, l := range h.LabelsRemoved {
+				c := change(l.Message.Id)
+				c.Added, c.Removed = toggle(c.Added, c.Removed, l.LabelIds)
+			}
+		}
+		if r.HistoryId != 0 {
+			historyID = r.HistoryId
+		}
+		if r.NextPageToken == "" {
+			break
+		}
+		pageToken = r.NextPageToken
+	}
+
+	// Added messages that were deleted again are left out, and so are the
+	// label changes of added and deleted messages: the labels of added
+	// messages are part of their metadata.
+	added = slices.DeleteFunc(added, func(id string) bool { return slices.Contains(deleted, id) })
+	changes := &Changes{Deleted: deleted}
+	for _, id := range changedIDs {
+		c := labelChanges[id]
+		if slices.Contains(added, id) || slices.Contains(deleted, id) || (len(c.Added) == 0 && len(c.Removed) == 0) {
+			continue
+		}
+		changes.LabelChanges = append(changes.LabelChanges, *c)
+	}
+
+	// The history lists the oldest messages first.
+	slices.Reverse(added)
+	maxMessages := s.maxMessages()
+	if len(added) > maxMessages {
+		added = added[:maxMessages]
+		changes.More = true
+	}
+	result := s.Client.GetMetadata(added)
+	changes.Messages = result.Messages
+	changes.Errors = result.Errors
+	changes.ThreadSizes = result.ThreadSizes
+	return changes, historyID, nil
+}
+
+// toggle moves labels that were undone from undone, and adds the others to
+// done.
+func toggle(undone, done, labels []string) ([]string, []string) {
+	for _, l := range labels {
+		if i := slices.Index(undone, l); i >= 0 {
+			undone = slices.Delete(undone, i, i+1)
+		} else if !slices.Contains(done, l) {
+			done = append(done, l)
+		}
+	}
+	return undone, done
+}
+
+// resync searches for the messages received since the given time and
+// returns them with the current history ID.
+func (s *Syncer) resync(since time.Time) (*Changes, uint64, error) {
+	// The history ID is read before searching, so that messages added
+	// during the search are listed by the next check.
+	profile, err := s.Client.GetProfile()
+	if err != nil {
+		return nil, 0, err
+	}
+	query := fmt.Sprintf("after:%d", since.Unix())
+	if s.LabelID != "" {
+		query = "label:" + strings.ToLower(s.LabelID) + " " + query
+	}
+	result, err := s.Client.SearchMessages(query, "", int64(s.maxMessages()))
+	if err != nil {
+		return nil, 0, err
+	}
+	return &Changes{
+		FullResync:  true,
+		Messages:    result.Messages,
+		More:        result.NextPageToken != "",
+		Errors:      result.Errors,
+		ThreadSizes: result.ThreadSizes,
+	}, profile.HistoryId, nil
+}
+
+func (s *Syncer) maxMessages() int {
+	if s.MaxMessages > 0 {
+		return s.MaxMessages
+	}
+	return DefaultMaxSyncMessages
+}
+
+// loadState reads the stored state, which is empty before the first check.
+func (s *Syncer) loadState() (SyncState, error) {
+	var state SyncState
+	data, err := os.ReadFile(s.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("%w: %w", ErrSyncState, err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("%w: %s: %w", ErrSyncState, s.StatePath, err)
+	}
+	return state, nil
+}
+
+// saveState replaces the stored state, writing a temporary file first so
+// that the state is never left half written.
+func (s *Syncer) saveState(state SyncState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSyncState, err)
+	}
+	tmp := s.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("%w: %w", ErrSyncState, err)
+	}
+	if err := os.Rename(tmp, s.StatePath); err != nil {
+		return fmt.Errorf("%w: %w", ErrSyncState, err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package gmail
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// historyServer serves a mailbox whose history starts at ID 100: later
+// history IDs have expired.
+func historyServer(t *testing.T, searches *[]string) *Client {
+	t.Helper()
+	return setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		q := r.URL.Query()
+		switch path := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me"); {
+		case path == "/profile":
+			fmt.Fprintln(w, `{"emailAddress": "me@example.com", "historyId": "200"}`)
+		case path == "/history":
+			assert.Equal(t, "INBOX", q.Get("labelId"))
+			if q.Get("startHistoryId") != "100" {
+				http.Error(w, `{"error": {"code": 404, "message": "Requested entity was not found."}}`, http.StatusNotFound)
+				return
+			}
+			if q.Get("pageToken") == "" {
+				fmt.Fprintln(w, `{"history": [
+					{"id": "101", "messagesAdded": [{"message": {"id": "m1"}}]},
+					{"id": "102", "labelsRemoved": [{"message": {"id": "m5"}, "labelIds": ["UNREAD"]}]},
+					{"id": "103", "labelsAdded": [{"message": {"id": "m6"}, "labelIds": ["STARRED"]}]}
+				], "historyId": "150", "nextPageToken": "p2"}`)
+				return
+			}
+			fmt.Fprintln(w, `{"history": [
+				{"id": "104", "messagesAdded": [{"message": {"id": "m2"}}, {"message": {"id": "m3"}}]},
+				{"id": "105", "messagesDeleted": [{"message": {"id": "m3"}}, {"message": {"id": "m4"}}]},
+				{"id": "106", "labelsRemoved": [{"message": {"id": "m6"}, "labelIds": ["STARRED"]}]},
+				{"id": "107", "labelsAdded": [{"message": {"id": "m1"}, "labelIds": ["STARRED"]}]}
+			], "historyId": "160"}`)
+		case path == "/messages":
+			*searches = append(*searches, q.Get("q"))
+			fmt.Fprintln(w, `{"messages": [{"id": "m9"}]}`)
+		case strings.HasPrefix(path, "/messages/"):
+			fmt.Fprintf(w, `{"id": %q, "threadId": "t1"}`, strings.TrimPrefix(path, "/messages/"))
+		case strings.HasPrefix(path, "/threads/"):
+			fmt.Fprintln(w, `{"messages": [{"id": "m1"}]}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	})
+}
+
+func readSyncState(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestSyncerChanges(t *testing.T) {
+	var searches []string
+	statePath := filepath.Join(t.TempDir(), "sync.json")
+	syncer := NewSyncer(historyServer(t, &searches), statePath)
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	syncer.now = func() time.Time { return now }
+
+	// The first check searches for the messages of the last day.
+	changes, err := syncer.Changes()
+	require.NoError(t, err)
+	assert.True(t, changes.FullResync)
+	assert.True(t, changes.Since.IsZero())
+	require.Len(t, changes.Messages, 1)
+	assert.Equal(t, "m9", changes.Messages[0].Id)
+	assert.Equal(t, []string{fmt.Sprintf("label:inbox after:%d", now.Add(-24*time.Hour).Unix())}, searches)
+	assert.JSONEq(t, `{"historyId": 200, "checkedAt": "2024-03-10T12:00:00Z"}`, readSyncState(t, statePath))
+
+	// The history after ID 100 is listed over two pages.
+	require.NoError(t, os.WriteFile(statePath, []byte(`{"historyId": 100, "checkedAt": "2024-03-10T12:00:00Z"}`), 0o600))
+	now = now.Add(time.Hour)
+	changes, err = syncer.Changes()
+	require.NoError(t, err)
+	assert.False(t, changes.FullResync)
+	assert.Equal(t, time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC), changes.Since)
+	// m3 was added and deleted again; the newest message comes first.
+	require.Len(t, changes.Messages, 2)
+	assert.Equal(t, "m2", changes.Messages[0].Id)
+	assert.Equal(t, "m1", changes.Messages[1].Id)
+	assert.Equal(t, []string{"m3", "m4"}, changes.Deleted)
+	// The star of m6 was removed again, and m1 is an added message.
+	assert.Equal(t, []LabelChange{{ID: "m5", Removed: []string{"UNREAD"}}}, changes.LabelChanges)
+	assert.JSONEq(t, `{"historyId": 160, "checkedAt": "2024-03-10T13:00:00Z"}`, readSyncState(t, statePath))
+
+	// The history after ID 160 has expired, so the messages since the last
+	// check are searched.
+	now = now.Add(time.Hour)
+	changes, err = syncer.Changes()
+	require.NoError(t, err)
+	assert.True(t, changes.FullResync)
+	assert.Equal(t, fmt.Sprintf("label:inbox after:%d", now.Add(-time.Hour).Unix()), searches[1])
+	assert.JSONEq(t, `{"historyId": 200, "checkedAt": "2024-03-10T14:00:00Z"}`, readSyncState(t, statePath))
+}
+
+func TestSyncerMaxMessages(t *testing.T) {
+	var searches []string
+	statePath := filepath.Join(t.TempDir(), "sync.json")
+	require.NoError(t, os.WriteFile(statePath, []byte(`{"historyId": 100}`), 0o600))
+	syncer := NewSyncer(historyServer(t, &searches), statePath)
+	syncer.MaxMessages = 1
+
+	changes, err := syncer.Changes()
+	require.NoError(t, err)
+	assert.True(t, changes.More)
+	require.Len(t, changes.Messages, 1)
+	assert.Equal(t, "m2", changes.Messages[0].Id)
+}
+
+func TestSyncerInvalidState(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "sync.json")
+	require.NoError(t, os.WriteFile(statePath, []byte(`{`), 0o600))
+	var searches []string
+	_, err := NewSyncer(historyServer(t, &searches), statePath).Changes()
+	require.ErrorIs(t, err, ErrSyncState)
+	assert.Empty(t, searches)
+}
@@ -0,0 +1,165 @@
+package gmail
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+
+	"github.com/bttk/bttk-mcp/internal/htmlmd"
+	"google.golang.org/api/gmail/v1"
+)
+
+// maxAttributionLines is the number of lines that the attribution of a
+// quote ("On Mon, 1 Jan 2024, Jane <jane@example.com> wrote:") may wrap to.
+const maxAttributionLines = 3
+
+// quoteHeaderPattern matches the lines that clients put before a quoted
+// message: "-----Original Message-----" or a line of underscores.
+var quoteHeaderPattern = regexp.MustCompile(`(?i)^(-{2,}\s*original message\s*-{2,}|_{10,})$`)
+
+// mobileSignaturePattern matches the signatures that mobile clients add.
+var mobileSignaturePattern = regexp.MustCompile(`(?i)^(sent from my |sent from mail for |get outlook for )`)
+
+// inlineImagePattern matches the Markdown of images that HTML messages
+// show from their inline attachments ("cid:" URLs), which are listed as
+// attachments instead.
+var inlineImagePattern = regexp.MustCompile(`!\[[^\]]*\]\(<?cid:[^)]*\)`)
+
+// DecodeBody decodes the base64url data of a message part.
+func DecodeBody(data string) ([]byte, error) {
+	decoded, err := base64.URLEncoding.DecodeString(data)
+	if err != nil {
+		// Try raw if standard fails (sometimes padding is missing)
+		decoded, err = base64.RawURLEncoding.DecodeString(data)
+	}
+	return decoded, err
+}
+
+// BodyText returns the text of a message: its first text/plain part, or
+// its first text/html part converted to Markdown when there is no plain
+// text. Attached text files are not part of the body.
+func BodyText(payload *gmail.MessagePart) string {
+	if text, ok := findBody(payload, "text/plain"); ok {
+		return normalizeNewlines(text)
+	}
+	if html, ok := findBody(payload, "text/html"); ok {
+		doc, err := htmlmd.Convert(html, htmlmd.Options{})
+		if err == nil {
+			return strings.TrimSpace(inlineImagePattern.ReplaceAllString(doc.Markdown, ""))
+		}
+	}
+	return ""
+}
+
+func findBody(part *gmail.MessagePart, mimeType string) (string, bool) {
+	if part == nil {
+		return "", false
+	}
+	if strings.HasPrefix(part.MimeType, mimeType) && part.Filename == "" && part.Body != nil && part.Body.Data != "" {
+		if data, err := DecodeBody(part.Body.Data); err == nil {
+			return string(data), true
+		}
+	}
+	for _, p := range part.Parts {
+		if text, ok := findBody(p, mimeType); ok {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+func normalizeNewlines(text string) string {
+	return strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+}
+
+// StripQuotes removes the quoted messages that replies carry: an Outlook
+// header block or an "Original Message" marker and everything after it,
+// lines quoted with ">", and the attribution lines ("On ..., Jane wrote:")
+// that introduce them. Text after a quote is kept, so that bottom-posted
+// and interleaved replies keep the sender's own text.
+func StripQuotes(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		if isHeaderBlock(lines, i) {
+			break
+		}
+		if end := attributionEnd(lines, i); end > 0 {
+			i = end - 1
+			continue
+		}
+		if isQuoted(lines[i]) {
+			continue
+		}
+		// Blank lines around removed quotes are collapsed.
+		blank := strings.TrimSpace(lines[i]) == ""
+		if blank && len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+			continue
+		}
+		kept = append(kept, lines[i])
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+func isQuoted(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), ">")
+}
+
+// isHeaderBlock reports whether lines[i] starts the block that Outlook and
+// other top-posting clients put before the whole quoted message.
+func isHeaderBlock(lines []string, i int) bool {
+	line := strings.TrimSpace(lines[i])
+	if quoteHeaderPattern.MatchString(line) {
+		return true
+	}
+	// Outlook: "From: ...", then "Sent: ..." or "Date: ..." on the next line.
+	if (strings.HasPrefix(line, "From: ") || strings.HasPrefix(line, "**From:**")) && i+1 < len(lines) {
+		next := strings.TrimLeft(strings.TrimSpace(lines[i+1]), "*")
+		return strings.HasPrefix(next, "Sent:") || strings.HasPrefix(next, "Date:")
+	}
+	return false
+}
+
+// attributionEnd returns the index of the line after the attribution that
+// starts at lines[i] ("On ..., Jane wrote:"), or 0 if there is none. An
+// attribution is followed by a quote: the next line that is not blank is
+// quoted with ">", or there is none.
+func attributionEnd(lines []string, i int) int {
+	if !strings.HasPrefix(strings.TrimSpace(lines[i]), "On ") {
+		return 0
+	}
+	// The attribution may wrap over a few lines.
+	for j := i; j < min(i+maxAttributionLines, len(lines)); j++ {
+		if !strings.HasSuffix(strings.TrimSpace(lines[j]), "wrote:") {
+			continue
+		}
+		for _, line := range lines[j+1:] {
+			if strings.TrimSpace(line) != "" {
+				if !isQuoted(line) {
+					return 0
+				}
+				break
+			}
+		}
+		return j + 1
+	}
+	return 0
+}
+
+// StripSignature removes the signature of a message: everything after the
+// "-- " signature delimiter, and the last line if mobile clients add it
+// ("Sent from my iPhone").
+func StripSignature(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "-- " || line == "--" {
+			lines = lines[:i]
+			break
+		}
+	}
+	text = strings.TrimSpace(strings.Join(lines, "\n"))
+	if i := strings.LastIndexByte(text, '\n'); mobileSignaturePattern.MatchString(text[i+1:]) {
+		text = strings.TrimSpace(text[:i+1])
+	}
+	return text
+}
@@ -0,0 +1,96 @@
+package gmail
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/gmail/v1"
+)
+
+func encodeBody(s string) *gmail.MessagePartBody {
+	return &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(s))}
+}
+
+func TestBodyText(t *testing.T) {
+	alternative := &gmail.MessagePart{
+		MimeType: "multipart/mixed",
+		Parts: []*gmail.MessagePart{
+			{MimeType: "multipart/alternative", Parts: []*gmail.MessagePart{
+				{MimeType: "text/plain", Body: encodeBody("Hello,\r\nplain text.\r\n")},
+				{MimeType: "text/html", Body: encodeBody("<p>Hello, <b>HTML</b>.</p>")},
+			}},
+			{MimeType: "text/plain", Filename: "notes.txt", Body: &gmail.MessagePartBody{AttachmentId: "a1"}},
+		},
+	}
+	assert.Equal(t, "Hello,\nplain text.", BodyText(alternative))
+
+	htmlOnly := &gmail.MessagePart{MimeType: "text/html", Body: encodeBody("<div>Hello, <b>HTML</b>.</div><div>Bye</div>")}
+	assert.Equal(t, "Hello, **HTML**.\n\nBye", BodyText(htmlOnly))
+
+	assert.Empty(t, BodyText(&gmail.MessagePart{MimeType: "image/png", Filename: "a.png"}))
+}
+
+func TestStripQuotes(t *testing.T) {
+	tests := []struct {
+		name, text, want string
+	}{
+		{
+			name: "gmail attribution",
+			text: "Sounds good.\n\nOn Mon, Mar 4, 2024 at 10:00 AM Jane Doe <jane@example.com>\nwrote:\n> Shall we meet?\n> Jane",
+			want: "Sounds good.",
+		},
+		{
+			name: "outlook header",
+			text: "Done.\n\n________________________________\nFrom: Jane Doe\nSent: Monday, March 4, 2024\nSubject: Task",
+			want: "Done.",
+		},
+		{
+			name: "outlook header without separator",
+			text: "Done.\n\nFrom: Jane Doe <jane@example.com>\nDate: Monday, March 4, 2024\n\nOld text",
+			want: "Done.",
+		},
+		{
+			name: "original message",
+			text: "See below.\n-----Original Message-----\nOld text",
+			want: "See below.",
+		},
+		{
+			name: "inline quotes",
+			text: "> Can you come?\nYes.\n> And bring the slides?\nSure.",
+			want: "Yes.\nSure.",
+		},
+		{
+			name: "bottom post",
+			text: "On Mon, 1 Jan 2024, Jane <jane@example.com> wrote:\n> Can you send the report?\n\nSure, attached is the Q3 report.",
+			want: "Sure, attached is the Q3 report.",
+		},
+		{
+			name: "interleaved",
+			text: "On Mon, 1 Jan 2024, Jane <jane@example.com> wrote:\n> Can you come?\n\nYes.\n\n> And bring the slides?\n\nSure.\n",
+			want: "Yes.\n\nSure.",
+		},
+		{
+			name: "attribution in text",
+			text: "On Monday Jane wrote:\nthe plan is ready.",
+			want: "On Monday Jane wrote:\nthe plan is ready.",
+		},
+		{
+			name: "not a quote",
+			text: "On Monday we ship.\nFrom: the release notes",
+			want: "On Monday we ship.\nFrom: the release notes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, StripQuotes(tt.text))
+		})
+	}
+}
+
+func TestStripSignature(t *testing.T) {
+	assert.Equal(t, "Thanks!", StripSignature("Thanks!\n\n-- \nJane Doe\nACME Corp"))
+	assert.Equal(t, "Thanks!", StripSignature("Thanks!\n\nSent from my iPhone"))
+	assert.Equal(t, "Use -- for options.\nSent from my phone, the fix works.\n\nBye",
+		StripSignature("Use -- for options.\nSent from my phone, the fix works.\n\nBye"))
+}
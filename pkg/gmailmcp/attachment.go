@@ -0,0 +1,300 @@
+package gmailmcp
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bttk/bttk-mcp/pkg/gmail"
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultMaxAttachmentBytes is the size of the largest attachment that is
+// returned or saved if AttachmentOptions.MaxBytes is not set.
+const DefaultMaxAttachmentBytes = 5 << 20
+
+const (
+	saveLocal = "local"
+	saveVault = "vault"
+)
+
+var (
+	errAttachmentNotFound = errors.New("attachment not found")
+	errAttachmentTooLarge = errors.New("attachment is too large")
+)
+
+// VaultWriter saves files to the Obsidian vault. It is implemented by
+// *obsidian.VaultService.
+type VaultWriter interface {
+	AvailablePath(ctx context.Context, filePath string) (string, error)
+	Upload(ctx context.Context, path string, data []byte, contentType string) error
+}
+
+// AttachmentOptions configures the gmail_get_attachment tool.
+type AttachmentOptions struct {
+	// MaxBytes is the size of the largest attachment that is returned or
+	// saved (default DefaultMaxAttachmentBytes).
+	MaxBytes int64
+	// Dir is the local directory that attachments can be saved to. Saving
+	// locally is disabled if it is empty.
+	Dir string
+	// Vault and VaultFolder set where in the Obsidian vault attachments can
+	// be saved to. Saving to the vault is disabled if Vault is nil.
+	Vault       VaultWriter
+	VaultFolder string
+}
+
+// attachmentInfo describes an attachment returned or saved by the tool.
+type attachmentInfo struct {
+	MessageID string `json:"messageId"`
+	gmail.Attachment
+	SavedTo string `json:"savedTo,omitempty"`
+}
+
+func (o AttachmentOptions) maxBytes() int64 {
+	if o.MaxBytes > 0 {
+		return o.MaxBytes
+	}
+	return DefaultMaxAttachmentBytes
+}
+
+// saveTargets returns the places that attachments can be saved to.
+func (o AttachmentOptions) saveTargets() []string {
+	var targets []string
+	if o.Dir != "" {
+		targets = append(targets, saveLocal)
+	}
+	if o.Vault != nil {
+		targets = append(targets, saveVault)
+	}
+	return targets
+}
+
+func GmailGetAttachmentTool(opts AttachmentOptions) mcp.Tool {
+	toolOpts := []mcp.ToolOption{
+		mcp.WithDescription(fmt.Sprintf("Get an attachment of a Gmail message, like a PDF invoice or a CSV report. "+
+			"Text files are returned as text, other files as base64 data. Attachments over %d bytes are refused.", opts.maxBytes())),
+		mcp.WithString("messageId", mcp.Required(), mcp.Description("The ID of the message.")),
+		mcp.WithString("attachmentId", mcp.Description("The attachmentId of the attachment, as listed by gmail_read.")),
+		mcp.WithString("filename", mcp.Description("The filename of the attachment, used if attachmentId is not given.")),
+	}
+	if targets := opts.saveTargets(); len(targets) > 0 {
+		toolOpts = append(toolOpts, mcp.WithString("saveTo", mcp.Enum(targets...), mcp.Description(
+			"Save the attachment instead of returning its content: \"local\" to the attachments directory, "+
+				"\"vault\" to the attachments folder of the Obsidian vault. Existing files are not overwritten.")))
+	} else {
+		toolOpts = append(toolOpts,
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true))
+	}
+	toolOpts = append(toolOpts, mcp.WithDestructiveHintAnnotation(false))
+	return mcp.NewTool("gmail_get_attachment", toolOpts...)
+}
+
+func GmailGetAttachmentHandler(client gmail.API, opts AttachmentOptions) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return mcp.NewToolResultError("arguments must be a map"), nil
+		}
+		messageID, ok := args["messageId"].(string)
+		if !ok {
+			return mcp.NewToolResultError("messageId argument must be a string"), nil
+		}
+		attachmentID, _ := args["attachmentId"].(string)
+		filename, _ := args["filename"].(string)
+		if attachmentID == "" && filename == "" {
+			return mcp.NewToolResultError("attachmentId or filename is required"), nil
+		}
+		saveTo, _ := args["saveTo"].(string)
+		if saveTo != "" && !slices.Contains(opts.saveTargets(), saveTo) {
+			return mcp.NewToolResultError(fmt.Sprintf("saving to %q is not configured", saveTo)), nil
+		}
+
+		info, data, err := fetchAttachment(client, messageID, attachmentID, filename, opts.maxBytes())
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get attachment: %v", err)), nil
+		}
+
+		switch saveTo {
+		case saveLocal:
+			info.SavedTo, err = saveToDir(opts.Dir, info.Filename, data)
+		case saveVault:
+			info.SavedTo, err = saveToVault(ctx, opts.Vault, opts.VaultFolder, info, data)
+		default:
+			return attachmentResult(info, data)
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to save attachment: %v", err)), nil
+		}
+		return mcp.NewToolResultJSON(info)
+	}
+}
+
+// fetchAttachment finds an attachment of a message by its ID or filename
+// and returns its content, refusing attachments over maxBytes.
+func fetchAttachment(client gmail.API, messageID, attachmentID, filename string, maxBytes int64) (*attachmentInfo, []byte, error) {
+	msg, err := client.GetMessage(messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachments := gmail.NewMessage(msg).Attachments
+
+	info := &attachmentInfo{MessageID: messageID}
+	found := false
+	for _, a := range attachments {
+		if (attachmentID != "" && a.ID == attachmentID) || (attachmentID == "" && strings.EqualFold(a.Filename, filename)) {
+			info.Attachment, found = a, true
+			break
+		}
+	}
+	switch {
+	case found:
+		if info.Size > maxBytes {
+			return nil, nil, fmt.Errorf("%w: %d bytes, the limit is %d", errAttachmentTooLarge, info.Size, maxBytes)
+		}
+	case attachmentID == "":
+		return nil, nil, fmt.Errorf("%w: no attachment named %q", errAttachmentNotFound, filename)
+	default:
+		// Gmail may return other attachment IDs each time a message is
+		// fetched, so an ID from an earlier gmail_read is tried anyway.
+		info.ID = attachmentID
+	}
+
+	data, err := client.GetAttachment(messageID, info.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, nil, fmt.Errorf("%w: %d bytes, the limit is %d", errAttachmentTooLarge, len(data), maxBytes)
+	}
+	if !found {
+		info.Attachment = matchBySize(attachments, int64(len(data)))
+		info.ID = attachmentID
+	}
+	info.Size = int64(len(data))
+	return info, data, nil
+}
+
+// matchBySize returns the attachment of the size if exactly one has it, or
+// else a generic description.
+func matchBySize(attachments []gmail.Attachment, size int64) gmail.Attachment {
+	var match gmail.Attachment
+	n := 0
+	for _, a := range attachments {
+		if a.Size == size {
+			match = a
+			n++
+		}
+	}
+	if n != 1 {
+		return gmail.Attachment{Filename: "attachment", MimeType: "application/octet-stream"}
+	}
+	return match
+}
+
+// attachmentResult returns the description of an attachment followed by
+// its content as an embedded resource: text for text files and base64
+// data for others.
+func attachmentResult(info *attachmentInfo, data []byte) (*mcp.CallToolResult, error) {
+	result, err := mcp.NewToolResultJSON(info)
+	if err != nil {
+		return nil, err
+	}
+	uri := fmt.Sprintf("gmail-attachment:///%s/%s", url.PathEscape(info.MessageID), url.PathEscape(info.Filename))
+	var resource mcp.ResourceContents
+	if isText(info.MimeType, info.Filename) && utf8.Valid(data) {
+		resource = mcp.TextResourceContents{URI: uri, MIMEType: info.MimeType, Text: string(data)}
+	} else {
+		resource = mcp.BlobResourceContents{URI: uri, MIMEType: info.MimeType, Blob: base64.StdEncoding.EncodeToString(data)}
+	}
+	result.Content = append(result.Content, mcp.NewEmbeddedResource(resource))
+	return result, nil
+}
+
+// isText reports whether a file of the type and name holds text.
+func isText(mimeType, filename string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		mediaType = strings.ToLower(mimeType)
+	}
+	if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml",
+		"application/csv", "application/javascript", "application/x-sh", "message/rfc822":
+		return true
+	}
+	// Text files are often sent as application/octet-stream.
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".txt", ".csv", ".tsv", ".md", ".json", ".xml", ".yaml", ".yml", ".log", ".ics", ".vcf":
+		return true
+	}
+	return false
+}
+
+// saveToDir writes data to a new file in dir and returns its path. A
+// number is added to the name if a file with the name exists.
+func saveToDir(dir, filename string, data []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	stem, ext := splitFilename(filename)
+	name := stem + ext
+	for i := 1; ; i++ {
+		p := filepath.Join(dir, name)
+		f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			name = fmt.Sprintf("%s %d%s", stem, i, ext)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return "", err
+		}
+		return p, f.Close()
+	}
+}
+
+// saveToVault uploads data to a new file in the folder of the vault and
+// returns its vault path.
+func saveToVault(ctx context.Context, vault VaultWriter, folder string, info *attachmentInfo, data []byte) (string, error) {
+	stem, ext := splitFilename(info.Filename)
+	p, err := vault.AvailablePath(ctx, path.Join(folder, stem+ext))
+	if err != nil {
+		return "", err
+	}
+	contentType := info.MimeType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return p, vault.Upload(ctx, p, data, contentType)
+}
+
+// splitFilename returns a safe name for a file from a message and its
+// extension.
+func splitFilename(filename string) (string, string) {
+	ext := path.Ext(filename)
+	stem := obsidian.SanitizeFilename(strings.TrimSuffix(filename, ext))
+	if stem == "" {
+		stem = "attachment"
+	}
+	if ext = obsidian.SanitizeFilename(ext); ext != "" {
+		ext = "." + ext
+	}
+	return stem, ext
+}
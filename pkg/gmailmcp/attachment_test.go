@@ -0,0 +1,173 @@
+package gmailmcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/mcptest"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/gmail/v1"
+)
+
+const (
+	reportCSV  = "month,total\nMarch,42\n"
+	invoicePDF = "%PDF-1.7\x00\xff"
+)
+
+func attachmentClient() *MockGmailClient {
+	return &MockGmailClient{
+		GetMessageFunc: func(id string) (*gmail.Message, error) {
+			if id != "m1" {
+				return nil, errMessageNotFound
+			}
+			return &gmail.Message{Id: "m1", Payload: &gmail.MessagePart{
+				MimeType: "multipart/mixed",
+				Parts: []*gmail.MessagePart{
+					{MimeType: "text/plain", Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte("See attached."))}},
+					{MimeType: "text/csv", Filename: "report.csv", Body: &gmail.MessagePartBody{AttachmentId: "a-csv", Size: int64(len(reportCSV))}},
+					{MimeType: "application/pdf", Filename: "invoice.pdf", Body: &gmail.MessagePartBody{AttachmentId: "a-pdf", Size: int64(len(invoicePDF))}},
+				},
+			}}, nil
+		},
+		GetAttachmentFunc: func(_, attachmentID string) ([]byte, error) {
+			switch attachmentID {
+			case "a-csv":
+				return []byte(reportCSV), nil
+			case "a-pdf", "a-pdf-old":
+				return []byte(invoicePDF), nil
+			}
+			return nil, errMessageNotFound
+		},
+	}
+}
+
+func callGetAttachment(t *testing.T, opts AttachmentOptions, args map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+	srv, err := mcptest.NewServer(t, server.ServerTool{
+		Tool:    GmailGetAttachmentTool(opts),
+		Handler: GmailGetAttachmentHandler(attachmentClient(), opts),
+	})
+	require.NoError(t, err)
+	defer srv.Close()
+
+	res, err := srv.Client().CallTool(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "gmail_get_attachment", Arguments: args},
+	})
+	require.NoError(t, err)
+	return res
+}
+
+func attachmentInfoOf(t *testing.T, res *mcp.CallToolResult) map[string]interface{} {
+	t.Helper()
+	require.False(t, res.IsError, "Tool result should not be an error")
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	var info map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &info))
+	return info
+}
+
+func TestGmailGetAttachment_Text(t *testing.T) {
+	res := callGetAttachment(t, AttachmentOptions{}, map[string]interface{}{"messageId": "m1", "filename": "REPORT.csv"})
+	info := attachmentInfoOf(t, res)
+	assert.Equal(t, "report.csv", info["filename"])
+	assert.Equal(t, "a-csv", info["attachmentId"])
+
+	require.Len(t, res.Content, 2)
+	resource, ok := res.Content[1].(mcp.EmbeddedResource)
+	require.True(t, ok)
+	text, ok := resource.Resource.(mcp.TextResourceContents)
+	require.True(t, ok)
+	assert.Equal(t, reportCSV, text.Text)
+	assert.Equal(t, "text/csv", text.MIMEType)
+	assert.Equal(t, "gmail-attachment:///m1/report.csv", text.URI)
+}
+
+func TestGmailGetAttachment_Blob(t *testing.T) {
+	// An ID from an earlier fetch of the message is matched by size.
+	res := callGetAttachment(t, AttachmentOptions{}, map[string]interface{}{"messageId": "m1", "attachmentId": "a-pdf-old"})
+	info := attachmentInfoOf(t, res)
+	assert.Equal(t, "invoice.pdf", info["filename"])
+	assert.Equal(t, "a-pdf-old", info["attachmentId"])
+
+	require.Len(t, res.Content, 2)
+	resource, ok := res.Content[1].(mcp.EmbeddedResource)
+	require.True(t, ok)
+	blob, ok := resource.Resource.(mcp.BlobResourceContents)
+	require.True(t, ok)
+	assert.Equal(t, "application/pdf", blob.MIMEType)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(invoicePDF)), blob.Blob)
+}
+
+func TestGmailGetAttachment_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts AttachmentOptions
+		args map[string]interface{}
+	}{
+		{name: "too large", opts: AttachmentOptions{MaxBytes: 5}, args: map[string]interface{}{"messageId": "m1", "attachmentId": "a-csv"}},
+		{name: "unknown filename", args: map[string]interface{}{"messageId": "m1", "filename": "missing.txt"}},
+		{name: "no attachment", args: map[string]interface{}{"messageId": "m1"}},
+		{name: "save not configured", args: map[string]interface{}{"messageId": "m1", "attachmentId": "a-csv", "saveTo": "local"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := callGetAttachment(t, tt.opts, tt.args)
+			assert.True(t, res.IsError)
+		})
+	}
+}
+
+func TestGmailGetAttachment_SaveLocal(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invoice.pdf"), []byte("old"), 0o600))
+
+	res := callGetAttachment(t, AttachmentOptions{Dir: dir},
+		map[string]interface{}{"messageId": "m1", "attachmentId": "a-pdf", "saveTo": "local"})
+	info := attachmentInfoOf(t, res)
+	assert.Len(t, res.Content, 1)
+	assert.Equal(t, filepath.Join(dir, "invoice 1.pdf"), info["savedTo"])
+
+	data, err := os.ReadFile(filepath.Join(dir, "invoice 1.pdf"))
+	require.NoError(t, err)
+	assert.Equal(t, invoicePDF, string(data))
+}
+
+type mockVault struct {
+	path, contentType string
+	data              []byte
+}
+
+func (v *mockVault) AvailablePath(_ context.Context, filePath string) (string, error) {
+	return filePath, nil
+}
+
+func (v *mockVault) Upload(_ context.Context, path string, data []byte, contentType string) error {
+	v.path, v.data, v.contentType = path, data, contentType
+	return nil
+}
+
+func TestGmailGetAttachment_SaveVault(t *testing.T) {
+	vault := &mockVault{}
+	res := callGetAttachment(t, AttachmentOptions{Vault: vault, VaultFolder: "Attachments"},
+		map[string]interface{}{"messageId": "m1", "filename": "invoice.pdf", "saveTo": "vault"})
+	info := attachmentInfoOf(t, res)
+	assert.Equal(t, "Attachments/invoice.pdf", info["savedTo"])
+	assert.Equal(t, "Attachments/invoice.pdf", vault.path)
+	assert.Equal(t, "application/pdf", vault.contentType)
+	assert.Equal(t, invoicePDF, string(vault.data))
+}
+
+func TestIsText(t *testing.T) {
+	assert.True(t, isText("text/csv; charset=utf-8", "report.csv"))
+	assert.True(t, isText("application/vnd.api+json", "data"))
+	assert.True(t, isText("application/octet-stream", "notes.TXT"))
+	assert.False(t, isText("application/pdf", "invoice.pdf"))
+}
@@ -0,0 +1,74 @@
+package gmailmcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bttk/bttk-mcp/pkg/gmail"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func GmailChangesSinceLastCheckTool() mcp.Tool {
+	return mcp.NewTool("gmail_changes_since_last_check",
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithDescription("List what changed in the Gmail inbox since the previous call of this tool: "+
+			"new messages (like gmail_search results, newest first), deleted messages and changed labels. "+
+			"The first call returns the messages of the last day. Each call starts a new period, so results are not repeated. "+
+			"When fullResync is true, the history was not available and only new messages are listed."),
+	)
+}
+
+func GmailChangesSinceLastCheckHandler(syncer *gmail.Syncer) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		changes, err := syncer.Changes()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list changes: %v", err)), nil
+		}
+
+		names := labelNames(syncer.Client)
+		hits := make([]gmail.SearchHit, 0, len(changes.Messages))
+		for _, m := range changes.Messages {
+			hits = append(hits, gmail.NewSearchHit(m, names, changes.ThreadSizes[m.ThreadId]))
+		}
+		labelChanges := make([]gmail.LabelChange, 0, len(changes.LabelChanges))
+		for _, c := range changes.LabelChanges {
+			labelChanges = append(labelChanges, gmail.LabelChange{
+				ID:      c.ID,
+				Added:   labelNameList(c.Added, names),
+				Removed: labelNameList(c.Removed, names),
+			})
+		}
+
+		resp := map[string]interface{}{
+			"messages":     hits,
+			"count":        len(hits),
+			"deleted":      nonNil(changes.Deleted),
+			"labelChanges": labelChanges,
+			"fullResync":   changes.FullResync,
+		}
+		if !changes.Since.IsZero() {
+			resp["since"] = changes.Since.Format(time.RFC3339)
+		}
+		if changes.More {
+			resp["more"] = true
+		}
+		if len(changes.Errors) > 0 {
+			resp["errors"] = changes.Errors
+		}
+		return mcp.NewToolResultJSON(resp)
+	}
+}
+
+// labelNameList replaces label IDs with their names.
+func labelNameList(ids []string, names map[string]string) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := names[id]; ok {
+			id = name
+		}
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package gmailmcp
+
+import (
+	"path/filepath"
+	"testing"
+
+	pkggmail "github.com/bttk/bttk-mcp/pkg/gmail"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestGmailChangesSinceLastCheck(t *testing.T) {
+	client := &MockGmailClient{
+		GetProfileFunc: func() (*gmail.Profile, error) {
+			return &gmail.Profile{HistoryId: 100}, nil
+		},
+		SearchMessagesFunc: func(_, _ string, _ int64) (*pkggmail.SearchResult, error) {
+			return &pkggmail.SearchResult{Messages: []*gmail.Message{{Id: "m1", ThreadId: "t1", LabelIds: []string{"INBOX"}}}}, nil
+		},
+		ListHistoryFunc: func(startHistoryID uint64, _, _ string) (*gmail.ListHistoryResponse, error) {
+			assert.Equal(t, uint64(100), startHistoryID)
+			return &gmail.ListHistoryResponse{HistoryId: 110, History: []*gmail.History{
+				{MessagesAdded: []*gmail.HistoryMessageAdded{{Message: &gmail.Message{Id: "m2"}}}},
+				{LabelsAdded: []*gmail.HistoryLabelAdded{{Message: &gmail.Message{Id: "m1"}, LabelIds: []string{"Label_1"}}}},
+				{MessagesDeleted: []*gmail.HistoryMessageDeleted{{Message: &gmail.Message{Id: "m0"}}}},
+			}}, nil
+		},
+		GetMetadataFunc: func(ids []string) *pkggmail.SearchResult {
+			assert.Equal(t, []string{"m2"}, ids)
+			return &pkggmail.SearchResult{
+				Messages:    []*gmail.Message{{Id: "m2", ThreadId: "t2"}},
+				ThreadSizes: map[string]int{"t2": 3},
+			}
+		},
+		ListLabelsFunc: func() ([]*gmail.Label, error) {
+			return []*gmail.Label{{Id: "INBOX", Name: "INBOX"}, {Id: "Label_1", Name: "Work"}}, nil
+		},
+	}
+	syncer := pkggmail.NewSyncer(client, filepath.Join(t.TempDir(), "sync.json"))
+	tool := server.ServerTool{Tool: GmailChangesSinceLastCheckTool(), Handler: GmailChangesSinceLastCheckHandler(syncer)}
+
+	_, resp := callTriageTool(t, tool, map[string]interface{}{})
+	assert.Equal(t, true, resp["fullResync"])
+	assert.NotContains(t, resp, "since")
+	messages, ok := resp["messages"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, messages, 1)
+	hit, ok := messages[0].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "m1", hit["id"])
+
+	_, resp = callTriageTool(t, tool, map[string]interface{}{})
+	assert.Equal(t, false, resp["fullResync"])
+	assert.Contains(t, resp, "since")
+	messages, ok = resp["messages"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, messages, 1)
+	hit, ok = messages[0].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "m2", hit["id"])
+	assert.InDelta(t, 3, hit["threadSize"], 0)
+	assert.Equal(t, []interface{}{"m0"}, resp["deleted"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "m1", "added": []interface{}{"Work"}}}, resp["labelChanges"])
+}
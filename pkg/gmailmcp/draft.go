@@ -0,0 +1,128 @@
+package gmailmcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bttk/bttk-mcp/pkg/gmail"
+	"github.com/mark3labs/mcp-go/mcp"
+	gmailv1 "google.golang.org/api/gmail/v1"
+)
+
+// draftResult describes a saved draft.
+type draftResult struct {
+	DraftID   string `json:"draftId"`
+	MessageID string `json:"messageId"`
+	ThreadID  string `json:"threadId"`
+	To        string `json:"to,omitempty"`
+	Cc        string `json:"cc,omitempty"`
+	Bcc       string `json:"bcc,omitempty"`
+	Subject   string `json:"subject"`
+}
+
+func GmailCreateDraftTool() mcp.Tool {
+	return mcp.NewTool("gmail_create_draft",
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithDescription("Save a new Gmail message as a draft, or replace the content of a draft. "+
+			"The draft is never sent: the user reviews and sends it in Gmail."),
+		mcp.WithString("to", mcp.Description("Recipients, like \"Jane Doe <jane@example.com>, bob@example.com\".")),
+		mcp.WithString("cc", mcp.Description("Carbon copy recipients.")),
+		mcp.WithString("bcc", mcp.Description("Blind carbon copy recipients.")),
+		mcp.WithString("subject", mcp.Description("The subject.")),
+		mcp.WithString("body", mcp.Description("The plain text body.")),
+		mcp.WithString("html", mcp.Description("The HTML body. A draft with body and html has both versions.")),
+		mcp.WithString("draftId", mcp.Description("The ID of a draft to replace instead of creating a new one.")),
+	)
+}
+
+func GmailCreateDraftHandler(client gmail.API) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return mcp.NewToolResultError("arguments must be a map"), nil
+		}
+		draftID, _ := args["draftId"].(string)
+		return saveDraft(client, draftID, composeArgs(args))
+	}
+}
+
+func GmailReplyDraftTool() mcp.Tool {
+	return mcp.NewTool("gmail_reply_draft",
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithDescription("Save a reply to a Gmail message as a draft in its thread, addressed to the sender "+
+			"(or to all recipients with replyAll). The draft is never sent: the user reviews and sends it in Gmail."),
+		mcp.WithString("messageId", mcp.Required(), mcp.Description("The ID of the message to reply to.")),
+		mcp.WithString("body", mcp.Description("The plain text body of the reply.")),
+		mcp.WithString("html", mcp.Description("The HTML body of the reply. A reply with body and html has both versions.")),
+		mcp.WithBoolean("replyAll", mcp.Description("Copy the other recipients of the message (default false).")),
+		mcp.WithString("draftId", mcp.Description("The ID of a draft to replace instead of creating a new one.")),
+	)
+}
+
+func GmailReplyDraftHandler(client gmail.API) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return mcp.NewToolResultError("arguments must be a map"), nil
+		}
+		id, ok := args["messageId"].(string)
+		if !ok {
+			return mcp.NewToolResultError("messageId argument must be a string"), nil
+		}
+		replyAll, _ := args["replyAll"].(bool)
+		draftID, _ := args["draftId"].(string)
+
+		original, err := client.GetMessage(id)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get message: %v", err)), nil
+		}
+		msg, err := gmail.NewReply(original, replyAll)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to reply: %v", err)), nil
+		}
+		msg.Text, _ = args["body"].(string)
+		msg.HTML, _ = args["html"].(string)
+		return saveDraft(client, draftID, msg)
+	}
+}
+
+// composeArgs returns the message described by the arguments of a tool.
+func composeArgs(args map[string]interface{}) *gmail.Compose {
+	msg := &gmail.Compose{}
+	msg.To, _ = args["to"].(string)
+	msg.Cc, _ = args["cc"].(string)
+	msg.Bcc, _ = args["bcc"].(string)
+	msg.Subject, _ = args["subject"].(string)
+	msg.Text, _ = args["body"].(string)
+	msg.HTML, _ = args["html"].(string)
+	return msg
+}
+
+// saveDraft creates a draft, or updates it if draftID is set.
+func saveDraft(client gmail.API, draftID string, msg *gmail.Compose) (*mcp.CallToolResult, error) {
+	if msg.Text == "" && msg.HTML == "" {
+		return mcp.NewToolResultError("body or html is required"), nil
+	}
+	if _, err := msg.MIME(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var (
+		draft *gmailv1.Draft
+		err   error
+	)
+	if draftID != "" {
+		draft, err = client.UpdateDraft(draftID, msg)
+	} else {
+		draft, err = client.CreateDraft(msg)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to save draft: %v", err)), nil
+	}
+
+	result := draftResult{DraftID: draft.Id, To: msg.To, Cc: msg.Cc, Bcc: msg.Bcc, Subject: msg.Subject}
+	if draft.Message != nil {
+		result.MessageID, result.ThreadID = draft.Message.Id, draft.Message.ThreadId
+	}
+	return mcp.NewToolResultJSON(result)
+}
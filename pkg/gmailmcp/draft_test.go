@@ -0,0 +1,112 @@
+package gmailmcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	pkggmail "github.com/bttk/bttk-mcp/pkg/gmail"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/mcptest"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/gmail/v1"
+)
+
+// draftClient records the drafts that are saved.
+func draftClient(saved *[]*pkggmail.Compose, updated *string) *MockGmailClient {
+	return &MockGmailClient{
+		GetMessageFunc: func(id string) (*gmail.Message, error) {
+			if id != "m1" {
+				return nil, errMessageNotFound
+			}
+			return &gmail.Message{Id: "m1", ThreadId: "t1", Payload: &gmail.MessagePart{Headers: []*gmail.MessagePartHeader{
+				{Name: "From", Value: "jane@example.com"},
+				{Name: "To", Value: "me@example.com, bob@example.com"},
+				{Name: "Delivered-To", Value: "me@example.com"},
+				{Name: "Subject", Value: "Launch"},
+				{Name: "Message-ID", Value: "<m1@example.com>"},
+			}}}, nil
+		},
+		CreateDraftFunc: func(msg *pkggmail.Compose) (*gmail.Draft, error) {
+			*saved = append(*saved, msg)
+			return &gmail.Draft{Id: "d1", Message: &gmail.Message{Id: "m2", ThreadId: msg.ThreadID}}, nil
+		},
+		UpdateDraftFunc: func(id string, msg *pkggmail.Compose) (*gmail.Draft, error) {
+			*saved = append(*saved, msg)
+			*updated = id
+			return &gmail.Draft{Id: id, Message: &gmail.Message{Id: "m3", ThreadId: msg.ThreadID}}, nil
+		},
+	}
+}
+
+func callDraftTool(t *testing.T, tool server.ServerTool, args map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+	srv, err := mcptest.NewServer(t, tool)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	res, err := srv.Client().CallTool(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: tool.Tool.Name, Arguments: args},
+	})
+	require.NoError(t, err)
+	return res
+}
+
+func TestGmailCreateDraft(t *testing.T) {
+	var saved []*pkggmail.Compose
+	var updated string
+	client := draftClient(&saved, &updated)
+	tool := server.ServerTool{Tool: GmailCreateDraftTool(), Handler: GmailCreateDraftHandler(client)}
+
+	res := callDraftTool(t, tool, map[string]interface{}{
+		"to": "jane@example.com", "subject": "Hello", "body": "Hi Jane", "html": "<p>Hi Jane</p>",
+	})
+	require.False(t, res.IsError, "Tool result should not be an error")
+	require.Len(t, saved, 1)
+	assert.Equal(t, &pkggmail.Compose{To: "jane@example.com", Subject: "Hello", Text: "Hi Jane", HTML: "<p>Hi Jane</p>"}, saved[0])
+
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &resp))
+	assert.Equal(t, "d1", resp["draftId"])
+	assert.Equal(t, "m2", resp["messageId"])
+
+	res = callDraftTool(t, tool, map[string]interface{}{"draftId": "d1", "to": "jane@example.com", "body": "Hi again"})
+	require.False(t, res.IsError, "Tool result should not be an error")
+	assert.Equal(t, "d1", updated)
+
+	for _, args := range []map[string]interface{}{
+		{"to": "jane@example.com"},
+		{"to": "jane@example.com", "subject": "Hi\nBcc: eve@example.com", "body": "Hi"},
+	} {
+		res = callDraftTool(t, tool, args)
+		assert.True(t, res.IsError)
+	}
+	assert.Len(t, saved, 2)
+}
+
+func TestGmailReplyDraft(t *testing.T) {
+	var saved []*pkggmail.Compose
+	var updated string
+	client := draftClient(&saved, &updated)
+	tool := server.ServerTool{Tool: GmailReplyDraftTool(), Handler: GmailReplyDraftHandler(client)}
+
+	res := callDraftTool(t, tool, map[string]interface{}{"messageId": "m1", "body": "Sounds good.", "replyAll": true})
+	require.False(t, res.IsError, "Tool result should not be an error")
+	require.Len(t, saved, 1)
+	assert.Equal(t, &pkggmail.Compose{
+		To:         "<jane@example.com>",
+		Cc:         "<bob@example.com>",
+		Subject:    "Re: Launch",
+		Text:       "Sounds good.",
+		InReplyTo:  "<m1@example.com>",
+		References: "<m1@example.com>",
+		ThreadID:   "t1",
+	}, saved[0])
+
+	res = callDraftTool(t, tool, map[string]interface{}{"messageId": "missing", "body": "Hi"})
+	assert.True(t, res.IsError)
+}
@@ -0,0 +1,67 @@
+package gmailmcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bttk/bttk-mcp/pkg/gmail"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func GmailFindTool() mcp.Tool {
+	return mcp.NewTool("gmail_find",
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("Search for Gmail messages by fields instead of a query string. All given fields must match. "+
+			"Returns the same results as gmail_search, and the query that the fields compile to."),
+		mcp.WithString("from", mcp.Description("Sender name or address.")),
+		mcp.WithString("to", mcp.Description("Recipient name or address.")),
+		mcp.WithString("subject", mcp.Description("Words or a phrase of the subject.")),
+		mcp.WithString("label", mcp.Description("Label name, like \"Work/Projects\".")),
+		mcp.WithBoolean("hasAttachment", mcp.Description("true for messages with attachments, false for messages without.")),
+		mcp.WithBoolean("unread", mcp.Description("true for unread messages, false for read messages.")),
+		mcp.WithString("largerThan", mcp.Description("Minimum size, like \"500K\" or \"5M\".")),
+		mcp.WithString("after", mcp.Description("Messages after this date: \"2024-03-01\", \"yesterday\", \"last 7 days\", \"2 weeks ago\".")),
+		mcp.WithString("before", mcp.Description("Messages before this date, in the same formats as after.")),
+		mcp.WithString("text", mcp.Description("Free text searched in the whole message.")),
+		mcp.WithNumber("maxResults", mcp.Description("Maximum number of results to return per page (default 50).")),
+		mcp.WithString("pageToken", mcp.Description("The nextPageToken of the previous page.")),
+	)
+}
+
+func GmailFindHandler(client gmail.API) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return mcp.NewToolResultError("arguments must be a map"), nil
+		}
+		q := &gmail.Query{}
+		q.From, _ = args["from"].(string)
+		q.To, _ = args["to"].(string)
+		q.Subject, _ = args["subject"].(string)
+		q.Label, _ = args["label"].(string)
+		q.LargerThan, _ = args["largerThan"].(string)
+		q.After, _ = args["after"].(string)
+		q.Before, _ = args["before"].(string)
+		q.Text, _ = args["text"].(string)
+		if v, ok := args["hasAttachment"].(bool); ok {
+			q.HasAttachment = &v
+		}
+		if v, ok := args["unread"].(bool); ok {
+			q.Unread = &v
+		}
+
+		query, err := q.Compile(time.Now())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		resp, err := search(client, query, args)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to search messages for %q: %v", query, err)), nil
+		}
+		resp["query"] = query
+		return mcp.NewToolResultJSON(resp)
+	}
+}
@@ -0,0 +1,50 @@
+package gmailmcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	pkggmail "github.com/bttk/bttk-mcp/pkg/gmail"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/mcptest"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestGmailFind(t *testing.T) {
+	var queries []string
+	mockClient := &MockGmailClient{
+		SearchMessagesFunc: func(query, _ string, _ int64) (*pkggmail.SearchResult, error) {
+			queries = append(queries, query)
+			return &pkggmail.SearchResult{Messages: []*gmail.Message{{Id: "m1", ThreadId: "t1"}}}, nil
+		},
+	}
+	srv, err := mcptest.NewServer(t, server.ServerTool{Tool: GmailFindTool(), Handler: GmailFindHandler(mockClient)})
+	require.NoError(t, err)
+	defer srv.Close()
+
+	res, err := srv.Client().CallTool(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "gmail_find", Arguments: map[string]interface{}{
+			"from": "Jane Doe", "hasAttachment": true, "unread": false, "label": "Invoices",
+		}},
+	})
+	require.NoError(t, err)
+	require.False(t, res.IsError, "Tool result should not be an error")
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &resp))
+	assert.Equal(t, `from:"Jane Doe" label:Invoices has:attachment is:read`, resp["query"])
+	assert.InDelta(t, 1, resp["count"], 0)
+	assert.Equal(t, []string{`from:"Jane Doe" label:Invoices has:attachment is:read`}, queries)
+
+	res, err = srv.Client().CallTool(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "gmail_find", Arguments: map[string]interface{}{"after": "next week"}},
+	})
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+	assert.Len(t, queries, 1)
+}
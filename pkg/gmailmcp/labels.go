@@ -0,0 +1,284 @@
+package gmailmcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bttk/bttk-mcp/internal/toolargs"
+	"github.com/bttk/bttk-mcp/pkg/gmail"
+	"github.com/mark3labs/mcp-go/mcp"
+	gmailv1 "google.golang.org/api/gmail/v1"
+)
+
+// System labels that the triage tools change.
+const (
+	labelInbox   = "INBOX"
+	labelUnread  = "UNREAD"
+	labelStarred = "STARRED"
+)
+
+var (
+	errUnknownLabel   = errors.New("unknown label")
+	errProtectedLabel = errors.New("label is protected")
+)
+
+// labelInfo describes a label of the mailbox.
+type labelInfo struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Protected bool   `json:"protected,omitempty"`
+}
+
+// skippedMessage is a message that was not changed because it has a
+// protected label, or because its labels could not be read.
+type skippedMessage struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// triageResult lists the messages that a triage tool changed and skipped.
+type triageResult struct {
+	Changed []string         `json:"changed"`
+	Skipped []skippedMessage `json:"skipped,omitempty"`
+}
+
+// labelIndex finds labels by their name or ID and knows which are
+// protected.
+type labelIndex struct {
+	labels    []*gmailv1.Label
+	protected map[string]bool
+}
+
+// newLabelIndex lists the labels of the mailbox. Every protected label must
+// exist.
+func newLabelIndex(client gmail.API, protected []string) (*labelIndex, error) {
+	labels, err := client.ListLabels()
+	if err != nil {
+		return nil, err
+	}
+	idx := &labelIndex{labels: labels, protected: map[string]bool{}}
+	for _, p := range protected {
+		label := idx.find(p)
+		if label == nil {
+			// A misspelled or renamed label would turn its protection off.
+			return nil, fmt.Errorf("%w: protected label %s", errUnknownLabel, p)
+		}
+		idx.protected[label.Id] = true
+	}
+	return idx, nil
+}
+
+// find returns the label with the ID or name, ignoring case.
+func (x *labelIndex) find(nameOrID string) *gmailv1.Label {
+	for _, label := range x.labels {
+		if label.Id == nameOrID || strings.EqualFold(label.Name, nameOrID) {
+			return label
+		}
+	}
+	return nil
+}
+
+// ids returns the IDs of labels given by name or ID. Protected labels are
+// refused.
+func (x *labelIndex) ids(namesOrIDs []string) ([]string, error) {
+	ids := make([]string, 0, len(namesOrIDs))
+	for _, n := range namesOrIDs {
+		label := x.find(n)
+		if label == nil {
+			return nil, fmt.Errorf("%w: %s", errUnknownLabel, n)
+		}
+		if x.protected[label.Id] {
+			return nil, fmt.Errorf("%w: %s", errProtectedLabel, label.Name)
+		}
+		ids = append(ids, label.Id)
+	}
+	return ids, nil
+}
+
+// unprotected splits messages into those without protected labels and
+// those that must be skipped. The labels of the messages are read
+// concurrently; messages that cannot be read are skipped.
+func (x *labelIndex) unprotected(client gmail.API, messageIDs []string) ([]string, []skippedMessage) {
+	if len(x.protected) == 0 {
+		return messageIDs, nil
+	}
+	result := client.GetMetadata(messageIDs)
+	var skipped []skippedMessage
+	for _, e := range result.Errors {
+		skipped = append(skipped, skippedMessage{ID: e.ID, Error: e.Error})
+	}
+	ids := make([]string, 0, len(result.Messages))
+	for _, msg := range result.Messages {
+		protected := ""
+		for _, l := range msg.LabelIds {
+			if x.protected[l] {
+				protected = x.find(l).Name
+				break
+			}
+		}
+		if protected != "" {
+			skipped = append(skipped, skippedMessage{ID: msg.Id, Label: protected})
+		} else {
+			ids = append(ids, msg.Id)
+		}
+	}
+	return ids, skipped
+}
+
+// modify changes the labels of the messages that have no protected label.
+func (x *labelIndex) modify(client gmail.API, messageIDs, add, remove []string) (*triageResult, error) {
+	addIDs, err := x.ids(add)
+	if err != nil {
+		return nil, err
+	}
+	removeIDs, err := x.ids(remove)
+	if err != nil {
+		return nil, err
+	}
+	ids, skipped := x.unprotected(client, messageIDs)
+	if len(ids) > 0 {
+		if err := client.ModifyLabels(ids, addIDs, removeIDs); err != nil {
+			return nil, err
+		}
+	}
+	return &triageResult{Changed: nonNil(ids), Skipped: skipped}, nil
+}
+
+func GmailListLabelsTool() mcp.Tool {
+	return mcp.NewTool("gmail_list_labels",
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("List the Gmail labels: system labels like INBOX or STARRED and user labels. "+
+			"Protected labels cannot be added or removed, and messages with them are not changed."),
+	)
+}
+
+func GmailListLabelsHandler(client gmail.API, protected []string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		idx, err := newLabelIndex(client, protected)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list labels: %v", err)), nil
+		}
+		labels := make([]labelInfo, 0, len(idx.labels))
+		for _, l := range idx.labels {
+			labels = append(labels, labelInfo{ID: l.Id, Name: l.Name, Type: l.Type, Protected: idx.protected[l.Id]})
+		}
+		return mcp.NewToolResultJSON(map[string]interface{}{"labels": labels})
+	}
+}
+
+func GmailModifyLabelsTool() mcp.Tool {
+	return mcp.NewTool("gmail_modify_labels",
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("Change the labels of Gmail messages: add or remove labels, mark them read or unread, "+
+			"star them, or archive them. Messages with a protected label are skipped."),
+		mcp.WithArray("messageIds", mcp.Required(), mcp.Description("The IDs of the messages."), mcp.WithStringItems()),
+		mcp.WithArray("addLabels", mcp.Description("Names or IDs of labels to add."), mcp.WithStringItems()),
+		mcp.WithArray("removeLabels", mcp.Description("Names or IDs of labels to remove."), mcp.WithStringItems()),
+		mcp.WithBoolean("read", mcp.Description("true marks the messages as read, false as unread.")),
+		mcp.WithBoolean("starred", mcp.Description("true stars the messages, false removes the star.")),
+		mcp.WithBoolean("archive", mcp.Description("true archives the messages, false moves them back to the inbox.")),
+	)
+}
+
+func GmailModifyLabelsHandler(client gmail.API, protected []string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return mcp.NewToolResultError("arguments must be a map"), nil
+		}
+		messageIDs := toolargs.StringSlice(args["messageIds"])
+		if len(messageIDs) == 0 {
+			return mcp.NewToolResultError("messageIds must list at least one message"), nil
+		}
+		add := toolargs.StringSlice(args["addLabels"])
+		remove := toolargs.StringSlice(args["removeLabels"])
+		// Each flag adds its label when set to one value and removes it
+		// when set to the other.
+		for _, flag := range []struct {
+			name, label string
+			addIf       bool
+		}{{"read", labelUnread, false}, {"starred", labelStarred, true}, {"archive", labelInbox, false}} {
+			if v, ok := args[flag.name].(bool); ok {
+				if v == flag.addIf {
+					add = append(add, flag.label)
+				} else {
+					remove = append(remove, flag.label)
+				}
+			}
+		}
+		if len(add) == 0 && len(remove) == 0 {
+			return mcp.NewToolResultError("nothing to change: set addLabels, removeLabels, read, starred or archive"), nil
+		}
+
+		idx, err := newLabelIndex(client, protected)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list labels: %v", err)), nil
+		}
+		result, err := idx.modify(client, messageIDs, add, remove)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to modify labels: %v", err)), nil
+		}
+		return mcp.NewToolResultJSON(result)
+	}
+}
+
+func GmailArchiveTool() mcp.Tool {
+	return mcp.NewTool("gmail_archive",
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("Archive Gmail messages (remove them from the inbox), or move them to the trash, "+
+			"where Gmail deletes them after 30 days. Messages with a protected label are skipped."),
+		mcp.WithArray("messageIds", mcp.Required(), mcp.Description("The IDs of the messages."), mcp.WithStringItems()),
+		mcp.WithBoolean("trash", mcp.Description("Move the messages to the trash instead of archiving them (default false).")),
+	)
+}
+
+func GmailArchiveHandler(client gmail.API, protected []string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return mcp.NewToolResultError("arguments must be a map"), nil
+		}
+		messageIDs := toolargs.StringSlice(args["messageIds"])
+		if len(messageIDs) == 0 {
+			return mcp.NewToolResultError("messageIds must list at least one message"), nil
+		}
+		trash, _ := args["trash"].(bool)
+
+		idx, err := newLabelIndex(client, protected)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list labels: %v", err)), nil
+		}
+		if !trash {
+			result, err := idx.modify(client, messageIDs, nil, []string{labelInbox})
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to archive: %v", err)), nil
+			}
+			return mcp.NewToolResultJSON(result)
+		}
+
+		ids, skipped := idx.unprotected(client, messageIDs)
+		result := &triageResult{Changed: []string{}, Skipped: skipped}
+		for _, id := range ids {
+			if err := client.TrashMessage(id); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to trash %s after trashing %d messages: %v", id, len(result.Changed), err)), nil
+			}
+			result.Changed = append(result.Changed, id)
+		}
+		return mcp.NewToolResultJSON(result)
+	}
+}
+
+func nonNil(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
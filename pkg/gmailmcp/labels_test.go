@@ -0,0 +1,151 @@
+package gmailmcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	pkggmail "github.com/bttk/bttk-mcp/pkg/gmail"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/mcptest"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/gmail/v1"
+)
+
+// modifyCall records a call of ModifyLabels.
+type modifyCall struct {
+	ids, add, remove []string
+}
+
+// triageClient has the messages m1 (a newsletter), m2 and m3 (labeled
+// Legal), and records the changes made to them.
+func triageClient(modified *[]modifyCall, trashed *[]string) *MockGmailClient {
+	labels := map[string][]string{
+		"m1": {"INBOX", "UNREAD", "Label_1"},
+		"m2": {"INBOX"},
+		"m3": {"INBOX", "Label_2"},
+	}
+	return &MockGmailClient{
+		ListLabelsFunc: func() ([]*gmail.Label, error) {
+			return []*gmail.Label{
+				{Id: "INBOX", Name: "INBOX", Type: "system"},
+				{Id: "UNREAD", Name: "UNREAD", Type: "system"},
+				{Id: "STARRED", Name: "STARRED", Type: "system"},
+				{Id: "Label_1", Name: "Newsletters", Type: "user"},
+				{Id: "Label_2", Name: "Legal", Type: "user"},
+			}, nil
+		},
+		GetMetadataFunc: func(ids []string) *pkggmail.SearchResult {
+			result := &pkggmail.SearchResult{}
+			for _, id := range ids {
+				if _, ok := labels[id]; !ok {
+					result.Errors = append(result.Errors, pkggmail.MessageError{ID: id, Error: errMessageNotFound.Error()})
+					continue
+				}
+				result.Messages = append(result.Messages, &gmail.Message{Id: id, LabelIds: labels[id]})
+			}
+			return result
+		},
+		ModifyLabelsFunc: func(ids, add, remove []string) error {
+			*modified = append(*modified, modifyCall{ids: ids, add: add, remove: remove})
+			return nil
+		},
+		TrashMessageFunc: func(id string) error {
+			*trashed = append(*trashed, id)
+			return nil
+		},
+	}
+}
+
+func callTriageTool(t *testing.T, tool server.ServerTool, args map[string]interface{}) (*mcp.CallToolResult, map[string]interface{}) {
+	t.Helper()
+	srv, err := mcptest.NewServer(t, tool)
+	require.NoError(t, err)
+	defer srv.Close()
+
+	res, err := srv.Client().CallTool(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: tool.Tool.Name, Arguments: args},
+	})
+	require.NoError(t, err)
+	if res.IsError {
+		return res, nil
+	}
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &resp))
+	return res, resp
+}
+
+func TestGmailListLabels(t *testing.T) {
+	client := triageClient(nil, nil)
+	_, resp := callTriageTool(t, server.ServerTool{
+		Tool: GmailListLabelsTool(), Handler: GmailListLabelsHandler(client, []string{"legal"}),
+	}, map[string]interface{}{})
+
+	labels, ok := resp["labels"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, labels, 5)
+	assert.Equal(t, map[string]interface{}{"id": "Label_2", "name": "Legal", "type": "user", "protected": true}, labels[4])
+}
+
+func TestGmailUnknownProtectedLabel(t *testing.T) {
+	var modified []modifyCall
+	client := triageClient(&modified, nil)
+	tool := server.ServerTool{Tool: GmailModifyLabelsTool(), Handler: GmailModifyLabelsHandler(client, []string{"Legal", "Lgal"})}
+
+	res, _ := callTriageTool(t, tool, map[string]interface{}{"messageIds": []interface{}{"m1"}, "read": true})
+	require.True(t, res.IsError)
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, "protected label Lgal")
+	assert.Empty(t, modified)
+}
+
+func TestGmailModifyLabels(t *testing.T) {
+	var modified []modifyCall
+	client := triageClient(&modified, nil)
+	tool := server.ServerTool{Tool: GmailModifyLabelsTool(), Handler: GmailModifyLabelsHandler(client, []string{"Legal"})}
+
+	_, resp := callTriageTool(t, tool, map[string]interface{}{
+		"messageIds":   []interface{}{"m1", "m2", "m3", "m9"},
+		"removeLabels": []interface{}{"newsletters"},
+		"read":         true,
+		"starred":      true,
+	})
+	assert.Equal(t, []interface{}{"m1", "m2"}, resp["changed"])
+	// Messages whose labels cannot be read are skipped too.
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "m9", "error": errMessageNotFound.Error()},
+		map[string]interface{}{"id": "m3", "label": "Legal"},
+	}, resp["skipped"])
+	assert.Equal(t, []modifyCall{{ids: []string{"m1", "m2"}, add: []string{"STARRED"}, remove: []string{"Label_1", "UNREAD"}}}, modified)
+
+	for _, args := range []map[string]interface{}{
+		{"messageIds": []interface{}{"m1"}, "addLabels": []interface{}{"Legal"}},
+		{"messageIds": []interface{}{"m1"}, "addLabels": []interface{}{"Unknown"}},
+		{"messageIds": []interface{}{"m1"}},
+		{"messageIds": []interface{}{}, "read": true},
+	} {
+		res, _ := callTriageTool(t, tool, args)
+		assert.True(t, res.IsError)
+	}
+	assert.Len(t, modified, 1)
+}
+
+func TestGmailArchive(t *testing.T) {
+	var modified []modifyCall
+	var trashed []string
+	client := triageClient(&modified, &trashed)
+	tool := server.ServerTool{Tool: GmailArchiveTool(), Handler: GmailArchiveHandler(client, []string{"Label_2"})}
+
+	_, resp := callTriageTool(t, tool, map[string]interface{}{"messageIds": []interface{}{"m1", "m3"}})
+	assert.Equal(t, []interface{}{"m1"}, resp["changed"])
+	assert.Equal(t, []modifyCall{{ids: []string{"m1"}, add: []string{}, remove: []string{"INBOX"}}}, modified)
+
+	_, resp = callTriageTool(t, tool, map[string]interface{}{"messageIds": []interface{}{"m2", "m3"}, "trash": true})
+	assert.Equal(t, []interface{}{"m2"}, resp["changed"])
+	assert.Equal(t, []string{"m2"}, trashed)
+}
@@ -0,0 +1,319 @@
+package gmailmcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bttk/bttk-mcp/pkg/gmail"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	gmailv1 "google.golang.org/api/gmail/v1"
+)
+
+// DefaultDailySendQuota is the number of messages that gmail_send sends a
+// day if SendOptions.DailyQuota is not set.
+const DefaultDailySendQuota = 10
+
+// Statuses of the audit log entries of gmail_send.
+const (
+	sendStatusSending  = "sending"
+	sendStatusSent     = "sent"
+	sendStatusRefused  = "refused"
+	sendStatusDeclined = "declined"
+	sendStatusFailed   = "failed"
+)
+
+var (
+	errRecipientNotAllowed = errors.New("recipients are not in the allowlist")
+	errSendQuota           = errors.New("daily send quota reached")
+	errSendNotConfirmed    = errors.New("sending was not confirmed")
+)
+
+// SendOptions configures the gmail_send tool.
+type SendOptions struct {
+	// Allowlist holds the addresses ("team@example.com") and domains
+	// ("example.com" or "@example.com") that messages may be sent to. No
+	// message is sent if it is empty.
+	Allowlist []string
+	// DailyQuota is the number of messages that are sent a day (default
+	// DefaultDailySendQuota).
+	DailyQuota int
+	// AuditLog is the path of the JSON Lines file that every attempt to send
+	// is written to. It is also used to count the messages sent today.
+	AuditLog string
+	// Confirm, if set, asks the user to confirm each message before it is
+	// sent. The message is not sent unless it returns true.
+	Confirm func(ctx context.Context, message string) (bool, error)
+	// now returns the current time; it is replaced in tests.
+	now func() time.Time
+}
+
+// sendAuditEntry is an entry of the audit log of gmail_send.
+type sendAuditEntry struct {
+	Time       time.Time `json:"time"`
+	Status     string    `json:"status"`
+	Recipients []string  `json:"recipients"`
+	Subject    string    `json:"subject"`
+	MessageID  string    `json:"messageId,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// sender sends messages within the limits of SendOptions. It sends one
+// message at a time, so that the quota holds.
+type sender struct {
+	client gmail.API
+	opts   SendOptions
+	mu     sync.Mutex
+}
+
+func GmailSendTool(opts SendOptions) mcp.Tool {
+	description := fmt.Sprintf("Send an email right away. Only the allowed recipients can be reached (%s), "+
+		"and at most %d messages are sent a day. Every attempt is logged. Prefer gmail_create_draft "+
+		"unless the message must go out without review.", strings.Join(opts.Allowlist, ", "), opts.dailyQuota())
+	if opts.Confirm != nil {
+		description += " The user is asked to confirm each message."
+	}
+	return mcp.NewTool("gmail_send",
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithOpenWorldHintAnnotation(true),
+		mcp.WithDescription(description),
+		mcp.WithString("to", mcp.Required(), mcp.Description("Recipients, like \"Jane Doe <jane@example.com>, bob@example.com\".")),
+		mcp.WithString("cc", mcp.Description("Carbon copy recipients.")),
+		mcp.WithString("bcc", mcp.Description("Blind carbon copy recipients.")),
+		mcp.WithString("subject", mcp.Required(), mcp.Description("The subject.")),
+		mcp.WithString("body", mcp.Description("The plain text body.")),
+		mcp.WithString("html", mcp.Description("The HTML body. A message with body and html has both versions.")),
+	)
+}
+
+func GmailSendHandler(client gmail.API, opts SendOptions) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s := &sender{client: client, opts: opts}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return mcp.NewToolResultError("arguments must be a map"), nil
+		}
+		msg := composeArgs(args)
+		if msg.Text == "" && msg.HTML == "" {
+			return mcp.NewToolResultError("body or html is required"), nil
+		}
+		if _, err := msg.MIME(); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		sent, err := s.send(ctx, msg)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("message not sent: %v", err)), nil
+		}
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"messageId": sent.Id,
+			"threadId":  sent.ThreadId,
+			"status":    sendStatusSent,
+		})
+	}
+}
+
+// send sends a message if its recipients are allowed, the quota is not
+// reached and the user confirms it, and writes the attempt to the audit
+// log.
+func (s *sender) send(ctx context.Context, msg *gmail.Compose) (*gmailv1.Message, error) {
+	recipients, err := msg.Recipients()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.opts.now != nil {
+		now = s.opts.now()
+	}
+	entry := sendAuditEntry{Time: now, Recipients: recipients, Subject: msg.Subject}
+
+	entry.Status, err = s.check(ctx, now, msg, recipients)
+	if err != nil {
+		entry.Error = err.Error()
+		if logErr := appendAuditEntry(s.opts.AuditLog, entry); logErr != nil {
+			return nil, errors.Join(err, logErr)
+		}
+		return nil, err
+	}
+
+	// The attempt is logged before the message is sent, so that no message
+	// goes out that is not logged and counted.
+	entry.Status = sendStatusSending
+	if err := appendAuditEntry(s.opts.AuditLog, entry); err != nil {
+		return nil, fmt.Errorf("writing the audit log: %w", err)
+	}
+
+	sent, err := s.client.SendMessage(msg)
+	entry.Status = sendStatusSent
+	if err != nil {
+		entry.Status = sendStatusFailed
+		entry.Error = err.Error()
+	} else {
+		entry.MessageID = sent.Id
+	}
+	if logErr := appendAuditEntry(s.opts.AuditLog, entry); logErr != nil {
+		if sent != nil {
+			return sent, fmt.Errorf("message %s was sent, but the audit log was not written: %w", sent.Id, logErr)
+		}
+		return nil, errors.Join(err, logErr)
+	}
+	return sent, err
+}
+
+// check returns an error and the status to log if the message must not
+// be sent.
+func (s *sender) check(ctx context.Context, now time.Time, msg *gmail.Compose, recipients []string) (string, error) {
+	if len(recipients) == 0 {
+		return sendStatusRefused, fmt.Errorf("%w: there are no recipients", errRecipientNotAllowed)
+	}
+	var denied []string
+	for _, r := range recipients {
+		if !allowedRecipient(s.opts.Allowlist, r) {
+			denied = append(denied, r)
+		}
+	}
+	if len(denied) > 0 {
+		return sendStatusRefused, fmt.Errorf("%w: %s", errRecipientNotAllowed, strings.Join(denied, ", "))
+	}
+
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	count, err := countSent(s.opts.AuditLog, start)
+	if err != nil {
+		return sendStatusRefused, fmt.Errorf("reading the audit log: %w", err)
+	}
+	if count >= s.opts.dailyQuota() {
+		return sendStatusRefused, fmt.Errorf("%w: %d messages sent today", errSendQuota, count)
+	}
+
+	if s.opts.Confirm != nil {
+		ok, err := s.opts.Confirm(ctx, fmt.Sprintf("Send %q to %s?", msg.Subject, strings.Join(recipients, ", ")))
+		if err != nil {
+			return sendStatusDeclined, fmt.Errorf("%w: %w", errSendNotConfirmed, err)
+		}
+		if !ok {
+			return sendStatusDeclined, errSendNotConfirmed
+		}
+	}
+	return "", nil
+}
+
+func (o SendOptions) dailyQuota() int {
+	if o.DailyQuota > 0 {
+		return o.DailyQuota
+	}
+	return DefaultDailySendQuota
+}
+
+// allowedRecipient reports whether the address matches an address or a
+// domain of the allowlist.
+func allowedRecipient(allowlist []string, address string) bool {
+	address = strings.ToLower(address)
+	_, domain, _ := strings.Cut(address, "@")
+	for _, allowed := range allowlist {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == address || strings.TrimPrefix(allowed, "@") == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// countSent returns the number of messages of the audit log sent since
+// start: the attempts to send, less those that failed. An attempt whose
+// result was not logged counts as sent. Lines that cannot be parsed, like
+// one cut short by a crash, are skipped.
+func countSent(auditLog string, start time.Time) (int, error) {
+	f, err := os.Open(auditLog)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry sendAuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if entry.Time.Before(start) {
+			continue
+		}
+		switch entry.Status {
+		case sendStatusSending:
+			count++
+		case sendStatusFailed:
+			count--
+		}
+	}
+	return max(count, 0), scanner.Err()
+}
+
+// appendAuditEntry appends an entry to the audit log. If the last line was
+// cut short, the entry starts a new line.
+func appendAuditEntry(auditLog string, entry sendAuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(auditLog, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ElicitConfirmation asks the user of the MCP client to confirm a message
+// with an elicitation request. It returns false if the client does not
+// support elicitation.
+func ElicitConfirmation(ctx context.Context, message string) (bool, error) {
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return false, server.ErrNoActiveSession
+	}
+	result, err := srv.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: message,
+			RequestedSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"confirm": map[string]interface{}{"type": "boolean", "title": "Send this message"},
+				},
+				"required": []string{"confirm"},
+			},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	if result.Action != mcp.ElicitationResponseActionAccept {
+		return false, nil
+	}
+	content, _ := result.Content.(map[string]interface{})
+	confirmed, _ := content["confirm"].(bool)
+	return confirmed, nil
+}
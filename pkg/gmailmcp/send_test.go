@@ -0,0 +1,132 @@
+package gmailmcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pkggmail "github.com/bttk/bttk-mcp/pkg/gmail"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/mcptest"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/gmail/v1"
+)
+
+func readAuditLog(t *testing.T, path string) []sendAuditEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var entries []sendAuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry sendAuditEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+	return entries
+}
+
+func TestGmailSend(t *testing.T) {
+	now := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	var sent []*pkggmail.Compose
+	client := &MockGmailClient{
+		SendMessageFunc: func(msg *pkggmail.Compose) (*gmail.Message, error) {
+			sent = append(sent, msg)
+			return &gmail.Message{Id: "s1", ThreadId: "t1"}, nil
+		},
+	}
+	confirm := true
+	opts := SendOptions{
+		Allowlist:  []string{"lead@partner.com", "@example.com"},
+		DailyQuota: 2,
+		AuditLog:   filepath.Join(t.TempDir(), "send.jsonl"),
+		Confirm:    func(context.Context, string) (bool, error) { return confirm, nil },
+		now:        func() time.Time { return now },
+	}
+
+	srv, err := mcptest.NewServer(t, server.ServerTool{Tool: GmailSendTool(opts), Handler: GmailSendHandler(client, opts)})
+	require.NoError(t, err)
+	defer srv.Close()
+	send := func(to string) *mcp.CallToolResult {
+		res, err := srv.Client().CallTool(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "gmail_send", Arguments: map[string]interface{}{
+				"to": to, "subject": "Status", "body": "All green.",
+			}},
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	assert.False(t, send("team@example.com, Lead <lead@partner.com>").IsError)
+	assert.True(t, send("team@example.com, eve@partner.com").IsError, "recipient not in the allowlist")
+	confirm = false
+	assert.True(t, send("team@example.com").IsError, "not confirmed")
+	confirm = true
+	assert.False(t, send("team@example.com").IsError)
+	assert.True(t, send("team@example.com").IsError, "quota reached")
+
+	// The quota starts over the next day.
+	now = now.Add(24 * time.Hour)
+	assert.False(t, send("team@example.com").IsError)
+	assert.Len(t, sent, 3)
+
+	var statuses []string
+	for _, entry := range readAuditLog(t, opts.AuditLog) {
+		statuses = append(statuses, entry.Status)
+	}
+	assert.Equal(t, []string{"sending", "sent", "refused", "declined", "sending", "sent", "refused", "sending", "sent"}, statuses)
+	first := readAuditLog(t, opts.AuditLog)[1]
+	assert.Equal(t, []string{"team@example.com", "lead@partner.com"}, first.Recipients)
+	assert.Equal(t, "s1", first.MessageID)
+}
+
+func TestAllowedRecipient(t *testing.T) {
+	allowlist := []string{"Lead@Partner.com", "example.com"}
+	assert.True(t, allowedRecipient(allowlist, "lead@partner.com"))
+	assert.True(t, allowedRecipient(allowlist, "team@EXAMPLE.com"))
+	assert.False(t, allowedRecipient(allowlist, "eve@partner.com"))
+	assert.False(t, allowedRecipient(allowlist, "eve@sub.example.com"))
+	assert.False(t, allowedRecipient(nil, "team@example.com"))
+}
+
+func TestAuditLogDamaged(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	auditLog := filepath.Join(t.TempDir(), "send.jsonl")
+	require.NoError(t, appendAuditEntry(auditLog, sendAuditEntry{Time: now, Status: sendStatusSending}))
+	// A crash cut the second entry short.
+	f, err := os.OpenFile(auditLog, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"time": "2024-03-01T12:01:00Z", "sta`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, appendAuditEntry(auditLog, sendAuditEntry{Time: now, Status: sendStatusSending}))
+
+	count, err := countSent(auditLog, now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestGmailSend_AuditLogUnwritable(t *testing.T) {
+	client := &MockGmailClient{
+		SendMessageFunc: func(*pkggmail.Compose) (*gmail.Message, error) {
+			t.Error("the message was sent without an audit log")
+			return &gmail.Message{Id: "s1"}, nil
+		},
+	}
+	opts := SendOptions{
+		Allowlist: []string{"example.com"},
+		AuditLog:  filepath.Join(t.TempDir(), "missing", "send.jsonl"),
+	}
+	s := &sender{client: client, opts: opts}
+	_, err := s.send(context.Background(), &pkggmail.Compose{To: "team@example.com", Subject: "Status", Text: "All green."})
+	require.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package gmailmcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/bttk/bttk-mcp/pkg/gmail"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const defaultMaxThreadBytes = 20000
+
+const truncatedMarker = "... [TRUNCATED]"
+
+// threadMessage is a message of a conversation.
+type threadMessage struct {
+	ID        string `json:"id"`
+	From      string `json:"from"`
+	To        string `json:"to,omitempty"`
+	Cc        string `json:"cc,omitempty"`
+	Date      string `json:"date"`
+	Body      string `json:"body"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+func GmailReadThreadTool() mcp.Tool {
+	return mcp.NewTool("gmail_read_thread",
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("Read a Gmail conversation by thread ID. Messages are returned oldest first with their sender, "+
+			"recipients, date and body; quoted replies and signatures are removed, so each body is only what its sender wrote."),
+		mcp.WithString("threadId", mcp.Required(), mcp.Description("The ID of the thread to read (threadId of a message).")),
+		mcp.WithNumber("maxBodyBytes", mcp.Description(fmt.Sprintf(
+			"Maximum bytes of body content to return for the whole thread, shared by its messages (default %d).", defaultMaxThreadBytes))),
+	)
+}
+
+func GmailReadThreadHandler(client gmail.API) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return mcp.NewToolResultError("arguments must be a map"), nil
+		}
+		id, ok := args["threadId"].(string)
+		if !ok {
+			return mcp.NewToolResultError("threadId argument must be a string"), nil
+		}
+
+		maxBodyBytes := defaultMaxThreadBytes
+		if mbb, ok := args["maxBodyBytes"].(float64); ok {
+			maxBodyBytes = int(mbb)
+		}
+
+		thread, err := client.GetThread(id)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get thread: %v", err)), nil
+		}
+
+		msgs := thread.Messages
+		sort.SliceStable(msgs, func(i, j int) bool { return msgs[i].InternalDate < msgs[j].InternalDate })
+		messages := make([]threadMessage, 0, len(msgs))
+		bodies := make([]string, 0, len(msgs))
+		subject := ""
+		for _, msg := range msgs {
+			m := gmail.NewMessage(msg)
+			if subject == "" {
+				subject = m.Subject
+			}
+			messages = append(messages, threadMessage{ID: m.ID, From: m.From, To: m.To, Cc: m.Cc, Date: m.Date})
+			bodies = append(bodies, gmail.StripSignature(gmail.StripQuotes(m.Body)))
+		}
+		for i, limit := range shareBudget(bodies, maxBodyBytes) {
+			messages[i].Body, messages[i].Truncated = truncate(bodies[i], limit)
+		}
+
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"threadId":     thread.Id,
+			"subject":      subject,
+			"messageCount": len(messages),
+			"messages":     messages,
+		})
+	}
+}
+
+// shareBudget splits budget bytes between texts: short texts are kept
+// whole, and the rest is shared equally by the longer ones.
+func shareBudget(texts []string, budget int) []int {
+	order := make([]int, len(texts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return len(texts[order[a]]) < len(texts[order[b]]) })
+
+	limits := make([]int, len(texts))
+	remaining := max(budget, 0)
+	for n, i := range order {
+		share := remaining / (len(order) - n)
+		limits[i] = min(len(texts[i]), share)
+		remaining -= limits[i]
+	}
+	return limits
+}
+
+// truncate cuts text to at most limit bytes, at a rune boundary.
+func truncate(text string, limit int) (string, bool) {
+	limit = max(limit, 0)
+	if len(text) <= limit {
+		return text, false
+	}
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return text[:limit] + truncatedMarker, true
+}
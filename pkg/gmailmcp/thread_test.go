@@ -0,0 +1,107 @@
+package gmailmcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/mcptest"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/gmail/v1"
+)
+
+func threadMessagePart(from, body string) *gmail.MessagePart {
+	return &gmail.MessagePart{
+		MimeType: "text/plain",
+		Headers: []*gmail.MessagePartHeader{
+			{Name: "Subject", Value: "Launch"},
+			{Name: "From", Value: from},
+			{Name: "To", Value: "team@example.com"},
+		},
+		Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(body))},
+	}
+}
+
+func readThread(t *testing.T, args map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	mockClient := &MockGmailClient{
+		GetThreadFunc: func(id string) (*gmail.Thread, error) {
+			if id != "t1" {
+				return nil, errMessageNotFound
+			}
+			// The API lists messages oldest first, but do not rely on it.
+			return &gmail.Thread{Id: "t1", Messages: []*gmail.Message{
+				{Id: "m2", InternalDate: 1709546400000, Payload: threadMessagePart("bob@example.com",
+					"Agreed, "+strings.Repeat("b", 100)+"\n\nOn Mon, Mar 4, 2024 Ann <ann@example.com> wrote:\n> Ship it on Friday?\n")},
+				{Id: "m1", InternalDate: 1709542800000, Payload: threadMessagePart("ann@example.com",
+					"Ship it on Friday?\n\n-- \nAnn\nRelease manager")},
+			}}, nil
+		},
+	}
+
+	srv, err := mcptest.NewServer(t, server.ServerTool{
+		Tool:    GmailReadThreadTool(),
+		Handler: GmailReadThreadHandler(mockClient),
+	})
+	require.NoError(t, err)
+	defer srv.Close()
+
+	res, err := srv.Client().CallTool(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "gmail_read_thread", Arguments: args},
+	})
+	require.NoError(t, err)
+	require.False(t, res.IsError, "Tool result should not be an error")
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &resp))
+	return resp
+}
+
+func TestGmailReadThread(t *testing.T) {
+	resp := readThread(t, map[string]interface{}{"threadId": "t1"})
+	assert.Equal(t, "Launch", resp["subject"])
+	assert.InDelta(t, 2, resp["messageCount"], 0)
+
+	messages, ok := resp["messages"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, messages, 2)
+	assert.Equal(t, map[string]interface{}{
+		"id":   "m1",
+		"from": "ann@example.com",
+		"to":   "team@example.com",
+		"date": "2024-03-04T09:00:00Z",
+		"body": "Ship it on Friday?",
+	}, messages[0])
+	second, ok := messages[1].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "Agreed, "+strings.Repeat("b", 100), second["body"])
+}
+
+func TestGmailReadThread_SharedBudget(t *testing.T) {
+	resp := readThread(t, map[string]interface{}{"threadId": "t1", "maxBodyBytes": 40})
+
+	messages, ok := resp["messages"].([]interface{})
+	require.True(t, ok)
+	first, ok := messages[0].(map[string]interface{})
+	require.True(t, ok)
+	second, ok := messages[1].(map[string]interface{})
+	require.True(t, ok)
+	// The short first message is kept whole, the second gets the rest.
+	assert.Equal(t, "Ship it on Friday?", first["body"])
+	assert.Equal(t, "Agreed, "+strings.Repeat("b", 14)+truncatedMarker, second["body"])
+	assert.Equal(t, true, second["truncated"])
+}
+
+func TestShareBudget(t *testing.T) {
+	texts := []string{strings.Repeat("a", 50), "short", strings.Repeat("c", 50)}
+	assert.Equal(t, []int{47, 5, 48}, shareBudget(texts, 100))
+	assert.Equal(t, []int{50, 5, 50}, shareBudget(texts, 1000))
+	assert.Equal(t, []int{0, 0, 0}, shareBudget(texts, 0))
+}
@@ -2,14 +2,10 @@ package gmailmcp
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
-	"strings"
 
 	"github.com/bttk/bttk-mcp/pkg/gmail"
 	"github.com/mark3labs/mcp-go/mcp"
-
-	gmailv1 "google.golang.org/api/gmail/v1"
 )
 
 const (
@@ -22,9 +18,12 @@ func GmailSearchTool() mcp.Tool {
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithIdempotentHintAnnotation(true),
-		mcp.WithDescription("Search for Gmail messages using a query string."),
+		mcp.WithDescription("Search for Gmail messages using a query string. Each message has its date, sender, "+
+			"recipients, subject, snippet, label names, whether it has attachments and the size of its thread. "+
+			"Results come in pages: pass the returned nextPageToken as pageToken to get the next page."),
 		mcp.WithString("query", mcp.Required(), mcp.Description("The search query (e.g., 'from:user@example.com', 'subject:meeting').")),
-		mcp.WithNumber("maxResults", mcp.Description("Maximum number of results to return (default 50).")),
+		mcp.WithNumber("maxResults", mcp.Description("Maximum number of results to return per page (default 50).")),
+		mcp.WithString("pageToken", mcp.Description("The nextPageToken of the previous page.")),
 	)
 }
 
@@ -39,21 +38,57 @@ func GmailSearchHandler(client gmail.API) func(ctx context.Context, request mcp.
 			return mcp.NewToolResultError("query argument must be a string"), nil
 		}
 
-		maxResults := int64(defaultMaxResults)
-		if mr, ok := args["maxResults"].(float64); ok {
-			maxResults = int64(mr)
-		}
-
-		msgs, err := client.SearchMessages(query, maxResults)
+		resp, err := search(client, query, args)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to search messages: %v", err)), nil
 		}
+		return mcp.NewToolResultJSON(resp)
+	}
+}
+
+// search runs a search with the maxResults and pageToken arguments of a
+// tool and returns the response of the tool.
+func search(client gmail.API, query string, args map[string]interface{}) (map[string]interface{}, error) {
+	maxResults := int64(defaultMaxResults)
+	if mr, ok := args["maxResults"].(float64); ok {
+		maxResults = int64(mr)
+	}
+	pageToken, _ := args["pageToken"].(string)
+
+	result, err := client.SearchMessages(query, pageToken, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	names := labelNames(client)
+	hits := make([]gmail.SearchHit, 0, len(result.Messages))
+	for _, m := range result.Messages {
+		hits = append(hits, gmail.NewSearchHit(m, names, result.ThreadSizes[m.ThreadId]))
+	}
 
-		return mcp.NewToolResultJSON(map[string]interface{}{
-			"messages": msgs,
-			"count":    len(msgs),
-		})
+	resp := map[string]interface{}{
+		"messages": hits,
+		"count":    len(hits),
+	}
+	if result.NextPageToken != "" {
+		resp["nextPageToken"] = result.NextPageToken
+	}
+	if len(result.Errors) > 0 {
+		resp["errors"] = result.Errors
+	}
+	return resp, nil
+}
+
+// labelNames maps label IDs to their names. Without names, hits show label
+// IDs.
+func labelNames(client gmail.API) map[string]string {
+	names := map[string]string{}
+	if labels, err := client.ListLabels(); err == nil {
+		for _, l := range labels {
+			names[l.Id] = l.Name
+		}
 	}
+	return names
 }
 
 func GmailReadTool() mcp.Tool {
@@ -61,7 +96,8 @@ func GmailReadTool() mcp.Tool {
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithIdempotentHintAnnotation(true),
-		mcp.WithDescription("Read the content of a specific Gmail message by ID."),
+		mcp.WithDescription("Read a specific Gmail message by ID: its sender, recipients, subject, date and Message-ID, "+
+			"its body as plain text (HTML messages are converted), and the names, sizes and IDs of its attachments."),
 		mcp.WithString("messageId", mcp.Required(), mcp.Description("The ID of the message to read.")),
 		mcp.WithNumber("maxBodyBytes", mcp.Description("Maximum bytes of body content to return (default 10000).")),
 	)
@@ -88,54 +124,12 @@ func GmailReadHandler(client gmail.API) func(ctx context.Context, request mcp.Ca
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get message: %v", err)), nil
 		}
 
-		currentBytes := 0
-		processPart(msg.Payload, maxBodyBytes, &currentBytes)
-
-		return mcp.NewToolResultJSON(msg)
-	}
-}
-
-func processPart(part *gmailv1.MessagePart, maxBytes int, currentBytes *int) {
-	if part == nil {
-		return
-	}
-
-	if part.Body != nil && part.Body.Data != "" {
-		processPartBody(part, maxBytes, currentBytes)
-	}
-
-	for _, p := range part.Parts {
-		processPart(p, maxBytes, currentBytes)
-	}
-}
-
-func processPartBody(part *gmailv1.MessagePart, maxBytes int, currentBytes *int) {
-	isText := strings.Contains(part.MimeType, "text/plain") || strings.Contains(part.MimeType, "text/html")
-	if !isText {
-		// Non-text message parts: remove data but leave metadata
-		part.Body.Data = ""
-		return
-	}
-
-	// Gmail uses base64url encoding
-	data, err := base64.URLEncoding.DecodeString(part.Body.Data)
-	if err != nil {
-		// Try raw if standard fails (sometimes padding is missing)
-		data, err = base64.RawURLEncoding.DecodeString(part.Body.Data)
-	}
-	if err != nil {
-		return
-	}
-
-	remaining := maxBytes - *currentBytes
-	switch {
-	case remaining <= 0:
-		part.Body.Data = ""
-	case len(data) > remaining:
-		part.Body.Data = string(data[:remaining]) + "... [TRUNCATED]"
-		*currentBytes = maxBytes
-	default:
-		part.Body.Data = string(data)
-		*currentBytes += len(data)
+		m := gmail.NewMessage(msg)
+		var truncated bool
+		m.Body, truncated = truncate(m.Body, maxBodyBytes)
+		return mcp.NewToolResultJSON(struct {
+			*gmail.Message
+			Truncated bool `json:"truncated,omitempty"`
+		}{m, truncated})
 	}
 }
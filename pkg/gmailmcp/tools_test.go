@@ -6,6 +6,7 @@ import (
 	"errors"
 	"testing"
 
+	pkggmail "github.com/bttk/bttk-mcp/pkg/gmail"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/mcptest"
 	"github.com/mark3labs/mcp-go/server"
@@ -17,15 +18,27 @@ var errMessageNotFound = errors.New("message not found")
 
 // MockGmailClient is a mock implementation of pkg_gmail.GmailAPI
 type MockGmailClient struct {
-	SearchMessagesFunc func(query string, maxResults int64) ([]*gmail.Message, error)
+	SearchMessagesFunc func(query, pageToken string, maxResults int64) (*pkggmail.SearchResult, error)
 	GetMessageFunc     func(id string) (*gmail.Message, error)
+	GetThreadFunc      func(id string) (*gmail.Thread, error)
+	GetAttachmentFunc  func(messageID, attachmentID string) ([]byte, error)
+	CreateDraftFunc    func(msg *pkggmail.Compose) (*gmail.Draft, error)
+	UpdateDraftFunc    func(id string, msg *pkggmail.Compose) (*gmail.Draft, error)
+	ListDraftsFunc     func(maxResults int64) ([]*gmail.Draft, error)
+	SendMessageFunc    func(msg *pkggmail.Compose) (*gmail.Message, error)
+	ListLabelsFunc     func() ([]*gmail.Label, error)
+	ModifyLabelsFunc   func(messageIDs, addLabelIDs, removeLabelIDs []string) error
+	TrashMessageFunc   func(id string) error
+	GetMetadataFunc    func(messageIDs []string) *pkggmail.SearchResult
+	GetProfileFunc     func() (*gmail.Profile, error)
+	ListHistoryFunc    func(startHistoryID uint64, labelID, pageToken string) (*gmail.ListHistoryResponse, error)
 }
 
-func (m *MockGmailClient) SearchMessages(query string, maxResults int64) ([]*gmail.Message, error) {
+func (m *MockGmailClient) SearchMessages(query, pageToken string, maxResults int64) (*pkggmail.SearchResult, error) {
 	if m.SearchMessagesFunc != nil {
-		return m.SearchMessagesFunc(query, maxResults)
+		return m.SearchMessagesFunc(query, pageToken, maxResults)
 	}
-	return nil, nil
+	return &pkggmail.SearchResult{}, nil
 }
 
 func (m *MockGmailClient) GetMessage(id string) (*gmail.Message, error) {
@@ -35,26 +48,121 @@ func (m *MockGmailClient) GetMessage(id string) (*gmail.Message, error) {
 	return nil, nil
 }
 
+func (m *MockGmailClient) GetThread(id string) (*gmail.Thread, error) {
+	if m.GetThreadFunc != nil {
+		return m.GetThreadFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *MockGmailClient) GetAttachment(messageID, attachmentID string) ([]byte, error) {
+	if m.GetAttachmentFunc != nil {
+		return m.GetAttachmentFunc(messageID, attachmentID)
+	}
+	return nil, nil
+}
+
+func (m *MockGmailClient) CreateDraft(msg *pkggmail.Compose) (*gmail.Draft, error) {
+	if m.CreateDraftFunc != nil {
+		return m.CreateDraftFunc(msg)
+	}
+	return nil, nil
+}
+
+func (m *MockGmailClient) UpdateDraft(id string, msg *pkggmail.Compose) (*gmail.Draft, error) {
+	if m.UpdateDraftFunc != nil {
+		return m.UpdateDraftFunc(id, msg)
+	}
+	return nil, nil
+}
+
+func (m *MockGmailClient) ListDrafts(maxResults int64) ([]*gmail.Draft, error) {
+	if m.ListDraftsFunc != nil {
+		return m.ListDraftsFunc(maxResults)
+	}
+	return nil, nil
+}
+
+func (m *MockGmailClient) SendMessage(msg *pkggmail.Compose) (*gmail.Message, error) {
+	if m.SendMessageFunc != nil {
+		return m.SendMessageFunc(msg)
+	}
+	return nil, nil
+}
+
+func (m *MockGmailClient) ListLabels() ([]*gmail.Label, error) {
+	if m.ListLabelsFunc != nil {
+		return m.ListLabelsFunc()
+	}
+	return nil, nil
+}
+
+func (m *MockGmailClient) ModifyLabels(messageIDs, addLabelIDs, removeLabelIDs []string) error {
+	if m.ModifyLabelsFunc != nil {
+		return m.ModifyLabelsFunc(messageIDs, addLabelIDs, removeLabelIDs)
+	}
+	return nil
+}
+
+func (m *MockGmailClient) TrashMessage(id string) error {
+	if m.TrashMessageFunc != nil {
+		return m.TrashMessageFunc(id)
+	}
+	return nil
+}
+
+func (m *MockGmailClient) GetMetadata(messageIDs []string) *pkggmail.SearchResult {
+	if m.GetMetadataFunc != nil {
+		return m.GetMetadataFunc(messageIDs)
+	}
+	return &pkggmail.SearchResult{}
+}
+
+func (m *MockGmailClient) GetProfile() (*gmail.Profile, error) {
+	if m.GetProfileFunc != nil {
+		return m.GetProfileFunc()
+	}
+	return nil, nil
+}
+
+func (m *MockGmailClient) ListHistory(startHistoryID uint64, labelID, pageToken string) (*gmail.ListHistoryResponse, error) {
+	if m.ListHistoryFunc != nil {
+		return m.ListHistoryFunc(startHistoryID, labelID, pageToken)
+	}
+	return nil, nil
+}
+
 func TestGmailSearch(t *testing.T) {
 	mockClient := &MockGmailClient{
-		SearchMessagesFunc: func(query string, _ int64) ([]*gmail.Message, error) {
+		SearchMessagesFunc: func(query, pageToken string, _ int64) (*pkggmail.SearchResult, error) {
+			if query == "test" && pageToken == "p2" {
+				return &pkggmail.SearchResult{
+					Messages: []*gmail.Message{{Id: "125", ThreadId: "t125"}},
+					Errors:   []pkggmail.MessageError{{ID: "126", Error: "rate limit exceeded"}},
+				}, nil
+			}
 			if query == "test" {
-				return []*gmail.Message{
+				return &pkggmail.SearchResult{NextPageToken: "p2", ThreadSizes: map[string]int{"t123": 4}, Messages: []*gmail.Message{
 					{
 						Id:       "123",
 						ThreadId: "t123",
+						LabelIds: []string{"INBOX", "Label_1"},
 						Snippet:  "Verification code...",
 						Payload: &gmail.MessagePart{
 							Headers: []*gmail.MessagePartHeader{
 								{Name: "To", Value: "me@example.com"},
 								{Name: "From", Value: "noreply@google.com"},
+								{Name: "Subject", Value: "Your code"},
 							},
 						},
 					},
 					{Id: "124", ThreadId: "t124"},
-				}, nil
+				}}, nil
 			}
-			return []*gmail.Message{}, nil
+			return &pkggmail.SearchResult{}, nil
+		},
+		ListLabelsFunc: func() ([]*gmail.Label, error) {
+			return []*gmail.Label{{Id: "INBOX", Name: "INBOX"}, {Id: "Label_1", Name: "Security"}}, nil
 		},
 	}
 
@@ -83,8 +191,24 @@ func TestGmailSearch(t *testing.T) {
 	assert.Contains(t, text.Text, `"count":2`)
 	assert.Contains(t, text.Text, `"id":"123"`)
 	assert.Contains(t, text.Text, `"snippet":"Verification code..."`)
-	assert.Contains(t, text.Text, `"name":"To","value":"me@example.com"`)
-	assert.Contains(t, text.Text, `"name":"From","value":"noreply@google.com"`)
+	assert.Contains(t, text.Text, `"from":"noreply@google.com","to":"me@example.com","subject":"Your code"`)
+	assert.Contains(t, text.Text, `"labels":["INBOX","Security"]`)
+	assert.Contains(t, text.Text, `"threadSize":4`)
+	assert.NotContains(t, text.Text, "payload")
+	assert.Contains(t, text.Text, `"nextPageToken":"p2"`)
+
+	res, err = srv.Client().CallTool(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "gmail_search",
+			Arguments: map[string]interface{}{"query": "test", "pageToken": "p2"},
+		},
+	})
+	assert.NoError(t, err)
+	text, ok = res.Content[0].(mcp.TextContent)
+	assert.True(t, ok)
+	assert.Contains(t, text.Text, `"count":1`)
+	assert.Contains(t, text.Text, `"errors":[{"id":"126","error":"rate limit exceeded"}]`)
+	assert.NotContains(t, text.Text, "nextPageToken")
 }
 
 func TestGmailRead(t *testing.T) {
@@ -135,10 +259,12 @@ func TestGmailRead(t *testing.T) {
 	assert.True(t, ok)
 
 	// Quick checks
-	checks := []string{`"id":"123"`, `"snippet":"Hello world"`, `"Test Email"`, `"sender@example.com"`, `"This is the decoded body content."`}
+	checks := []string{`"id":"123"`, `"snippet":"Hello world"`, `"subject":"Test Email"`, `"from":"sender@example.com"`, `"body":"This is the decoded body content."`}
 	for _, check := range checks {
 		assert.Contains(t, text.Text, check)
 	}
+	// The raw MIME tree is not returned.
+	assert.NotContains(t, text.Text, "payload")
 
 	// Test truncation
 	res, err = srv.Client().CallTool(context.Background(), mcp.CallToolRequest{
@@ -153,4 +279,5 @@ func TestGmailRead(t *testing.T) {
 	assert.NoError(t, err)
 	text, _ = res.Content[0].(mcp.TextContent)
 	assert.Contains(t, text.Text, "This is th... [TRUNCATED]")
+	assert.Contains(t, text.Text, `"truncated":true`)
 }
@@ -64,20 +64,12 @@ func (s *ActiveFileService) Delete(ctx context.Context) error {
 }
 
 // Patch updates the active file.
-func (s *ActiveFileService) Patch(ctx context.Context, op PatchOperation, targetType TargetType, target string, content string) error {
+func (s *ActiveFileService) Patch(ctx context.Context, op PatchOperation, targetType TargetType, target string, content string, opts ...PatchOption) error {
 	u := s.client.baseURL.ResolveReference(&url.URL{Path: "active/"})
-	req, err := http.NewRequestWithContext(ctx, "PATCH", u.String(), strings.NewReader(content))
+	req, err := newPatchRequest(ctx, u, op, targetType, target, content, opts)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Operation", string(op))
-	req.Header.Set("Target-Type", string(targetType))
-	req.Header.Set("Target", target)
-	// Default to markdown content type for simplicity in this helper,
-	// though the API supports JSON for table rows etc.
-	// We might want to make this flexible later.
-	req.Header.Set("Content-Type", "text/markdown")
-
 	return s.client.do(req, nil)
 }
@@ -0,0 +1,81 @@
+package obsidian
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// AnalysisService runs checks over many notes of the vault.
+//
+// The Local REST API serves one file per request, so the analyses read the
+// notes with a few requests in parallel and work on the contents locally.
+type AnalysisService struct {
+	client *Client
+}
+
+const defaultReadWorkers = 8
+
+// ReadError is a note that could not be read during an analysis.
+type ReadError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// markdownFiles returns the Markdown notes under folder.
+func (s *AnalysisService) markdownFiles(ctx context.Context, folder string) ([]string, error) {
+	files, err := s.client.Vault.ListRecursive(ctx, folder)
+	if err != nil {
+		return nil, err
+	}
+	notes := files[:0]
+	for _, f := range files {
+		if isMarkdown(f) {
+			notes = append(notes, f)
+		}
+	}
+	return notes, nil
+}
+
+// readAll reads the files in parallel with read, e.g. VaultService.Get or
+// VaultService.GetNote. Files that cannot be read are reported instead of
+// failing the whole analysis, unless ctx is done.
+func readAll[T any](ctx context.Context, paths []string, read func(context.Context, string) (T, error)) (map[string]T, []ReadError, error) {
+	contents := make(map[string]T, len(paths))
+	var failed []ReadError
+	var mu sync.Mutex
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for range min(defaultReadWorkers, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				content, err := read(ctx, p)
+				mu.Lock()
+				if err != nil {
+					failed = append(failed, ReadError{Path: p, Error: err.Error()})
+				} else {
+					contents[p] = content
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, p := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- p
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(failed, func(i, j int) bool { return failed[i].Path < failed[j].Path })
+	return contents, failed, nil
+}
@@ -0,0 +1,502 @@
+package obsidian
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Markdown parsing for exports. The parser covers the Markdown that notes
+// use in practice (ATX headings, paragraphs, lists and tasks, fenced code,
+// block quotes and callouts, tables, rules and math blocks) and the Obsidian
+// syntax on top of it: wikilinks, embeds, tags, highlights, comments and
+// block IDs. It is not a complete CommonMark implementation: raw HTML is
+// escaped, and setext headings, indented code and reference links are
+// rendered as text.
+
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockHeading
+	blockCode
+	blockMath
+	blockQuote
+	blockCallout
+	blockList
+	blockTable
+	blockRule
+)
+
+// mdBlock is a block of a parsed Markdown document.
+type mdBlock struct {
+	kind blockKind
+	// text is the inline text of paragraphs and headings, or the content of
+	// code and math blocks.
+	text  string
+	level int
+	// lang is the language of a code block, or the type of a callout.
+	lang string
+	// title is the title of a callout.
+	title string
+	// id is the block ID ("^id") of a paragraph, without the caret.
+	id string
+	// children are the blocks of a quote or callout.
+	children []*mdBlock
+	items    []listItem
+	ordered  bool
+	start    int
+	loose    bool
+	// rows are the cells of a table; the first row is the header.
+	rows  [][]string
+	align []string
+}
+
+type listItem struct {
+	task    bool
+	checked bool
+	blocks  []*mdBlock
+}
+
+const (
+	tabWidth          = 4
+	maxMarkerSpaces   = 4
+	maxOrderedDigits  = 9
+	minRuleCharacters = 3
+)
+
+// parseMarkdown parses the body of a note (without frontmatter).
+func parseMarkdown(content string) []*mdBlock {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return parseBlocks(strings.Split(stripComments(content), "\n"))
+}
+
+func parseBlocks(lines []string) []*mdBlock {
+	var blocks []*mdBlock
+	for i := 0; i < len(lines); {
+		block, n := parseBlock(lines[i:])
+		if block != nil {
+			blocks = append(blocks, block)
+		}
+		i += n
+	}
+	return blocks
+}
+
+// parseBlock parses the block at the start of lines and returns it with the
+// number of lines it spans. Blank lines return a nil block.
+func parseBlock(lines []string) (*mdBlock, int) {
+	line := lines[0]
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case trimmed == "":
+		return nil, 1
+	case isFence(line):
+		return parseFence(lines)
+	case trimmed == "$$":
+		return parseMath(lines)
+	case isRule(trimmed):
+		return &mdBlock{kind: blockRule}, 1
+	case strings.HasPrefix(trimmed, ">"):
+		return parseQuote(lines)
+	}
+	if level, text, ok := parseHeading(line); ok {
+		return &mdBlock{kind: blockHeading, level: level, text: text}, 1
+	}
+	if _, ok := parseListMarker(line); ok {
+		return parseList(lines)
+	}
+	if isTableStart(lines) {
+		return parseTable(lines)
+	}
+	return parseParagraph(lines)
+}
+
+// startsBlock reports whether line starts a block that interrupts a paragraph.
+func startsBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if isFence(line) || trimmed == "$$" || isRule(trimmed) || strings.HasPrefix(trimmed, ">") {
+		return true
+	}
+	if _, _, ok := parseHeading(line); ok {
+		return true
+	}
+	// Only ordered lists starting at 1 interrupt a paragraph, so that a
+	// line like "2024. was a good year" stays text.
+	m, ok := parseListMarker(line)
+	return ok && (!m.ordered || m.number == 1)
+}
+
+func isRule(trimmed string) bool {
+	s := strings.ReplaceAll(trimmed, " ", "")
+	if len(s) < minRuleCharacters || !strings.ContainsRune("-*_", rune(s[0])) {
+		return false
+	}
+	return strings.Trim(s, s[:1]) == ""
+}
+
+func parseFence(lines []string) (*mdBlock, int) {
+	open := strings.TrimSpace(lines[0])
+	fence := open[:len(open)-len(strings.TrimLeft(open, open[:1]))]
+	block := &mdBlock{kind: blockCode}
+	if info := strings.Fields(open[len(fence):]); len(info) > 0 {
+		block.lang = info[0]
+	}
+	indent := indentation(lines[0])
+
+	var code []string
+	for i := 1; i < len(lines); i++ {
+		if t := strings.TrimSpace(lines[i]); strings.HasPrefix(t, fence) && strings.Trim(t, fence[:1]) == "" {
+			block.text = strings.Join(code, "\n")
+			return block, i + 1
+		}
+		code = append(code, dedent(lines[i], indent))
+	}
+	// An unclosed fence runs to the end of the note.
+	block.text = strings.Join(code, "\n")
+	return block, len(lines)
+}
+
+func parseMath(lines []string) (*mdBlock, int) {
+	var math []string
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "$$" {
+			return &mdBlock{kind: blockMath, text: strings.Join(math, "\n")}, i + 1
+		}
+		math = append(math, lines[i])
+	}
+	return &mdBlock{kind: blockMath, text: strings.Join(math, "\n")}, len(lines)
+}
+
+func parseQuote(lines []string) (*mdBlock, int) {
+	var inner []string
+	n := 0
+	for ; n < len(lines); n++ {
+		rest, ok := strings.CutPrefix(strings.TrimLeft(lines[n], " \t"), ">")
+		if !ok {
+			break
+		}
+		inner = append(inner, strings.TrimPrefix(rest, " "))
+	}
+
+	block := &mdBlock{kind: blockQuote}
+	if typ, title, ok := parseCalloutHeader(inner[0]); ok {
+		block.kind, block.lang, block.title = blockCallout, typ, title
+		inner = inner[1:]
+	}
+	block.children = parseBlocks(inner)
+	return block, n
+}
+
+// parseCalloutHeader parses the first line of a callout: "[!type]+ Title".
+// The title defaults to the type.
+func parseCalloutHeader(line string) (typ, title string, ok bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), "[!")
+	end := strings.IndexByte(rest, ']')
+	if !ok || end <= 0 {
+		return "", "", false
+	}
+	typ = strings.ToLower(rest[:end])
+	title = strings.TrimSpace(strings.TrimLeft(rest[end+1:], "+-"))
+	if title == "" {
+		r, size := utf8.DecodeRuneInString(typ)
+		title = string(unicode.ToUpper(r)) + typ[size:]
+	}
+	return typ, title, true
+}
+
+// listMarker is the marker of a list item.
+type listMarker struct {
+	// indent is the number of columns before the marker.
+	indent int
+	// width is the number of columns of the marker and the spaces after
+	// it: the content of the item starts at indent+width.
+	width   int
+	ordered bool
+	number  int
+	// content is the text after the marker.
+	content string
+}
+
+func parseListMarker(line string) (listMarker, bool) {
+	m := listMarker{indent: indentation(line)}
+	rest := strings.TrimLeft(line, " \t")
+	size := 1
+	if rest == "" {
+		return m, false
+	}
+	if !strings.ContainsRune("-*+", rune(rest[0])) {
+		digits := len(rest) - len(strings.TrimLeft(rest, "0123456789"))
+		if digits == 0 || digits > maxOrderedDigits || digits == len(rest) || (rest[digits] != '.' && rest[digits] != ')') {
+			return m, false
+		}
+		m.ordered = true
+		m.number, _ = strconv.Atoi(rest[:digits])
+		size = digits + 1
+	}
+
+	after := rest[size:]
+	if after != "" && after[0] != ' ' && after[0] != '\t' {
+		return m, false
+	}
+	spaces := len(after) - len(strings.TrimLeft(after, " "))
+	if spaces == 0 || spaces > maxMarkerSpaces || strings.TrimSpace(after) == "" {
+		spaces = 1
+	}
+	m.width = size + spaces
+	m.content = strings.TrimLeft(after, " \t")
+	return m, true
+}
+
+func parseList(lines []string) (*mdBlock, int) {
+	first, _ := parseListMarker(lines[0])
+	list := &mdBlock{kind: blockList, ordered: first.ordered, start: first.number}
+
+	var item []string
+	contentIndent := 0
+	blank := false
+	n := 0
+	for ; n < len(lines); n++ {
+		line := lines[n]
+		if strings.TrimSpace(line) == "" {
+			if item != nil {
+				item = append(item, "")
+			}
+			blank = true
+			continue
+		}
+		indent := indentation(line)
+		if indent < contentIndent && isRule(strings.TrimSpace(line)) {
+			break
+		}
+		if m, ok := parseListMarker(line); ok && (item == nil || indent < contentIndent) {
+			if m.ordered != first.ordered {
+				break
+			}
+			// A new item of this list.
+			if item != nil {
+				list.items = append(list.items, newListItem(item))
+				list.loose = list.loose || blank
+			}
+			item = []string{m.content}
+			contentIndent = m.indent + m.width
+			blank = false
+			continue
+		}
+		switch {
+		case indent >= contentIndent:
+			item = append(item, dedent(line, contentIndent))
+		case !blank && !startsBlock(line):
+			// A lazy continuation line of the item's paragraph.
+			item = append(item, strings.TrimSpace(line))
+		default:
+			list.items = append(list.items, newListItem(item))
+			return list, n
+		}
+		blank = false
+	}
+	list.items = append(list.items, newListItem(item))
+	return list, n
+}
+
+func newListItem(lines []string) listItem {
+	var item listItem
+	first := lines[0]
+	if len(first) >= len("[ ]") && first[0] == '[' && first[2] == ']' {
+		if rest := first[len("[ ]"):]; rest == "" || rest[0] == ' ' {
+			// Obsidian treats any status other than a space as done: [x], [-], [>]...
+			item.task = true
+			item.checked = first[1] != ' '
+			lines[0] = strings.TrimLeft(rest, " ")
+		}
+	}
+	item.blocks = parseBlocks(lines)
+	return item
+}
+
+func isTableStart(lines []string) bool {
+	if len(lines) < 2 || !strings.Contains(lines[0], "|") {
+		return false
+	}
+	header, delim := splitTableRow(lines[0]), splitTableRow(lines[1])
+	if len(header) != len(delim) {
+		return false
+	}
+	for _, cell := range delim {
+		if !strings.Contains(cell, "-") || strings.Trim(cell, ":-") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseTable(lines []string) (*mdBlock, int) {
+	table := &mdBlock{kind: blockTable, rows: [][]string{splitTableRow(lines[0])}}
+	for _, cell := range splitTableRow(lines[1]) {
+		left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+		align := ""
+		switch {
+		case left && right:
+			align = "center"
+		case right:
+			align = "right"
+		case left:
+			align = "left"
+		}
+		table.align = append(table.align, align)
+	}
+	n := 2
+	for ; n < len(lines) && strings.TrimSpace(lines[n]) != "" && strings.Contains(lines[n], "|"); n++ {
+		table.rows = append(table.rows, splitTableRow(lines[n]))
+	}
+	return table, n
+}
+
+// splitTableRow splits a table row into its trimmed cells. Escaped pipes
+// ("\|", as in [[Note\|Alias]]) do not separate cells.
+func splitTableRow(line string) []string {
+	line = strings.TrimPrefix(strings.TrimSpace(line), "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '|':
+			cells = append(cells, strings.TrimSpace(line[start:i]))
+			start = i + 1
+		}
+	}
+	return append(cells, strings.TrimSpace(line[start:]))
+}
+
+func parseParagraph(lines []string) (*mdBlock, int) {
+	text := []string{strings.TrimSpace(lines[0])}
+	n := 1
+	for ; n < len(lines) && strings.TrimSpace(lines[n]) != "" && !startsBlock(lines[n]); n++ {
+		text = append(text, strings.TrimSpace(lines[n]))
+	}
+	block := &mdBlock{kind: blockParagraph}
+	block.text, block.id = cutBlockID(strings.Join(text, "\n"))
+	if block.text == "" {
+		// A block ID on its own line labels the previous block; it is not rendered.
+		return nil, n
+	}
+	return block, n
+}
+
+// cutBlockID removes a trailing block ID ("text ^id") from a paragraph.
+func cutBlockID(text string) (string, string) {
+	i := strings.LastIndexByte(text, '^')
+	if i < 0 || (i > 0 && text[i-1] != ' ' && text[i-1] != '\n') {
+		return text, ""
+	}
+	id := text[i+1:]
+	if id == "" || strings.IndexFunc(id, func(r rune) bool { return !isBlockIDRune(r) }) >= 0 {
+		return text, ""
+	}
+	return strings.TrimRight(text[:i], " \n"), id
+}
+
+func isBlockIDRune(r rune) bool {
+	return r == '-' || (r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+}
+
+// stripComments removes Obsidian comments (%%...%%), which may span lines,
+// outside code blocks.
+func stripComments(content string) string {
+	if !strings.Contains(content, "%%") {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	inFence, inComment := false, false
+	for i, line := range lines {
+		if !inComment && isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence || (!inComment && !strings.Contains(line, "%%")) {
+			continue
+		}
+		var sb strings.Builder
+		for {
+			j := strings.Index(line, "%%")
+			if !inComment {
+				if j < 0 {
+					sb.WriteString(line)
+					break
+				}
+				sb.WriteString(line[:j])
+			} else if j < 0 {
+				break
+			}
+			line = line[j+2:]
+			inComment = !inComment
+		}
+		lines[i] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// indentation returns the number of columns of leading whitespace.
+func indentation(line string) int {
+	cols := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			cols++
+		case '\t':
+			cols += tabWidth - cols%tabWidth
+		default:
+			return cols
+		}
+	}
+	return cols
+}
+
+// dedent removes up to cols columns of leading whitespace.
+func dedent(line string, cols int) string {
+	col := 0
+	for i := 0; i < len(line); i++ {
+		if col >= cols {
+			return line[i:]
+		}
+		switch line[i] {
+		case ' ':
+			col++
+		case '\t':
+			next := col + tabWidth - col%tabWidth
+			if next > cols {
+				return strings.Repeat(" ", next-cols) + line[i+1:]
+			}
+			col = next
+		default:
+			return line[i:]
+		}
+	}
+	return ""
+}
+
+// headingID returns the HTML ID of a heading: its text in lower case, with
+// dashes for spaces and without punctuation.
+func headingID(text string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		case unicode.IsSpace(r) || r == '-':
+			dash = true
+		}
+	}
+	return sb.String()
+}
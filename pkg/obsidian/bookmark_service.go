@@ -0,0 +1,222 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+const bookmarksFile = DefaultConfigDir + "/bookmarks.json"
+
+// BookmarkService reads and edits the bookmarks of the core Bookmarks plugin.
+//
+// Bookmarks are stored in the bookmarks.json file of the configuration
+// folder. Obsidian reads the file at startup and overwrites it whenever
+// bookmarks change in the UI, so edits made here show up in Obsidian only
+// after the plugin is reloaded.
+type BookmarkService struct {
+	client *Client
+}
+
+// Bookmark types.
+const (
+	BookmarkFile    = "file"
+	BookmarkFolder  = "folder"
+	BookmarkGroup   = "group"
+	BookmarkSearch  = "search"
+	BookmarkHeading = "heading"
+	BookmarkBlock   = "block"
+	BookmarkGraph   = "graph"
+	BookmarkURL     = "url"
+)
+
+// Bookmark is an item of bookmarks.json. Groups contain other bookmarks.
+type Bookmark struct {
+	Type  string `json:"type"`
+	Ctime int64  `json:"ctime,omitempty"`
+	Title string `json:"title,omitempty"`
+	// Path of a file, folder, heading or block bookmark.
+	Path string `json:"path,omitempty"`
+	// Subpath of a heading ("#Heading") or block ("#^id") bookmark.
+	Subpath string     `json:"subpath,omitempty"`
+	Query   string     `json:"query,omitempty"`
+	URL     string     `json:"url,omitempty"`
+	Items   []Bookmark `json:"items,omitempty"`
+
+	// extra keeps fields this type does not know about, so that saving
+	// does not lose them.
+	extra map[string]json.RawMessage
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Bookmark) UnmarshalJSON(data []byte) error {
+	type plain Bookmark
+	if err := json.Unmarshal(data, (*plain)(b)); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, known := range []string{"type", "ctime", "title", "path", "subpath", "query", "url", "items"} {
+		delete(fields, known)
+	}
+	if len(fields) > 0 {
+		b.extra = fields
+	} else {
+		b.extra = nil
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b Bookmark) MarshalJSON() ([]byte, error) {
+	type plain Bookmark
+	data, err := json.Marshal(plain(b))
+	emptyGroup := b.Type == BookmarkGroup && len(b.Items) == 0
+	if err != nil || (len(b.extra) == 0 && !emptyGroup) {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if emptyGroup {
+		// Obsidian expects groups to have an items array.
+		fields["items"] = json.RawMessage("[]")
+	}
+	for k, v := range b.extra {
+		if _, ok := fields[k]; !ok {
+			fields[k] = v
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// DisplayTitle returns the title of the bookmark, or a title derived from
+// what it points to if it has none.
+func (b *Bookmark) DisplayTitle() string {
+	if b.Title != "" {
+		return b.Title
+	}
+	switch b.Type {
+	case BookmarkFile, BookmarkFolder:
+		return strings.TrimSuffix(path.Base(b.Path), ".md")
+	case BookmarkHeading, BookmarkBlock:
+		return strings.TrimSuffix(path.Base(b.Path), ".md") + b.Subpath
+	case BookmarkSearch:
+		return b.Query
+	case BookmarkURL:
+		return b.URL
+	default:
+		return b.Type
+	}
+}
+
+type bookmarksDocument struct {
+	Items []Bookmark `json:"items"`
+}
+
+// List returns the bookmarks. It returns no bookmarks if the file does not exist.
+func (s *BookmarkService) List(ctx context.Context) ([]Bookmark, error) {
+	raw, err := s.client.Vault.Get(ctx, bookmarksFile)
+	if IsNotFound(err) {
+		return []Bookmark{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var doc bookmarksDocument
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse bookmarks.json: %w", err)
+	}
+	if doc.Items == nil {
+		doc.Items = []Bookmark{}
+	}
+	return doc.Items, nil
+}
+
+// Save replaces all bookmarks.
+func (s *BookmarkService) Save(ctx context.Context, bookmarks []Bookmark) error {
+	if bookmarks == nil {
+		bookmarks = []Bookmark{}
+	}
+	data, err := json.MarshalIndent(bookmarksDocument{Items: bookmarks}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.client.Vault.Create(ctx, bookmarksFile, string(data))
+}
+
+// Add adds a bookmark at the end of the named group, which is created if it
+// does not exist. Nested groups are separated by "/". An empty group adds
+// the bookmark at the top level.
+func (s *BookmarkService) Add(ctx context.Context, bookmark Bookmark, group string) error {
+	bookmarks, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+	if bookmark.Ctime == 0 {
+		bookmark.Ctime = time.Now().UnixMilli()
+	}
+
+	items := &bookmarks
+	for _, name := range strings.Split(strings.Trim(group, "/"), "/") {
+		if name == "" {
+			continue
+		}
+		items = groupItems(items, name, bookmark.Ctime)
+	}
+	*items = append(*items, bookmark)
+	return s.Save(ctx, bookmarks)
+}
+
+// groupItems returns the items of the group with the given title in items,
+// creating the group if needed.
+func groupItems(items *[]Bookmark, title string, ctime int64) *[]Bookmark {
+	for i := range *items {
+		if b := &(*items)[i]; b.Type == BookmarkGroup && b.Title == title {
+			return &b.Items
+		}
+	}
+	*items = append(*items, Bookmark{Type: BookmarkGroup, Ctime: ctime, Title: title})
+	return &(*items)[len(*items)-1].Items
+}
+
+// Remove removes all bookmarks of the file at filePath (including heading and
+// block bookmarks), in any group. It returns the number of bookmarks removed.
+func (s *BookmarkService) Remove(ctx context.Context, filePath string) (int, error) {
+	bookmarks, err := s.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	kept, removed := removeBookmarks(bookmarks, filePath)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, s.Save(ctx, kept)
+}
+
+func removeBookmarks(items []Bookmark, filePath string) ([]Bookmark, int) {
+	kept := make([]Bookmark, 0, len(items))
+	removed := 0
+	for _, b := range items {
+		if b.Type != BookmarkGroup && b.Path == filePath {
+			removed++
+			continue
+		}
+		if b.Type == BookmarkGroup {
+			var n int
+			b.Items, n = removeBookmarks(b.Items, filePath)
+			removed += n
+		}
+		kept = append(kept, b)
+	}
+	return kept, removed
+}
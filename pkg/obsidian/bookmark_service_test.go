@@ -0,0 +1,106 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBookmarks = `{
+  "items": [
+    {"type": "file", "ctime": 1, "path": "Home.md"},
+    {"type": "group", "ctime": 2, "title": "Work", "color": "red", "items": [
+      {"type": "heading", "ctime": 3, "path": "Work/Plan.md", "subpath": "#Goals", "title": "Goals"},
+      {"type": "search", "ctime": 4, "query": "tag:#todo"}
+    ]}
+  ]
+}`
+
+// bookmarksServer serves bookmarks.json from content and records what is written to it.
+func bookmarksServer(t *testing.T, content string, saved *string) *Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/vault/.obsidian/bookmarks.json", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			if content == "" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"errorCode": 40400, "message": "Not Found"}`)
+				return
+			}
+			fmt.Fprint(w, content)
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			*saved = string(body)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+	return client
+}
+
+func TestBookmarks_List(t *testing.T) {
+	client := bookmarksServer(t, testBookmarks, nil)
+
+	bookmarks, err := client.Bookmarks.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, bookmarks, 2)
+	assert.Equal(t, "Home", bookmarks[0].DisplayTitle())
+	assert.Equal(t, "Work", bookmarks[1].Title)
+	require.Len(t, bookmarks[1].Items, 2)
+	assert.Equal(t, "Goals", bookmarks[1].Items[0].DisplayTitle())
+	assert.Equal(t, "tag:#todo", bookmarks[1].Items[1].DisplayTitle())
+}
+
+func TestBookmarks_ListMissingFile(t *testing.T) {
+	client := bookmarksServer(t, "", nil)
+
+	bookmarks, err := client.Bookmarks.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, bookmarks)
+}
+
+func TestBookmarks_Add(t *testing.T) {
+	var saved string
+	client := bookmarksServer(t, testBookmarks, &saved)
+
+	err := client.Bookmarks.Add(context.Background(), Bookmark{Type: BookmarkFile, Path: "Work/Notes.md"}, "Work/Reference")
+	require.NoError(t, err)
+
+	var doc struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(saved), &doc))
+	work := doc.Items[1]
+	// Unknown fields are kept.
+	assert.Equal(t, "red", work["color"])
+
+	items := work["items"].([]interface{})
+	require.Len(t, items, 3)
+	group := items[2].(map[string]interface{})
+	assert.Equal(t, "Reference", group["title"])
+	added := group["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Work/Notes.md", added["path"])
+	assert.NotZero(t, added["ctime"])
+}
+
+func TestBookmarks_Remove(t *testing.T) {
+	var saved string
+	client := bookmarksServer(t, testBookmarks, &saved)
+
+	removed, err := client.Bookmarks.Remove(context.Background(), "Work/Plan.md")
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.NotContains(t, saved, "Plan.md")
+	assert.Contains(t, saved, "tag:#todo")
+}
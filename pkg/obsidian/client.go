@@ -29,6 +29,10 @@ type Client struct {
 	Search     *SearchService
 	Commands   *CommandService
 	Open       *OpenService
+	Workspace  *WorkspaceService
+	Bookmarks  *BookmarkService
+	Analysis   *AnalysisService
+	Export     *ExportService
 }
 
 // Option is a functional option for configuring the Client.
@@ -111,6 +115,10 @@ func (c *Client) initializeServices() {
 	c.Search = &SearchService{client: c}
 	c.Commands = &CommandService{client: c}
 	c.Open = &OpenService{client: c}
+	c.Workspace = &WorkspaceService{client: c}
+	c.Bookmarks = &BookmarkService{client: c}
+	c.Analysis = &AnalysisService{client: c}
+	c.Export = &ExportService{client: c}
 }
 
 func (c *Client) do(req *http.Request, v interface{}) error {
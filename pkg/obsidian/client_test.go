@@ -3,9 +3,11 @@ package obsidian
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -195,6 +197,61 @@ func TestClient_Vault_Create(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestClient_Vault_Upload(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/vault/Attachments/scan.pdf", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "application/pdf", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, []byte("%PDF-1.7"), body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	err = client.Vault.Upload(context.Background(), "Attachments/scan.pdf", []byte("%PDF-1.7"), "application/pdf")
+	require.NoError(t, err)
+}
+
+func TestClient_Vault_Append(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/vault/log.md", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "text/markdown", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	err = client.Vault.Append(context.Background(), "log.md", "- entry\n")
+	require.NoError(t, err)
+}
+
+func TestClient_Vault_Patch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/vault/note.md", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "replace", r.Header.Get("Operation"))
+		assert.Equal(t, "frontmatter", r.Header.Get("Target-Type"))
+		assert.Equal(t, "status", r.Header.Get("Target"))
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	err = client.Vault.Patch(context.Background(), "note.md", PatchReplace, TargetFrontmatter, "status", "done")
+	require.NoError(t, err)
+}
+
 func TestClient_Vault_Delete(t *testing.T) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/vault/todelete.md", func(w http.ResponseWriter, r *http.Request) {
@@ -251,3 +308,89 @@ func TestClient_Search_Dataview(t *testing.T) {
 	require.Len(t, results, 1)
 	assert.Equal(t, "b.md", results[0].Filename)
 }
+
+func TestClient_Periodic_PatchCurrent_CreateTarget(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/periodic/daily/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "Journal/Log", r.Header.Get("Target"))
+		assert.Equal(t, "/", r.Header.Get("Target-Delimiter"))
+		assert.Equal(t, "true", r.Header.Get("Create-Target-If-Missing"))
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	err = client.Periodic.PatchCurrent(context.Background(), "daily", PatchAppend, TargetHeading, "Journal/Log", "- entry\n",
+		WithCreateTargetIfMissing(), WithTargetDelimiter("/"))
+	require.NoError(t, err)
+}
+
+func TestIsNotFound(t *testing.T) {
+	assert.True(t, IsNotFound(fmt.Errorf("wrapped: %w", &ErrorResponse{ErrorCode: 40400})))
+	assert.False(t, IsNotFound(&ErrorResponse{ErrorCode: 40149}))
+	assert.False(t, IsNotFound(ErrAPI))
+}
+
+func TestClient_Periodic_DailyNotePath(t *testing.T) {
+	date := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name     string
+		periodic string
+		core     string
+		want     string
+	}{
+		{name: "defaults", want: "2024-03-01.md"},
+		{name: "core plugin", core: `{"folder": "Daily/", "format": "YYYY/MM/YYYY-MM-DD"}`, want: "Daily/2024/03/2024-03-01.md"},
+		{
+			name:     "periodic notes",
+			periodic: `{"daily": {"enabled": true, "folder": "Journal", "format": "dddd, MMMM Do YYYY"}}`,
+			core:     `{"folder": "Daily"}`,
+			want:     "Journal/Friday, March 1st 2024.md",
+		},
+		{
+			name:     "periodic notes without daily notes",
+			periodic: `{"daily": {"enabled": false, "folder": "Journal"}}`,
+			core:     `{"folder": "Daily"}`,
+			want:     "Daily/2024-03-01.md",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			for file, content := range map[string]string{
+				"plugins/periodic-notes/data.json": tc.periodic,
+				"daily-notes.json":                 tc.core,
+			} {
+				mux.HandleFunc("/vault/.obsidian/"+file, func(w http.ResponseWriter, _ *http.Request) {
+					if content == "" {
+						w.WriteHeader(http.StatusNotFound)
+						fmt.Fprint(w, `{"errorCode": 40400, "message": "Not Found"}`)
+						return
+					}
+					fmt.Fprint(w, content)
+				})
+			}
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "test-token")
+			require.NoError(t, err)
+			got, err := client.Periodic.DailyNotePath(context.Background(), date)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFormatMomentDate(t *testing.T) {
+	date := time.Date(2024, 1, 12, 9, 0, 0, 0, time.UTC)
+	got, err := FormatMomentDate("GGGG-[W]WW ddd D/M/YY Q", date)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-W02 Fri 12/1/24 1", got)
+
+	_, err = FormatMomentDate("YYYY-MM-DD HH:mm", date)
+	assert.ErrorIs(t, err, ErrUnsupportedDateFormat)
+}
@@ -0,0 +1,175 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnsupportedDateFormat is returned when a daily note format uses a
+// Moment.js token that FormatMomentDate does not support.
+var ErrUnsupportedDateFormat = errors.New("unsupported date format")
+
+// defaultDailyNoteFormat is the daily note format of Obsidian when none is set.
+const defaultDailyNoteFormat = "YYYY-MM-DD"
+
+// dailyNoteSettings are the daily note settings of the core Daily notes
+// plugin, and of the daily notes of the Periodic Notes plugin.
+type dailyNoteSettings struct {
+	Enabled bool   `json:"enabled"`
+	Folder  string `json:"folder"`
+	Format  string `json:"format"`
+}
+
+// DailyNotePath returns the vault path of the daily note of date, from the
+// settings of the Periodic Notes plugin if it handles daily notes, or else
+// of the core Daily notes plugin.
+//
+// The Local REST API creates periodic notes without telling their path, so
+// the settings are read from the configuration folder.
+func (s *PeriodicService) DailyNotePath(ctx context.Context, date time.Time) (string, error) {
+	settings, err := s.dailyNoteSettings(ctx)
+	if err != nil {
+		return "", err
+	}
+	format := settings.Format
+	if format == "" {
+		format = defaultDailyNoteFormat
+	}
+	name, err := FormatMomentDate(format, date)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(strings.Trim(settings.Folder, "/"), name+".md"), nil
+}
+
+func (s *PeriodicService) dailyNoteSettings(ctx context.Context) (dailyNoteSettings, error) {
+	var periodic struct {
+		Daily dailyNoteSettings `json:"daily"`
+	}
+	if found, err := s.readSettings(ctx, "plugins/periodic-notes/data.json", &periodic); err != nil {
+		return dailyNoteSettings{}, err
+	} else if found && periodic.Daily.Enabled {
+		return periodic.Daily, nil
+	}
+
+	var core dailyNoteSettings
+	if _, err := s.readSettings(ctx, "daily-notes.json", &core); err != nil {
+		return dailyNoteSettings{}, err
+	}
+	return core, nil
+}
+
+// readSettings decodes a JSON file of the configuration folder into v. It
+// reports false if the file does not exist.
+func (s *PeriodicService) readSettings(ctx context.Context, name string, v interface{}) (bool, error) {
+	raw, err := s.client.Vault.Get(ctx, DefaultConfigDir+"/"+name)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(raw), v); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// FormatMomentDate formats t with a Moment.js format, as Obsidian names
+// daily notes: "YYYY-MM-DD", "YYYY/MM/dddd, MMMM Do". Text in brackets is
+// kept as it is.
+func FormatMomentDate(format string, t time.Time) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(format); {
+		if format[i] == '[' {
+			end := strings.IndexByte(format[i:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("%w: unclosed [ in %q", ErrUnsupportedDateFormat, format)
+			}
+			b.WriteString(format[i+1 : i+end])
+			i += end + 1
+			continue
+		}
+		c := format[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		n := 1
+		for i+n < len(format) && format[i+n] == c {
+			n++
+		}
+		token := format[i : i+n]
+		// "Do" is the day of the month with an ordinal suffix.
+		if token == "D" && strings.HasPrefix(format[i+1:], "o") {
+			token = "Do"
+		}
+		value, ok := momentToken(token, t)
+		if !ok {
+			return "", fmt.Errorf("%w: %q in %q", ErrUnsupportedDateFormat, token, format)
+		}
+		b.WriteString(value)
+		i += len(token)
+	}
+	return b.String(), nil
+}
+
+func momentToken(token string, t time.Time) (string, bool) {
+	year, week := t.ISOWeek()
+	switch token {
+	case "YYYY":
+		return strconv.Itoa(t.Year()), true
+	case "YY":
+		return t.Format("06"), true
+	case "Q":
+		return strconv.Itoa((int(t.Month())-1)/3 + 1), true //nolint:mnd
+	case "MMMM":
+		return t.Format("January"), true
+	case "MMM":
+		return t.Format("Jan"), true
+	case "MM":
+		return t.Format("01"), true
+	case "M":
+		return strconv.Itoa(int(t.Month())), true
+	case "DD":
+		return t.Format("02"), true
+	case "D":
+		return strconv.Itoa(t.Day()), true
+	case "Do":
+		return strconv.Itoa(t.Day()) + ordinalSuffix(t.Day()), true
+	case "dddd":
+		return t.Format("Monday"), true
+	case "ddd":
+		return t.Format("Mon"), true
+	case "GGGG":
+		return strconv.Itoa(year), true
+	case "WW":
+		return fmt.Sprintf("%02d", week), true
+	case "W":
+		return strconv.Itoa(week), true
+	default:
+		return "", false
+	}
+}
+
+func ordinalSuffix(day int) string { //nolint:mnd
+	switch {
+	case day >= 11 && day <= 13:
+		return "th"
+	case day%10 == 1:
+		return "st"
+	case day%10 == 2:
+		return "nd"
+	case day%10 == 3:
+		return "rd"
+	default:
+		return "th"
+	}
+}
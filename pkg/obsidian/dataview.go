@@ -0,0 +1,278 @@
+package obsidian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrDataview is returned when a Dataview query is rejected by Obsidian.
+var ErrDataview = errors.New("dataview query failed")
+
+// dataviewParseError starts the banner of the errors of the Dataview query
+// parser, like "-- PARSING FAILED ----", in lower case.
+const dataviewParseError = "-- parsing failed"
+
+// DataviewQueryType is the kind of a Dataview query, derived from its leading keyword.
+type DataviewQueryType string
+
+const (
+	DataviewQueryTable    DataviewQueryType = "table"
+	DataviewQueryList     DataviewQueryType = "list"
+	DataviewQueryTask     DataviewQueryType = "task"
+	DataviewQueryCalendar DataviewQueryType = "calendar"
+	DataviewQueryUnknown  DataviewQueryType = "unknown"
+)
+
+const (
+	// DataviewFileColumn is the name of the column holding the file of each row.
+	DataviewFileColumn = "File"
+	// DataviewValueColumn is the name of the column used for non-tabular results.
+	DataviewValueColumn = "Value"
+)
+
+// DataviewTable holds Dataview results normalized into columns and rows.
+// The first column is always DataviewFileColumn.
+type DataviewTable struct {
+	Type    DataviewQueryType `json:"type"`
+	Columns []string          `json:"columns"`
+	Rows    [][]interface{}   `json:"rows"`
+}
+
+// DetectDataviewQueryType returns the type of the given DQL query.
+func DetectDataviewQueryType(dql string) DataviewQueryType {
+	fields := strings.Fields(dql)
+	if len(fields) == 0 {
+		return DataviewQueryUnknown
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "TABLE":
+		return DataviewQueryTable
+	case "LIST":
+		return DataviewQueryList
+	case "TASK":
+		return DataviewQueryTask
+	case "CALENDAR":
+		return DataviewQueryCalendar
+	default:
+		return DataviewQueryUnknown
+	}
+}
+
+// DataviewTable performs a DQL search and normalizes the results into a table.
+// Errors reported by Dataview are rewritten by ExplainDataviewError.
+func (s *SearchService) DataviewTable(ctx context.Context, dql string) (*DataviewTable, error) {
+	results, err := s.Dataview(ctx, dql)
+	if err != nil {
+		return nil, ExplainDataviewError(err)
+	}
+	return NormalizeDataview(dql, results), nil
+}
+
+// NormalizeDataview converts raw Dataview results into a DataviewTable.
+// Object results (TABLE queries) become one row per file with a column per field,
+// keeping the order in which the fields were returned. Array results (LIST and TASK
+// queries) become one row per element, and scalar results are put in a single
+// DataviewValueColumn.
+func NormalizeDataview(dql string, results []JSONLogicResult) *DataviewTable {
+	t := &DataviewTable{
+		Type:    DetectDataviewQueryType(dql),
+		Columns: []string{DataviewFileColumn},
+		Rows:    [][]interface{}{},
+	}
+	index := map[string]int{DataviewFileColumn: 0}
+
+	for _, r := range results {
+		switch v := r.Result.(type) {
+		case map[string]interface{}:
+			t.addObjectRow(r.Filename, v, orderedKeys(r.raw, v), index)
+		case []interface{}:
+			for _, item := range v {
+				if obj, ok := item.(map[string]interface{}); ok {
+					t.addObjectRow(r.Filename, obj, sortedKeys(obj), index)
+					continue
+				}
+				t.addObjectRow(r.Filename, map[string]interface{}{DataviewValueColumn: item}, []string{DataviewValueColumn}, index)
+			}
+		case nil:
+			t.Rows = append(t.Rows, []interface{}{r.Filename})
+		default:
+			t.addObjectRow(r.Filename, map[string]interface{}{DataviewValueColumn: v}, []string{DataviewValueColumn}, index)
+		}
+	}
+
+	for i, row := range t.Rows {
+		for len(row) < len(t.Columns) {
+			row = append(row, nil)
+		}
+		t.Rows[i] = row
+	}
+	return t
+}
+
+func (t *DataviewTable) addObjectRow(filename string, obj map[string]interface{}, keys []string, index map[string]int) {
+	row := make([]interface{}, len(t.Columns))
+	row[0] = filename
+	for _, k := range keys {
+		i, ok := index[k]
+		if !ok {
+			i = len(t.Columns)
+			index[k] = i
+			t.Columns = append(t.Columns, k)
+		}
+		for len(row) <= i {
+			row = append(row, nil)
+		}
+		row[i] = obj[k]
+	}
+	t.Rows = append(t.Rows, row)
+}
+
+// Markdown renders the table as a Markdown table.
+func (t *DataviewTable) Markdown() string {
+	if len(t.Rows) == 0 {
+		return "No results."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("|")
+	for _, c := range t.Columns {
+		sb.WriteString(" " + escapeMarkdownCell(c) + " |")
+	}
+	sb.WriteString("\n|")
+	for range t.Columns {
+		sb.WriteString(" --- |")
+	}
+	sb.WriteString("\n")
+
+	for _, row := range t.Rows {
+		sb.WriteString(MarkdownRow(row))
+	}
+	return sb.String()
+}
+
+// MarkdownRow renders a row of a table as a line of its Markdown table.
+func MarkdownRow(row []interface{}) string {
+	var sb strings.Builder
+	sb.WriteString("|")
+	for i, cell := range row {
+		value := formatDataviewValue(cell)
+		if i == 0 && value != "" {
+			value = "[[" + value + "]]"
+		}
+		sb.WriteString(" " + escapeMarkdownCell(value) + " |")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// ExplainDataviewError maps errors returned for DQL queries to messages that
+// explain how to fix the query. Errors it does not recognize are returned unchanged.
+func ExplainDataviewError(err error) error {
+	var apiErr *ErrorResponse
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	msg := strings.ToLower(apiErr.Message)
+	var hint string
+	switch {
+	case strings.Contains(msg, "without id"):
+		hint = "TABLE WITHOUT ID queries are not supported; remove WITHOUT ID so that each row keeps its file"
+	case strings.Contains(msg, "only table"):
+		hint = "only TABLE queries are supported; rewrite LIST or TASK queries as TABLE (e.g. 'TABLE file.mtime FROM \"folder\"')"
+	case strings.Contains(msg, dataviewParseError):
+		hint = "the query could not be parsed; check the keywords (TABLE, FROM, WHERE, SORT, LIMIT), " +
+			"quote folder names in FROM and separate fields with commas"
+	case strings.Contains(msg, "dataview"):
+		hint = "the Dataview plugin must be installed and enabled in Obsidian"
+	default:
+		return err
+	}
+	return fmt.Errorf("%w: %s (%s)", ErrDataview, hint, apiErr.Message)
+}
+
+// orderedKeys returns the keys of a JSON object in the order they appear in raw.
+// It falls back to sorted keys of obj if raw cannot be read.
+func orderedKeys(raw json.RawMessage, obj map[string]interface{}) []string {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return sortedKeys(obj)
+	}
+
+	keys := make([]string, 0, len(obj))
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return sortedKeys(obj)
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return sortedKeys(obj)
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return sortedKeys(obj)
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatDataviewValue renders a Dataview value (as decoded from JSON) as text.
+// Links are rendered as wikilinks and lists are joined with commas.
+func formatDataviewValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	case []interface{}:
+		parts := make([]string, 0, len(val))
+		for _, item := range val {
+			parts = append(parts, formatDataviewValue(item))
+		}
+		return strings.Join(parts, ", ")
+	case map[string]interface{}:
+		if path, ok := val["path"].(string); ok {
+			if display, ok := val["display"].(string); ok && display != "" {
+				return "[[" + path + "|" + display + "]]"
+			}
+			return "[[" + path + "]]"
+		}
+		if text, ok := val["text"].(string); ok {
+			return text
+		}
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(b)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,88 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDataviewQueryType(t *testing.T) {
+	tests := map[string]DataviewQueryType{
+		`TABLE file.mtime FROM "folder"`: DataviewQueryTable,
+		"  list FROM #tag":               DataviewQueryList,
+		"TASK WHERE !completed":          DataviewQueryTask,
+		"CALENDAR file.ctime":            DataviewQueryCalendar,
+		"":                               DataviewQueryUnknown,
+		"SELECT * FROM notes":            DataviewQueryUnknown,
+	}
+	for query, expected := range tests {
+		assert.Equal(t, expected, DetectDataviewQueryType(query), query)
+	}
+}
+
+func TestNormalizeDataview_Table(t *testing.T) {
+	var results []JSONLogicResult
+	err := json.Unmarshal([]byte(`[
+		{"filename": "a.md", "result": {"status": "done", "due": "2024-01-01", "owner": {"path": "People/Bob.md", "display": "Bob"}}},
+		{"filename": "b.md", "result": {"status": "open", "due": null, "extra": 3}}
+	]`), &results)
+	require.NoError(t, err)
+
+	table := NormalizeDataview("TABLE status, due, owner", results)
+	assert.Equal(t, DataviewQueryTable, table.Type)
+	assert.Equal(t, []string{"File", "status", "due", "owner", "extra"}, table.Columns)
+	require.Len(t, table.Rows, 2)
+	assert.Equal(t, []interface{}{"b.md", "open", nil, nil, float64(3)}, table.Rows[1])
+
+	md := table.Markdown()
+	assert.Contains(t, md, "| File | status | due | owner | extra |")
+	assert.Contains(t, md, `| [[a.md]] | done | 2024-01-01 | [[People/Bob.md\|Bob]] |  |`)
+}
+
+func TestNormalizeDataview_List(t *testing.T) {
+	results := []JSONLogicResult{
+		{Filename: "a.md", Result: []interface{}{"one", "two"}},
+		{Filename: "b.md", Result: nil},
+	}
+
+	table := NormalizeDataview("LIST", results)
+	assert.Equal(t, DataviewQueryList, table.Type)
+	assert.Equal(t, []string{"File", "Value"}, table.Columns)
+	assert.Equal(t, [][]interface{}{{"a.md", "one"}, {"a.md", "two"}, {"b.md", nil}}, table.Rows)
+	assert.Equal(t, "No results.", NormalizeDataview("LIST", nil).Markdown())
+}
+
+func TestClient_Search_DataviewTable_Error(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"errorCode": 40070, "message": "Only TABLE dataview queries are supported."}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	_, err = client.Search.DataviewTable(context.Background(), "LIST FROM \"folder\"")
+	require.ErrorIs(t, err, ErrDataview)
+	assert.Contains(t, err.Error(), "rewrite LIST or TASK queries as TABLE")
+}
+
+func TestExplainDataviewError(t *testing.T) {
+	parseErr := &ErrorResponse{ErrorCode: 40070, Message: "Error: \n-- PARSING FAILED --------------------------------------------------\n\n" +
+		"> 1 | TABLEE file.mtime\n    | ^\n\nExpected one of the following: \n\n'CALENDAR', 'LIST', 'TABLE', 'TASK'"}
+	err := ExplainDataviewError(parseErr)
+	require.ErrorIs(t, err, ErrDataview)
+	assert.Contains(t, err.Error(), "could not be parsed")
+
+	// Other errors that mention what was expected are not parse errors.
+	fieldErr := &ErrorResponse{ErrorCode: 40070, Message: "expected a number for LIMIT"}
+	assert.Equal(t, fieldErr, ExplainDataviewError(fieldErr))
+}
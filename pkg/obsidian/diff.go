@@ -0,0 +1,447 @@
+package obsidian
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrEditConflict is returned when an edit or a diff hunk does not match the content.
+var ErrEditConflict = errors.New("edit does not apply")
+
+// ErrInvalidDiff is returned when a unified diff cannot be parsed.
+var ErrInvalidDiff = errors.New("invalid unified diff")
+
+const noNewlineMarker = "\\ No newline at end of file"
+
+// Edit replaces the only occurrence of Search with Replace.
+type Edit struct {
+	Search  string `json:"search"`
+	Replace string `json:"replace"`
+}
+
+// ApplyEdits applies the edits to content in order. Each Search text must
+// occur exactly once in the content it is applied to; otherwise no edit is
+// applied and ErrEditConflict is returned.
+func ApplyEdits(content string, edits []Edit) (string, error) {
+	for i, e := range edits {
+		if e.Search == "" {
+			return "", fmt.Errorf("%w: edit %d: search text is empty", ErrEditConflict, i+1)
+		}
+		switch n := strings.Count(content, e.Search); n {
+		case 1:
+			content = strings.Replace(content, e.Search, e.Replace, 1)
+		case 0:
+			return "", fmt.Errorf("%w: edit %d: search text not found", ErrEditConflict, i+1)
+		default:
+			return "", fmt.Errorf("%w: edit %d: search text occurs %d times, add context to make it unique", ErrEditConflict, i+1, n)
+		}
+	}
+	return content, nil
+}
+
+// splitLines splits s into lines that keep their "\n". Only the last line
+// may lack it.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+type diffOp int
+
+const (
+	opEqual diffOp = iota
+	opDelete
+	opInsert
+)
+
+// diffLine is a line of the edit script that turns a into b.
+type diffLine struct {
+	op   diffOp
+	text string
+}
+
+// maxDiffEdits bounds the number of inserted and deleted lines that
+// diffLines searches for. The trace of Myers' algorithm takes memory
+// quadratic in this number; beyond it, the changed lines are replaced as a
+// whole.
+const maxDiffEdits = 1000
+
+// diffLines computes the shortest edit script from a to b with Myers'
+// algorithm, after skipping the lines that a and b start and end with. If
+// the rest differs by more than maxDiffEdits lines, it is deleted from a
+// and inserted from b in one change.
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	script := make([]diffLine, 0, len(a)+len(b)-prefix-suffix)
+	for _, l := range a[:prefix] {
+		script = append(script, diffLine{opEqual, l})
+	}
+	if middle, ok := myers(midA, midB); ok {
+		script = append(script, middle...)
+	} else {
+		for _, l := range midA {
+			script = append(script, diffLine{opDelete, l})
+		}
+		for _, l := range midB {
+			script = append(script, diffLine{opInsert, l})
+		}
+	}
+	for _, l := range a[len(a)-suffix:] {
+		script = append(script, diffLine{opEqual, l})
+	}
+	return script
+}
+
+// myers computes the shortest edit script from a to b, or returns false if
+// it has more than maxDiffEdits inserted and deleted lines.
+func myers(a, b []string) ([]diffLine, bool) {
+	n, m := len(a), len(b)
+	maxD := min(n+m, maxDiffEdits)
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3) //nolint:mnd
+	// trace[d] holds v[offset-d:offset+d+1] before step d: only those
+	// diagonals are read when backtracking from step d.
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, d), true
+			}
+		}
+	}
+	return nil, false
+}
+
+func backtrack(a, b []string, trace [][]int, d int) []diffLine {
+	var script []diffLine
+	x, y := len(a), len(b)
+	for ; d > 0; d-- {
+		v := trace[d]
+		// v starts at diagonal -d.
+		at := func(k int) int { return v[k+d] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			script = append(script, diffLine{opEqual, a[x]})
+		}
+		if x == prevX {
+			y--
+			script = append(script, diffLine{opInsert, b[y]})
+		} else {
+			x--
+			script = append(script, diffLine{opDelete, a[x]})
+		}
+	}
+	for x > 0 {
+		x--
+		script = append(script, diffLine{opEqual, a[x]})
+	}
+
+	slices.Reverse(script)
+	return script
+}
+
+// UnifiedDiff returns the unified diff between a and b with the given number
+// of context lines, or "" if they are equal.
+func UnifiedDiff(oldName, newName, a, b string, context int) string {
+	script := diffLines(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	for start := 0; start < len(script); {
+		// Find the next change.
+		for start < len(script) && script[start].op == opEqual {
+			start++
+		}
+		if start == len(script) {
+			break
+		}
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+		}
+
+		// Extend the hunk while changes are separated by at most 2*context equal lines.
+		end := start
+		for end < len(script) {
+			if script[end].op != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(script) && script[run].op == opEqual {
+				run++
+			}
+			if run == len(script) || run-end > 2*context {
+				break
+			}
+			end = run
+		}
+
+		from := max(start-context, 0)
+		to := min(end+context, len(script))
+		writeHunk(&out, script, from, to)
+		start = to
+	}
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, script []diffLine, from, to int) {
+	// Line numbers of the first line of the hunk in a and b.
+	oldLine, newLine := 1, 1
+	for _, l := range script[:from] {
+		if l.op != opInsert {
+			oldLine++
+		}
+		if l.op != opDelete {
+			newLine++
+		}
+	}
+	oldCount, newCount := 0, 0
+	for _, l := range script[from:to] {
+		if l.op != opInsert {
+			oldCount++
+		}
+		if l.op != opDelete {
+			newCount++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+
+	prefixes := [...]string{opEqual: " ", opDelete: "-", opInsert: "+"}
+	for _, l := range script[from:to] {
+		out.WriteString(prefixes[l.op])
+		out.WriteString(l.text)
+		if !strings.HasSuffix(l.text, "\n") {
+			out.WriteString("\n" + noNewlineMarker + "\n")
+		}
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// An empty range names the line before it.
+		start--
+	}
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// hunk is a parsed hunk of a unified diff.
+type hunk struct {
+	oldStart int
+	oldLines []string
+	newLines []string
+}
+
+// ApplyUnifiedDiff applies a unified diff to content. Hunks are matched
+// exactly, anywhere after the previous hunk; if they match more than once,
+// the match closest to the position in their header is used. If any hunk
+// does not match, no hunk is applied and ErrEditConflict is returned.
+func ApplyUnifiedDiff(content, diff string) (string, error) {
+	hunks, err := parseUnifiedDiff(diff)
+	if err != nil {
+		return "", err
+	}
+
+	lines := splitLines(content)
+	var result []string
+	pos := 0
+	for i, h := range hunks {
+		at, ok := findHunk(lines, h, pos)
+		if !ok {
+			return "", fmt.Errorf("%w: hunk %d (@@ -%d) does not match the content", ErrEditConflict, i+1, h.oldStart)
+		}
+		result = append(result, lines[pos:at]...)
+		result = append(result, h.newLines...)
+		pos = at + len(h.oldLines)
+	}
+	result = append(result, lines[pos:]...)
+	return strings.Join(result, ""), nil
+}
+
+// findHunk returns the index of the line where the old lines of h match,
+// at or after pos, preferring the match closest to the position in its header.
+func findHunk(lines []string, h hunk, pos int) (int, bool) {
+	expected := h.oldStart - 1
+	if len(h.oldLines) == 0 {
+		// Pure insertion: oldStart names the line after which to insert.
+		expected = h.oldStart
+	}
+	best, found := 0, false
+	for at := pos; at+len(h.oldLines) <= len(lines); at++ {
+		if !slices.Equal(lines[at:at+len(h.oldLines)], h.oldLines) {
+			continue
+		}
+		if !found || abs(at-expected) < abs(best-expected) {
+			best, found = at, true
+		}
+	}
+	return best, found
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func parseUnifiedDiff(diff string) ([]hunk, error) {
+	var hunks []hunk
+	var cur *hunk
+	// Lines of the current hunk that are still expected according to its header.
+	oldLeft, newLeft := 0, 0
+	// lastOp is the kind of the previous line, so that a
+	// "\ No newline at end of file" marker can strip its newline.
+	var lastOp byte
+
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		if oldLeft == 0 && newLeft == 0 {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				start, oldCount, newCount, err := parseHunkHeader(line)
+				if err != nil {
+					return nil, err
+				}
+				hunks = append(hunks, hunk{oldStart: start})
+				cur = &hunks[len(hunks)-1]
+				oldLeft, newLeft = oldCount, newCount
+			case strings.HasPrefix(line, "\\"):
+				stripNewline(cur, lastOp)
+			case strings.HasPrefix(line, "+++ ") && len(hunks) > 0:
+				return nil, fmt.Errorf("%w: the diff changes more than one file", ErrInvalidDiff)
+			}
+			// Anything else between hunks is a file header or commentary.
+			continue
+		}
+
+		op := byte(' ')
+		if line != "" {
+			op = line[0]
+		}
+		text := ""
+		if line != "" {
+			text = line[1:]
+		}
+		text += "\n"
+
+		switch op {
+		case ' ':
+			cur.oldLines = append(cur.oldLines, text)
+			cur.newLines = append(cur.newLines, text)
+			oldLeft--
+			newLeft--
+		case '-':
+			cur.oldLines = append(cur.oldLines, text)
+			oldLeft--
+		case '+':
+			cur.newLines = append(cur.newLines, text)
+			newLeft--
+		case '\\':
+			stripNewline(cur, lastOp)
+			continue
+		default:
+			return nil, fmt.Errorf("%w: unexpected line %q", ErrInvalidDiff, line)
+		}
+		if oldLeft < 0 || newLeft < 0 {
+			return nil, fmt.Errorf("%w: hunk %d is longer than its header says", ErrInvalidDiff, len(hunks))
+		}
+		lastOp = op
+	}
+
+	if oldLeft > 0 || newLeft > 0 {
+		return nil, fmt.Errorf("%w: hunk %d is shorter than its header says", ErrInvalidDiff, len(hunks))
+	}
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("%w: no hunks found", ErrInvalidDiff)
+	}
+	return hunks, nil
+}
+
+// stripNewline removes the newline of the last line of h that op added.
+func stripNewline(h *hunk, op byte) {
+	if h == nil {
+		return
+	}
+	trim := func(lines []string) {
+		if len(lines) > 0 {
+			lines[len(lines)-1] = strings.TrimSuffix(lines[len(lines)-1], "\n")
+		}
+	}
+	if op == ' ' || op == '-' {
+		trim(h.oldLines)
+	}
+	if op == ' ' || op == '+' {
+		trim(h.newLines)
+	}
+}
+
+// parseHunkHeader parses "@@ -oldStart[,oldCount] +newStart[,newCount] @@".
+func parseHunkHeader(line string) (int, int, int, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[0] != "@@" || fields[3] != "@@" || //nolint:mnd
+		!strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, fmt.Errorf("%w: bad hunk header %q", ErrInvalidDiff, line)
+	}
+	oldStart, oldCount, err1 := parseHunkRange(fields[1][1:])
+	_, newCount, err2 := parseHunkRange(fields[2][1:])
+	if err1 != nil || err2 != nil {
+		return 0, 0, 0, fmt.Errorf("%w: bad hunk header %q", ErrInvalidDiff, line)
+	}
+	return oldStart, oldCount, newCount, nil
+}
+
+func parseHunkRange(s string) (int, int, error) {
+	startStr, countStr, hasCount := strings.Cut(s, ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !hasCount {
+		return start, 1, nil
+	}
+	count, err := strconv.Atoi(countStr)
+	return start, count, err
+}
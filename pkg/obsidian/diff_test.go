@@ -0,0 +1,99 @@
+package obsidian
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyEdits(t *testing.T) {
+	content := "# Plan\n\n- [ ] one\n- [ ] two\n"
+
+	got, err := ApplyEdits(content, []Edit{
+		{Search: "- [ ] one", Replace: "- [x] one"},
+		{Search: "# Plan", Replace: "# Weekly plan"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "# Weekly plan\n\n- [x] one\n- [ ] two\n", got)
+
+	_, err = ApplyEdits(content, []Edit{{Search: "- [ ]", Replace: "- [x]"}})
+	assert.ErrorIs(t, err, ErrEditConflict)
+	assert.Contains(t, err.Error(), "occurs 2 times")
+
+	_, err = ApplyEdits(content, []Edit{{Search: "three", Replace: "3"}})
+	assert.ErrorIs(t, err, ErrEditConflict)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	b := "one\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven"
+
+	diff := UnifiedDiff("a/note.md", "b/note.md", a, b, 2)
+	assert.Equal(t, "--- a/note.md\n+++ b/note.md\n"+
+		"@@ -1,4 +1,4 @@\n one\n-two\n+2\n three\n four\n"+
+		"@@ -9,2 +9,3 @@\n nine\n ten\n+eleven\n\\ No newline at end of file\n", diff)
+
+	assert.Empty(t, UnifiedDiff("a", "b", a, a, 3))
+}
+
+func TestApplyUnifiedDiff_RoundTrip(t *testing.T) {
+	cases := []struct{ a, b string }{
+		{"one\ntwo\nthree\n", "one\n2\nthree\n"},
+		{"", "new\n"},
+		{"gone\n", ""},
+		{"a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n", "x\na\nb\nc\nd\ne\nf\nh\ni\nj\nk\ny\n"},
+		{"no newline", "no newline\n"},
+		{"keep\nend", "keep\nEND"},
+	}
+	for _, tc := range cases {
+		diff := UnifiedDiff("a", "b", tc.a, tc.b, 3)
+		got, err := ApplyUnifiedDiff(tc.a, diff)
+		require.NoError(t, err, diff)
+		assert.Equal(t, tc.b, got, diff)
+	}
+}
+
+func TestUnifiedDiff_Rewrite(t *testing.T) {
+	// More changed lines than maxDiffEdits are replaced in one hunk.
+	var a, b strings.Builder
+	for i := range 2 * maxDiffEdits {
+		fmt.Fprintf(&a, "old %d\n", i)
+		fmt.Fprintf(&b, "new %d\n", i)
+	}
+	content := "# Title\n" + a.String() + "end\n"
+	rewritten := "# Title\n" + b.String() + "end\n"
+
+	diff := UnifiedDiff("a", "b", content, rewritten, 1)
+	assert.Equal(t, 1, strings.Count(diff, "@@ -"))
+	assert.True(t, strings.HasPrefix(diff, "--- a\n+++ b\n@@ -1,2002 +1,2002 @@\n # Title\n-old 0\n"))
+	got, err := ApplyUnifiedDiff(content, diff)
+	require.NoError(t, err)
+	assert.Equal(t, rewritten, got)
+}
+
+func TestApplyUnifiedDiff_Offset(t *testing.T) {
+	// The hunk says line 2, but two lines were added at the top since.
+	content := "new\nnew\none\ntwo\nthree\n"
+	diff := "@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n"
+
+	got, err := ApplyUnifiedDiff(content, diff)
+	require.NoError(t, err)
+	assert.Equal(t, "new\nnew\none\n2\nthree\n", got)
+}
+
+func TestApplyUnifiedDiff_Conflict(t *testing.T) {
+	content := "one\ntwo\nthree\n"
+	diff := "--- a\n+++ b\n@@ -1,2 +1,2 @@\n one\n-2\n+two\n@@ -3 +3 @@\n-three\n+3\n"
+
+	_, err := ApplyUnifiedDiff(content, diff)
+	assert.ErrorIs(t, err, ErrEditConflict)
+
+	_, err = ApplyUnifiedDiff(content, "@@ -1,3 +1,1 @@\n one\n")
+	assert.ErrorIs(t, err, ErrInvalidDiff)
+
+	_, err = ApplyUnifiedDiff(content, "just text")
+	assert.ErrorIs(t, err, ErrInvalidDiff)
+}
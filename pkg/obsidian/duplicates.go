@@ -0,0 +1,349 @@
+package obsidian
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Defaults for DuplicateOptions.
+const (
+	DefaultDuplicateThreshold = 0.8
+	DefaultShingleSize        = 5
+	DefaultMinWords           = 10
+)
+
+// MinHash signatures have minHashBands*minHashRows values. With 32 bands of
+// 4 rows, pairs with a similarity of 0.5 become candidates with a
+// probability of 87%, and pairs above 0.7 with more than 99.9%. Below
+// minHashThreshold, most pairs would be missed, so all pairs are compared.
+const (
+	minHashBands     = 32
+	minHashRows      = 4
+	minHashThreshold = 0.5
+)
+
+// DuplicateOptions configures FindDuplicates.
+type DuplicateOptions struct {
+	// Folder limits the analysis to notes in the folder and its subfolders.
+	Folder string
+	// Threshold is the minimum similarity (0-1) of near-duplicates.
+	// Similarity is the Jaccard index of the sets of word shingles.
+	Threshold float64
+	// ShingleSize is the number of words in a shingle.
+	ShingleSize int
+	// MinWords skips notes with fewer words, which are too short to compare.
+	MinWords int
+}
+
+func (o *DuplicateOptions) setDefaults() {
+	if o.Threshold <= 0 || o.Threshold > 1 {
+		o.Threshold = DefaultDuplicateThreshold
+	}
+	if o.ShingleSize <= 0 {
+		o.ShingleSize = DefaultShingleSize
+	}
+	if o.MinWords <= 0 {
+		o.MinWords = DefaultMinWords
+	}
+}
+
+// Fingerprint summarizes the content of a note for duplicate detection.
+type Fingerprint struct {
+	// Hash is the SHA-256 of the normalized content.
+	Hash string
+	// Words is the number of words in the normalized content.
+	Words    int
+	shingles map[uint64]struct{}
+}
+
+// NormalizeContent prepares note content for comparison: the frontmatter is
+// removed, letters are lowercased, and punctuation and whitespace are
+// collapsed to single spaces.
+func NormalizeContent(content string) string {
+	_, body := SplitFrontmatter(content)
+	return strings.Join(strings.FieldsFunc(strings.ToLower(body), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// NewFingerprint fingerprints the content of a note with shingles of
+// shingleSize words.
+func NewFingerprint(content string, shingleSize int) Fingerprint {
+	normalized := NormalizeContent(content)
+	sum := sha256.Sum256([]byte(normalized))
+	words := strings.Fields(normalized)
+
+	fp := Fingerprint{
+		Hash:     hex.EncodeToString(sum[:]),
+		Words:    len(words),
+		shingles: make(map[uint64]struct{}),
+	}
+	if len(words) < shingleSize {
+		shingleSize = len(words)
+	}
+	for i := 0; i+shingleSize <= len(words) && shingleSize > 0; i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+shingleSize], " ")))
+		fp.shingles[h.Sum64()] = struct{}{}
+	}
+	return fp
+}
+
+// Similarity returns the Jaccard index of the shingle sets of two fingerprints.
+func (f *Fingerprint) Similarity(other *Fingerprint) float64 {
+	if len(f.shingles) == 0 || len(other.shingles) == 0 {
+		return 0
+	}
+	small, large := f.shingles, other.shingles
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	common := 0
+	for s := range small {
+		if _, ok := large[s]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(f.shingles)+len(other.shingles)-common)
+}
+
+// minHash returns the MinHash signature of the shingles.
+func (f *Fingerprint) minHash() []uint64 {
+	sig := make([]uint64, minHashBands*minHashRows)
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for s := range f.shingles {
+		for i := range sig {
+			if h := mix64(s ^ (uint64(i+1) * 0x9e3779b97f4a7c15)); h < sig[i] { //nolint:mnd // golden ratio seed
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// mix64 is the finalizer of MurmurHash3, used as a family of hash functions.
+func mix64(x uint64) uint64 {
+	x ^= x >> 33            //nolint:mnd
+	x *= 0xff51afd7ed558ccd //nolint:mnd
+	x ^= x >> 33            //nolint:mnd
+	x *= 0xc4ceb9fe1a85ec53 //nolint:mnd
+	x ^= x >> 33            //nolint:mnd
+	return x
+}
+
+// DuplicateFile is a note in a duplicate cluster.
+type DuplicateFile struct {
+	Path  string `json:"path"`
+	Words int    `json:"words"`
+}
+
+// DuplicateCluster is a group of notes that are duplicates of each other.
+type DuplicateCluster struct {
+	Files []DuplicateFile `json:"files"`
+	// Exact is true if all notes have the same normalized content.
+	Exact bool `json:"exact"`
+	// Similarity is the lowest similarity of the pairs that joined the cluster.
+	Similarity float64 `json:"similarity"`
+}
+
+// DuplicateReport is the result of FindDuplicates.
+type DuplicateReport struct {
+	// Scanned is the number of notes that were compared.
+	Scanned  int                `json:"scanned"`
+	Clusters []DuplicateCluster `json:"clusters"`
+	// Failed lists notes that could not be read.
+	Failed []ReadError `json:"failed,omitempty"`
+}
+
+// FindDuplicates reads the notes of the vault (or of opts.Folder) and
+// reports clusters of duplicate and near-duplicate notes.
+func (s *AnalysisService) FindDuplicates(ctx context.Context, opts DuplicateOptions) (*DuplicateReport, error) {
+	paths, err := s.markdownFiles(ctx, opts.Folder)
+	if err != nil {
+		return nil, err
+	}
+	contents, failed, err := readAll(ctx, paths, s.client.Vault.Get)
+	if err != nil {
+		return nil, err
+	}
+
+	report := ClusterDuplicates(contents, opts)
+	report.Failed = failed
+	return report, nil
+}
+
+// ClusterDuplicates finds duplicate clusters among notes, given as a map
+// from path to content.
+func ClusterDuplicates(notes map[string]string, opts DuplicateOptions) *DuplicateReport {
+	opts.setDefaults()
+
+	paths := make([]string, 0, len(notes))
+	fps := make([]Fingerprint, 0, len(notes))
+	for _, p := range slices.Sorted(maps.Keys(notes)) {
+		fp := NewFingerprint(notes[p], opts.ShingleSize)
+		if fp.Words < opts.MinWords {
+			continue
+		}
+		paths = append(paths, p)
+		fps = append(fps, fp)
+	}
+
+	uf := newUnionFind(len(paths))
+	// Lowest similarity of the pairs joined into each cluster, by root.
+	minSim := make(map[int]float64)
+	join := func(i, j int, sim float64) {
+		ri, rj := uf.find(i), uf.find(j)
+		lowest := sim
+		for _, r := range []int{ri, rj} {
+			if v, ok := minSim[r]; ok && v < lowest {
+				lowest = v
+			}
+		}
+		if ri != rj {
+			uf.union(ri, rj)
+		}
+		minSim[uf.find(i)] = lowest
+	}
+
+	// Exact duplicates.
+	byHash := make(map[string]int)
+	for i := range fps {
+		if first, ok := byHash[fps[i].Hash]; ok {
+			join(first, i, 1)
+		} else {
+			byHash[fps[i].Hash] = i
+		}
+	}
+
+	// Near-duplicates: notes that share a MinHash band are candidates, and
+	// candidates are confirmed with their exact similarity.
+	compare := func(i, j int) {
+		if fps[i].Hash == fps[j].Hash {
+			return
+		}
+		if sim := fps[i].Similarity(&fps[j]); sim >= opts.Threshold {
+			join(i, j, sim)
+		}
+	}
+	if opts.Threshold < minHashThreshold {
+		for i := range fps {
+			for j := i + 1; j < len(fps); j++ {
+				compare(i, j)
+			}
+		}
+	} else {
+		checked := make(map[[2]int]bool)
+		for _, bucket := range lshBuckets(fps) {
+			for a := 0; a < len(bucket); a++ {
+				for b := a + 1; b < len(bucket); b++ {
+					i, j := bucket[a], bucket[b]
+					if !checked[[2]int{i, j}] {
+						checked[[2]int{i, j}] = true
+						compare(i, j)
+					}
+				}
+			}
+		}
+	}
+
+	return &DuplicateReport{
+		Scanned:  len(paths),
+		Clusters: buildClusters(uf, paths, fps, minSim),
+	}
+}
+
+// lshBuckets groups notes whose MinHash signatures are equal in at least one band.
+func lshBuckets(fps []Fingerprint) [][]int {
+	buckets := make(map[[2]uint64][]int)
+	for i := range fps {
+		sig := fps[i].minHash()
+		for band := range minHashBands {
+			h := fnv.New64a()
+			var buf [8]byte
+			for _, v := range sig[band*minHashRows : (band+1)*minHashRows] {
+				binary.LittleEndian.PutUint64(buf[:], v)
+				h.Write(buf[:])
+			}
+			key := [2]uint64{uint64(band), h.Sum64()}
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	var out [][]int
+	for _, b := range buckets {
+		if len(b) > 1 {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+func buildClusters(uf *unionFind, paths []string, fps []Fingerprint, minSim map[int]float64) []DuplicateCluster {
+	members := make(map[int][]int)
+	for i := range paths {
+		root := uf.find(i)
+		members[root] = append(members[root], i)
+	}
+
+	clusters := []DuplicateCluster{}
+	for root, idx := range members {
+		if len(idx) < 2 { //nolint:mnd
+			continue
+		}
+		c := DuplicateCluster{Exact: true, Similarity: minSim[root]}
+		for _, i := range idx {
+			c.Files = append(c.Files, DuplicateFile{Path: paths[i], Words: fps[i].Words})
+			if fps[i].Hash != fps[idx[0]].Hash {
+				c.Exact = false
+			}
+		}
+		sort.Slice(c.Files, func(a, b int) bool { return c.Files[a].Path < c.Files[b].Path })
+		clusters = append(clusters, c)
+	}
+
+	sort.Slice(clusters, func(a, b int) bool {
+		if len(clusters[a].Files) != len(clusters[b].Files) {
+			return len(clusters[a].Files) > len(clusters[b].Files)
+		}
+		return clusters[a].Files[0].Path < clusters[b].Files[0].Path
+	})
+	return clusters
+}
+
+// unionFind is a disjoint-set forest over 0..n-1.
+type unionFind struct {
+	parent []int
+}
+
+func newUnionFind(n int) *unionFind {
+	uf := &unionFind{parent: make([]int, n)}
+	for i := range uf.parent {
+		uf.parent[i] = i
+	}
+	return uf
+}
+
+func (uf *unionFind) find(i int) int {
+	for uf.parent[i] != i {
+		uf.parent[i] = uf.parent[uf.parent[i]]
+		i = uf.parent[i]
+	}
+	return i
+}
+
+func (uf *unionFind) union(i, j int) {
+	ri, rj := uf.find(i), uf.find(j)
+	if ri != rj {
+		uf.parent[rj] = ri
+	}
+}
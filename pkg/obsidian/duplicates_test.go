@@ -0,0 +1,138 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testNoteText = "The quarterly planning meeting covered the roadmap for the mobile app, " +
+		"the hiring plan for the platform team, and the budget for the conference season. " +
+		"Action items were assigned to each team lead with a deadline at the end of the month."
+	testOtherText = "Sourdough bread needs a lively starter, strong flour, water and salt. " +
+		"Mix the dough, let it rest, fold it every half hour and bake it in a hot dutch oven " +
+		"until the crust is deep brown and the loaf sounds hollow."
+)
+
+func TestNormalizeContent(t *testing.T) {
+	content := "---\ntags: [x]\n---\n# Meeting Notes\n\n- Discussed *the* plan, again!\n"
+	assert.Equal(t, "meeting notes discussed the plan again", NormalizeContent(content))
+}
+
+func TestFingerprint_Similarity(t *testing.T) {
+	a := NewFingerprint(testNoteText, DefaultShingleSize)
+	b := NewFingerprint(testNoteText, DefaultShingleSize)
+	c := NewFingerprint(testOtherText, DefaultShingleSize)
+
+	assert.Equal(t, a.Hash, b.Hash)
+	assert.InDelta(t, 1.0, a.Similarity(&b), 1e-9)
+	assert.InDelta(t, 0.0, a.Similarity(&c), 1e-9)
+}
+
+func TestClusterDuplicates(t *testing.T) {
+	edited := strings.Replace(testNoteText, "end of the month", "end of the quarter", 1)
+	notes := map[string]string{
+		"Meetings/Planning.md":      testNoteText,
+		"Inbox/Planning copy.md":    "---\ncreated: 2024-01-01\n---\n" + strings.ToUpper(testNoteText),
+		"Archive/Planning (old).md": edited,
+		"Recipes/Bread.md":          testOtherText,
+		"Short A.md":                "Hello world",
+		"Short B.md":                "Hello world",
+	}
+
+	report := ClusterDuplicates(notes, DuplicateOptions{})
+	assert.Equal(t, 4, report.Scanned, "short notes are skipped")
+	require.Len(t, report.Clusters, 1)
+
+	cluster := report.Clusters[0]
+	assert.False(t, cluster.Exact)
+	assert.Greater(t, cluster.Similarity, DefaultDuplicateThreshold)
+	assert.Less(t, cluster.Similarity, 1.0)
+	var paths []string
+	for _, f := range cluster.Files {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"Archive/Planning (old).md", "Inbox/Planning copy.md", "Meetings/Planning.md"}, paths)
+
+	// A threshold above the similarity of the edited copy leaves the exact duplicates.
+	report = ClusterDuplicates(notes, DuplicateOptions{Threshold: 0.99})
+	require.Len(t, report.Clusters, 1)
+	assert.True(t, report.Clusters[0].Exact)
+	assert.Len(t, report.Clusters[0].Files, 2)
+
+	// Lowering MinWords includes the short notes.
+	report = ClusterDuplicates(notes, DuplicateOptions{Threshold: 0.99, MinWords: 1})
+	assert.Len(t, report.Clusters, 2)
+}
+
+func TestClusterDuplicates_LowThreshold(t *testing.T) {
+	// Each pair shares 17 of 30 words, a similarity of about 0.33, which
+	// MinHash banding would mostly miss.
+	notes := map[string]string{}
+	for n := range 5 {
+		var shared, a, b []string
+		for i := range 17 {
+			shared = append(shared, fmt.Sprintf("shared%c%c", 'a'+n, 'a'+i))
+		}
+		for i := range 13 {
+			a = append(a, fmt.Sprintf("first%c%c", 'a'+n, 'a'+i))
+			b = append(b, fmt.Sprintf("second%c%c", 'a'+n, 'a'+i))
+		}
+		notes[fmt.Sprintf("%d a.md", n)] = strings.Join(append(shared, a...), " ")
+		notes[fmt.Sprintf("%d b.md", n)] = strings.Join(append(shared, b...), " ")
+	}
+
+	report := ClusterDuplicates(notes, DuplicateOptions{Threshold: 0.3})
+	require.Len(t, report.Clusters, 5)
+	for _, c := range report.Clusters {
+		assert.Less(t, c.Similarity, 0.4)
+		assert.Len(t, c.Files, 2)
+	}
+}
+
+func TestAnalysis_FindDuplicates(t *testing.T) {
+	listings := map[string][]string{
+		"/vault/Notes/":     {"a.md", "b.md", "Sub/", "image.png"},
+		"/vault/Notes/Sub/": {"c.md", "broken.md"},
+	}
+	files := map[string]string{
+		"/vault/Notes/a.md":     testNoteText,
+		"/vault/Notes/b.md":     testOtherText,
+		"/vault/Notes/Sub/c.md": testNoteText,
+	}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if entries, ok := listings[r.URL.Path]; ok {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string][]string{"files": entries})
+			return
+		}
+		if content, ok := files[r.URL.Path]; ok {
+			fmt.Fprint(w, content)
+			return
+		}
+		assert.NotEqual(t, "/vault/Notes/image.png", r.URL.Path, "only notes are read")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errorCode": 40400, "message": "Not Found"}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	report, err := client.Analysis.FindDuplicates(context.Background(), DuplicateOptions{Folder: "Notes"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Scanned)
+	require.Len(t, report.Clusters, 1)
+	assert.True(t, report.Clusters[0].Exact)
+	assert.Equal(t, []DuplicateFile{{Path: "Notes/Sub/c.md", Words: 42}, {Path: "Notes/a.md", Words: 42}}, report.Clusters[0].Files)
+	require.Len(t, report.Failed, 1)
+	assert.Equal(t, "Notes/Sub/broken.md", report.Failed[0].Path)
+}
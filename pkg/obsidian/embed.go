@@ -0,0 +1,345 @@
+package obsidian
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultEmbedDepth is the number of levels of nested embeds that are
+// expanded by default.
+const DefaultEmbedDepth = 3
+
+// MaxEmbedDepth is the largest number of levels of nested embeds that are
+// expanded.
+const MaxEmbedDepth = 10
+
+// DefaultEmbedSize is the number of bytes of embedded content that are
+// expanded by default.
+const DefaultEmbedSize = 1 << 20
+
+// ErrEmbedNotFound is returned when an embedded note does not exist.
+var ErrEmbedNotFound = errors.New("embedded note not found")
+
+// ErrEmbedSubpath is returned when the heading or block of an embed does
+// not exist in the embedded note.
+var ErrEmbedSubpath = errors.New("embedded heading or block not found")
+
+// ErrEmbedCycle is returned when a note embeds itself, directly or through
+// other notes.
+var ErrEmbedCycle = errors.New("embed cycle")
+
+// ErrEmbedDepth is returned for embeds nested deeper than the depth limit.
+var ErrEmbedDepth = errors.New("embed depth limit reached")
+
+// ErrEmbedSize is returned for embeds that would expand more content than
+// the size limit.
+var ErrEmbedSize = errors.New("embed size limit reached")
+
+// Embed is a note embed ("![[Note#Heading]]") found while expanding embeds.
+type Embed struct {
+	// Target is the embedded note and subpath as written, without alias.
+	Target string `json:"target"`
+	// Path is the path of the embedded note, if it exists.
+	Path string `json:"path,omitempty"`
+	// Depth is 1 for the embeds of the expanded note, 2 for the embeds of
+	// the notes it embeds, and so on.
+	Depth int `json:"depth"`
+	// Error tells why the embed was left as it is.
+	Error string `json:"error,omitempty"`
+}
+
+// EmbedResolver expands the note embeds of notes with the content they
+// embed, as Obsidian renders them: a whole note ("![[Note]]"), the section
+// of a heading ("![[Note#Heading]]") or a block ("![[Note#^id]]"). It
+// caches the notes it reads, and is not safe for concurrent use.
+type EmbedResolver struct {
+	// MaxDepth is the number of levels of nested embeds that are expanded.
+	MaxDepth int
+	// MaxSize is the number of bytes of embedded content that one call
+	// expands. Embeds are nested, so a note that embeds a few notes that
+	// embed a few notes each would otherwise grow exponentially.
+	MaxSize int
+
+	index *LinkIndex
+	read  func(ctx context.Context, path string) (string, error)
+	// cache maps paths to the content of the notes that were read.
+	cache map[string]string
+	// size is the number of bytes embedded by the current call.
+	size int
+}
+
+// NewEmbedResolver returns a resolver that resolves embeds against the files
+// of the vault and reads the embedded notes with read.
+func NewEmbedResolver(files []string, read func(ctx context.Context, path string) (string, error)) *EmbedResolver {
+	return &EmbedResolver{
+		MaxDepth: DefaultEmbedDepth,
+		MaxSize:  DefaultEmbedSize,
+		index:    NewLinkIndex(files),
+		read:     read,
+		cache:    make(map[string]string),
+	}
+}
+
+// EmbedResolver returns a resolver for the embeds of the notes in the vault.
+func (s *VaultService) EmbedResolver(ctx context.Context) (*EmbedResolver, error) {
+	files, err := s.ListRecursive(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return NewEmbedResolver(files, s.Get), nil
+}
+
+// Expand replaces the note embeds in content, the body of the note at
+// source, with what they embed, recursively up to MaxDepth levels and
+// MaxSize bytes. Embeds of attachments are left as they are, and so are note
+// embeds that cannot be expanded; all note embeds are reported, in order.
+func (r *EmbedResolver) Expand(ctx context.Context, source, content string) (string, []Embed, error) {
+	r.size = 0
+	var embeds []Embed
+	expanded, err := r.expand(ctx, source, content, []string{embedKey(source, "")}, &embeds)
+	if err != nil {
+		return "", nil, err
+	}
+	return expanded, embeds, nil
+}
+
+// Embedded returns the content of a note embed in the note at source, with
+// its own embeds expanded, and the path of the embedded note.
+func (r *EmbedResolver) Embedded(ctx context.Context, link Link, source string) (string, string, error) {
+	target, ok := r.index.Resolve(link, source)
+	if !ok || !isMarkdown(target) {
+		return "", "", fmt.Errorf("%w: %s", ErrEmbedNotFound, embedTarget(link))
+	}
+	r.size = 0
+	var embeds []Embed
+	content, err := r.section(ctx, link, target, []string{embedKey(source, "")}, &embeds)
+	return content, target, err
+}
+
+// expand expands the embeds in content. chain holds the keys of the
+// embeds being expanded, starting with the expanded note.
+func (r *EmbedResolver) expand(ctx context.Context, source, content string, chain []string, embeds *[]Embed) (string, error) {
+	lines := strings.Split(content, "\n")
+	inFence := false
+	for i, line := range lines {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.Contains(line, "![[") {
+			continue
+		}
+		expanded, err := r.expandLine(ctx, source, line, chain, embeds)
+		if err != nil {
+			return "", err
+		}
+		lines[i] = expanded
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (r *EmbedResolver) expandLine(ctx context.Context, source, line string, chain []string, embeds *[]Embed) (string, error) {
+	var sb strings.Builder
+	last := 0
+	for i := 0; i < len(line); i++ {
+		if line[i] == '`' {
+			i = codeSpanEnd(line, i)
+			continue
+		}
+		if !strings.HasPrefix(line[i:], "![[") {
+			continue
+		}
+		end := strings.Index(line[i+len("![["):], "]]")
+		if end < 0 {
+			break
+		}
+		next := i + len("![[") + end + len("]]")
+		link := parseWikilink(line[i+len("![[") : next-len("]]")])
+		link.Embed = true
+
+		content, ok, err := r.embed(ctx, source, link, chain, embeds)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			sb.WriteString(line[last:i])
+			sb.WriteString(content)
+			last = next
+		}
+		i = next - 1
+	}
+	sb.WriteString(line[last:])
+	return sb.String(), nil
+}
+
+// embed returns the expanded content of an embed, or false if the embed
+// is left as it is.
+func (r *EmbedResolver) embed(ctx context.Context, source string, link Link, chain []string, embeds *[]Embed) (string, bool, error) {
+	target, ok := r.index.Resolve(link, source)
+	if ok && !isMarkdown(target) {
+		return "", false, nil
+	}
+	i := len(*embeds)
+	*embeds = append(*embeds, Embed{Target: embedTarget(link), Path: target, Depth: len(chain)})
+
+	var content string
+	var err error
+	if !ok {
+		err = ErrEmbedNotFound
+	} else {
+		content, err = r.section(ctx, link, target, chain, embeds)
+	}
+	if err == nil {
+		return content, true, nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return "", false, ctxErr
+	}
+	(*embeds)[i].Error = err.Error()
+	return "", false, nil
+}
+
+// section returns the note, heading section or block that link embeds from
+// the note at target, with its embeds expanded.
+func (r *EmbedResolver) section(ctx context.Context, link Link, target string, chain []string, embeds *[]Embed) (string, error) {
+	if len(chain) > r.MaxDepth {
+		return "", ErrEmbedDepth
+	}
+	key := embedKey(target, link.Subpath)
+	if slices.Contains(chain, key) {
+		return "", ErrEmbedCycle
+	}
+	body, err := r.note(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	content, ok := embedSection(body, link.Subpath)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrEmbedSubpath, link.Subpath)
+	}
+	// The expanded content is made of the sections, so counting them
+	// limits its size.
+	if r.size+len(content) > r.MaxSize {
+		return "", ErrEmbedSize
+	}
+	r.size += len(content)
+	return r.expand(ctx, target, content, append(slices.Clip(chain), key), embeds)
+}
+
+// note returns the body of a note, without frontmatter.
+func (r *EmbedResolver) note(ctx context.Context, notePath string) (string, error) {
+	content, ok := r.cache[notePath]
+	if !ok {
+		if r.read == nil {
+			return "", ErrEmbedNotFound
+		}
+		var err error
+		content, err = r.read(ctx, notePath)
+		if IsNotFound(err) {
+			return "", ErrEmbedNotFound
+		}
+		if err != nil {
+			return "", err
+		}
+		r.cache[notePath] = content
+	}
+	_, body := SplitFrontmatter(content)
+	return body, nil
+}
+
+func embedKey(notePath, subpath string) string {
+	return notePath + "#" + strings.ToLower(subpath)
+}
+
+func embedTarget(link Link) string {
+	if link.Subpath == "" {
+		return link.Target
+	}
+	return link.Target + "#" + link.Subpath
+}
+
+// embedSection returns the part of a note body that an embed with subpath
+// shows: the whole body, the section of a heading up to the next heading
+// of the same or a higher level, or a block.
+func embedSection(body, subpath string) (string, bool) {
+	if subpath == "" {
+		return strings.TrimRight(body, "\n"), true
+	}
+	if id, ok := strings.CutPrefix(subpath, "^"); ok {
+		return embedBlock(body, id)
+	}
+
+	// In "H1#H2", the last heading is the one that is embedded.
+	parts := strings.Split(subpath, "#")
+	want := strings.TrimSpace(parts[len(parts)-1])
+	lines := strings.Split(body, "\n")
+	headings := ParseHeadings(body)
+	for i, h := range headings {
+		if !strings.EqualFold(h.Text, want) {
+			continue
+		}
+		end := len(lines)
+		for _, next := range headings[i+1:] {
+			if next.Level <= h.Level {
+				end = next.Line - 1
+				break
+			}
+		}
+		return strings.TrimRight(strings.Join(lines[h.Line-1:end], "\n"), "\n"), true
+	}
+	return "", false
+}
+
+// embedBlock returns the block with the ID in a note body, without the ID:
+// a paragraph or heading ending with "^id", a list item with its nested
+// items, or the block before an "^id" line.
+func embedBlock(body, id string) (string, bool) {
+	lines := strings.Split(body, "\n")
+	inFence := false
+	for i, line := range lines {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if strings.EqualFold(trimmed, "^"+id) {
+			start := i
+			for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+				start--
+			}
+			return strings.Join(lines[start:i], "\n"), start < i
+		}
+		text, found := cutBlockID(strings.TrimRight(line, " \t"))
+		if !strings.EqualFold(found, id) {
+			continue
+		}
+		if m, ok := parseListMarker(line); ok {
+			return listItemLines(lines, i, text, m.indent), true
+		}
+		start := i
+		for start > 0 && strings.TrimSpace(lines[start-1]) != "" && !startsBlock(lines[start]) {
+			start--
+		}
+		return strings.Join(append(slices.Clone(lines[start:i]), text), "\n"), true
+	}
+	return "", false
+}
+
+// listItemLines returns the list item at line i, whose text is replaced,
+// with the lines nested in it, unindented.
+func listItemLines(lines []string, i int, text string, indent int) string {
+	item := []string{dedent(text, indent)}
+	for _, line := range lines[i+1:] {
+		if strings.TrimSpace(line) != "" && indentation(line) <= indent {
+			break
+		}
+		item = append(item, dedent(line, indent))
+	}
+	return strings.TrimRight(strings.Join(item, "\n"), "\n")
+}
@@ -0,0 +1,123 @@
+package obsidian
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEmbedResolver() *EmbedResolver {
+	notes := map[string]string{
+		"Meeting.md":         "---\ntype: meeting\n---\nAgenda:\n\n![[Agenda]]\n",
+		"Snippets/Agenda.md": "# Agenda\n\nIntro.\n\n## Status\n\nAll green.\n\n### Details\n\nNone.\n\n## Risks\n\nLate.\n",
+		"Facts.md":           "First line\nof the paragraph ^fact\n\n- item ^item\n  - child\n- other\n\n| A |\n|---|\n| 1 |\n^table\n",
+		"Loop.md":            "Loop: ![[Loop]]",
+		"A.md":               "A embeds ![[B]]",
+		"B.md":               "B embeds ![[C]]",
+		"C.md":               "C embeds ![[A]]",
+	}
+	files := []string{"Meeting.md", "Snippets/Agenda.md", "Facts.md", "Loop.md", "A.md", "B.md", "C.md", "image.png"}
+	return NewEmbedResolver(files, func(_ context.Context, path string) (string, error) {
+		return notes[path], nil
+	})
+}
+
+func TestEmbedResolver_Expand(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{
+			name:    "note without frontmatter",
+			content: "![[Meeting]]",
+			want:    "Agenda:\n\n# Agenda\n\nIntro.\n\n## Status\n\nAll green.\n\n### Details\n\nNone.\n\n## Risks\n\nLate.",
+		},
+		{
+			name:    "heading section",
+			content: "![[Agenda#Status]]",
+			want:    "## Status\n\nAll green.\n\n### Details\n\nNone.",
+		},
+		{
+			name:    "nested heading",
+			content: "![[Agenda#Agenda#details]]",
+			want:    "### Details\n\nNone.",
+		},
+		{
+			name:    "paragraph block",
+			content: "> ![[Facts#^fact]]",
+			want:    "> First line\nof the paragraph",
+		},
+		{
+			name:    "list item block",
+			content: "![[Facts#^item]]",
+			want:    "- item\n  - child",
+		},
+		{
+			name:    "block before its ID",
+			content: "![[Facts#^table]]",
+			want:    "| A |\n|---|\n| 1 |",
+		},
+		{
+			name:    "attachments and code stay",
+			content: "![[image.png|200]] `![[Meeting]]`\n```\n![[Meeting]]\n```",
+			want:    "![[image.png|200]] `![[Meeting]]`\n```\n![[Meeting]]\n```",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := testEmbedResolver().Expand(context.Background(), "Note.md", tt.content)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEmbedResolver_Limits(t *testing.T) {
+	r := testEmbedResolver()
+	got, embeds, err := r.Expand(context.Background(), "Note.md", "![[A]] ![[Loop]] ![[Gone]] ![[Agenda#Nothing]]")
+	require.NoError(t, err)
+	assert.Equal(t, "A embeds B embeds C embeds ![[A]] Loop: ![[Loop]] ![[Gone]] ![[Agenda#Nothing]]", got)
+	assert.Equal(t, []Embed{
+		{Target: "A", Path: "A.md", Depth: 1},
+		{Target: "B", Path: "B.md", Depth: 2},
+		{Target: "C", Path: "C.md", Depth: 3},
+		{Target: "A", Path: "A.md", Depth: 4, Error: ErrEmbedDepth.Error()},
+		{Target: "Loop", Path: "Loop.md", Depth: 1},
+		{Target: "Loop", Path: "Loop.md", Depth: 2, Error: ErrEmbedCycle.Error()},
+		{Target: "Gone", Depth: 1, Error: ErrEmbedNotFound.Error()},
+		{Target: "Agenda#Nothing", Path: "Snippets/Agenda.md", Depth: 1, Error: ErrEmbedSubpath.Error() + ": Nothing"},
+	}, embeds)
+
+	r.MaxDepth = MaxEmbedDepth
+	got, embeds, err = r.Expand(context.Background(), "Note.md", "![[A]]")
+	require.NoError(t, err)
+	assert.Equal(t, "A embeds B embeds C embeds ![[A]]", got)
+	assert.Equal(t, ErrEmbedCycle.Error(), embeds[3].Error)
+}
+
+func TestEmbedResolver_FanOut(t *testing.T) {
+	// Each level embeds the next one four times, so expanding it fully would
+	// take 4^10 copies of the last level.
+	notes := map[string]string{}
+	var files []string
+	for level := range MaxEmbedDepth + 1 {
+		name := fmt.Sprintf("L%d", level)
+		files = append(files, name+".md")
+		next := fmt.Sprintf("![[L%d]]", level+1)
+		notes[name+".md"] = strings.Repeat("Level text. ", 10) + strings.Repeat(next, 4)
+	}
+	r := NewEmbedResolver(files, func(_ context.Context, path string) (string, error) {
+		return notes[path], nil
+	})
+	r.MaxDepth = MaxEmbedDepth
+	r.MaxSize = 10000
+
+	got, embeds, err := r.Expand(context.Background(), "Note.md", "![[L0]]")
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(got), r.MaxSize+len("![[L0]]"))
+	assert.Contains(t, got, "![[L")
+	assert.Equal(t, ErrEmbedSize.Error(), embeds[len(embeds)-1].Error)
+}
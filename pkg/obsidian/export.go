@@ -0,0 +1,480 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportService renders notes as documents that can be shared outside
+// Obsidian: a standalone HTML page, plain text, or a JSON bundle with the
+// notes and their metadata. HTML pages carry their images as data URLs;
+// other attachments are not part of the document.
+type ExportService struct {
+	client *Client
+}
+
+// Export formats.
+const (
+	ExportHTML = "html"
+	ExportText = "text"
+	ExportJSON = "json"
+)
+
+// ExportFormats returns the names of the export formats.
+func ExportFormats() []string {
+	return []string{ExportHTML, ExportText, ExportJSON}
+}
+
+// ErrExportFormat is returned for an export format that is not supported.
+var ErrExportFormat = errors.New("unknown export format")
+
+// ErrNoNotes is returned when there are no notes to export in a folder.
+var ErrNoNotes = errors.New("no notes to export")
+
+// maxInlineImageSize is the size of the largest image that an HTML export
+// carries; larger images are rendered as their name.
+const maxInlineImageSize = 10 << 20
+
+// ErrExportRead is returned when a note of an exported folder cannot be read.
+var ErrExportRead = errors.New("failed to read note")
+
+// Export is a rendered note or folder.
+type Export struct {
+	Format string `json:"format"`
+	// Filename is a suggested file name: the name of the note or folder
+	// with the extension of the format.
+	Filename string `json:"filename"`
+	MIMEType string `json:"mime_type"`
+	// Notes are the paths of the exported notes.
+	Notes   []string `json:"notes"`
+	Content string   `json:"content"`
+}
+
+// ExportBundle is the content of a JSON export.
+type ExportBundle struct {
+	Exported time.Time      `json:"exported"`
+	Notes    []ExportedNote `json:"notes"`
+}
+
+// ExportedNote is a note in a JSON export.
+type ExportedNote struct {
+	Path        string                 `json:"path"`
+	Title       string                 `json:"title"`
+	Frontmatter map[string]interface{} `json:"frontmatter,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Created     time.Time              `json:"created,omitzero"`
+	Modified    time.Time              `json:"modified,omitzero"`
+	// Links are the files of the vault that the note links to or embeds.
+	Links []string `json:"links,omitempty"`
+	// Unresolved are the link targets that do not exist in the vault.
+	Unresolved []string `json:"unresolved,omitempty"`
+	// Markdown is the body of the note, without frontmatter.
+	Markdown string `json:"markdown"`
+	// Text is the body of the note as plain text.
+	Text string `json:"text"`
+}
+
+// Note exports a note. Links to other notes are rendered as text, because
+// the other notes are not part of the document; embedded notes and images
+// are included.
+func (s *ExportService) Note(ctx context.Context, notePath, format string) (*Export, error) {
+	if err := checkExportFormat(format); err != nil {
+		return nil, err
+	}
+	files, err := s.client.Vault.ListRecursive(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	note, err := s.client.Vault.GetNote(ctx, notePath)
+	if err != nil {
+		return nil, err
+	}
+	if note.Path == "" {
+		note.Path = notePath
+	}
+
+	e := newExporter(files, []*Note{note})
+	e.embeds.read = s.client.Vault.Get
+	e.readFile = s.client.Vault.Get
+	return e.export(ctx, format, strings.TrimSuffix(path.Base(note.Path), ".md"), time.Now())
+}
+
+// Folder exports the notes in a folder and its subfolders as one
+// document, in which links between the exported notes lead to each other.
+func (s *ExportService) Folder(ctx context.Context, folder, format string) (*Export, error) {
+	if err := checkExportFormat(format); err != nil {
+		return nil, err
+	}
+	files, err := s.client.Vault.ListRecursive(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, f := range files {
+		if isMarkdown(f) && inFolder(f, folder) {
+			paths = append(paths, f)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%w in %q", ErrNoNotes, folder)
+	}
+
+	read, failed, err := readAll(ctx, paths, s.client.Vault.GetNote)
+	if err != nil {
+		return nil, err
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("%w %s: %s", ErrExportRead, failed[0].Path, failed[0].Error)
+	}
+	notes := make([]*Note, 0, len(paths))
+	for _, p := range paths {
+		read[p].Path = p
+		notes = append(notes, read[p])
+	}
+
+	e := newExporter(files, notes)
+	e.embeds.read = s.client.Vault.Get
+	e.readFile = s.client.Vault.Get
+	name := path.Base(strings.Trim(folder, "/"))
+	if strings.Trim(folder, "/") == "" {
+		name = "Vault"
+	}
+	return e.export(ctx, format, name, time.Now())
+}
+
+// exporter renders notes, resolving their links against the files of the vault.
+type exporter struct {
+	index *LinkIndex
+	// notes are the exported notes, in order.
+	notes []*Note
+	// embeds expands embedded notes; it has the content of the exported notes.
+	embeds *EmbedResolver
+	// multi is set when several notes are exported into one document.
+	multi bool
+	// readFile reads the images that are embedded in HTML exports. Images
+	// are rendered as their name if it is nil.
+	readFile func(ctx context.Context, path string) (string, error)
+	// images caches the data URLs of images, by path.
+	images map[string]string
+}
+
+func newExporter(files []string, notes []*Note) *exporter {
+	e := &exporter{
+		index:  NewLinkIndex(files),
+		notes:  notes,
+		embeds: NewEmbedResolver(files, nil),
+		multi:  len(notes) > 1,
+		images: make(map[string]string),
+	}
+	for _, note := range notes {
+		e.embeds.cache[note.Path] = note.Content
+	}
+	return e
+}
+
+// export renders the notes in the format. name is the title of the
+// document and the name of its file.
+func (e *exporter) export(ctx context.Context, format, name string, now time.Time) (*Export, error) {
+	out := &Export{Format: format, Filename: SanitizeFilename(name)}
+	for _, note := range e.notes {
+		out.Notes = append(out.Notes, note.Path)
+	}
+
+	switch format {
+	case ExportHTML:
+		out.Filename += ".html"
+		out.MIMEType = "text/html"
+		out.Content = e.html(ctx, name)
+	case ExportText:
+		out.Filename += ".txt"
+		out.MIMEType = "text/plain"
+		out.Content = e.text(ctx)
+	case ExportJSON:
+		out.Filename += ".json"
+		out.MIMEType = "application/json"
+		data, err := json.MarshalIndent(e.bundle(ctx, now), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		out.Content = string(data) + "\n"
+	default:
+		return nil, checkExportFormat(format)
+	}
+	// Embeds that could not be read because ctx is done are rendered as links.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func checkExportFormat(format string) error {
+	if !slices.Contains(ExportFormats(), format) {
+		return fmt.Errorf("%w: %q", ErrExportFormat, format)
+	}
+	return nil
+}
+
+// noteTitle returns the title of a note: its file name without extension.
+func noteTitle(notePath string) string {
+	return strings.TrimSuffix(path.Base(notePath), path.Ext(notePath))
+}
+
+// noteAnchor returns the HTML ID of an exported note in a document with
+// several notes, which also prefixes the IDs of its headings and blocks.
+func (e *exporter) noteAnchor(notePath string) string {
+	if !e.multi {
+		return ""
+	}
+	return headingID(strings.ReplaceAll(strings.TrimSuffix(notePath, path.Ext(notePath)), "/", " "))
+}
+
+func (e *exporter) idPrefix(notePath string) string {
+	if !e.multi {
+		return ""
+	}
+	return e.noteAnchor(notePath) + "--"
+}
+
+// renderer returns a renderer for the body of the note at source.
+func (e *exporter) renderer(ctx context.Context, source string, plain bool, ids map[string]int) *renderer {
+	r := &renderer{plain: plain, idPrefix: e.idPrefix(source), ids: ids}
+	r.link = func(link Link) (string, bool) { return e.href(link, source) }
+	r.embed = func(link Link) string { return e.embed(ctx, r, link, source) }
+	return r
+}
+
+// href returns the URL of a link within the document. Links to attachments
+// and to notes that are not exported cannot be followed.
+func (e *exporter) href(link Link, source string) (string, bool) {
+	target, ok := e.index.Resolve(link, source)
+	if !ok || !isMarkdown(target) {
+		return "", false
+	}
+	if !slices.ContainsFunc(e.notes, func(n *Note) bool { return n.Path == target }) {
+		return "", false
+	}
+	if id, isBlock := strings.CutPrefix(link.Subpath, "^"); isBlock {
+		return "#" + e.idPrefix(target) + blockAnchor(id), true
+	}
+	if link.Subpath != "" {
+		parts := strings.Split(link.Subpath, "#")
+		return "#" + e.idPrefix(target) + headingID(parts[len(parts)-1]), true
+	}
+	return "#" + e.noteAnchor(target), true
+}
+
+// embed renders an embedded note or attachment. Embedded notes are
+// rendered in place, without their frontmatter, with their own embeds
+// expanded; embeds that cannot be expanded are rendered as links.
+func (e *exporter) embed(ctx context.Context, r *renderer, link Link, source string) string {
+	target, ok := e.index.Resolve(link, source)
+	if !ok || !isMarkdown(target) {
+		return e.embedFile(ctx, r, link, source)
+	}
+	content, _, err := e.embeds.Embedded(ctx, link, source)
+	if err != nil {
+		return e.embedFile(ctx, r, link, source)
+	}
+
+	// Links in the embedded note are relative to it; its headings get IDs
+	// in the embedding note.
+	inner := &renderer{plain: r.plain, idPrefix: r.idPrefix, ids: r.ids}
+	inner.link = func(link Link) (string, bool) { return e.href(link, target) }
+	inner.embed = func(link Link) string { return e.embedFile(ctx, inner, link, target) }
+	rendered := inner.render(parseMarkdown(content))
+	if r.plain {
+		return rendered
+	}
+	return `<div class="internal-embed" data-src="` + html.EscapeString(target) + `">` + "\n" + rendered + "\n</div>"
+}
+
+// embedFile renders an embed that is not expanded: an attachment, or a
+// link to the embedded note.
+func (e *exporter) embedFile(ctx context.Context, r *renderer, link Link, source string) string {
+	if target, ok := e.index.Resolve(link, source); ok && !isMarkdown(target) {
+		return e.attachment(ctx, r, link, target)
+	}
+	link.Embed = false
+	return r.internalLink(link)
+}
+
+// attachment renders an embedded file: an image, or the name of other
+// files, which are not part of the document.
+func (e *exporter) attachment(ctx context.Context, r *renderer, link Link, target string) string {
+	label := path.Base(target)
+	width := ""
+	if _, err := strconv.Atoi(link.Alias); err == nil {
+		// ![[image.png|300]] sets the width of the image.
+		width = ` width="` + link.Alias + `"`
+	} else if link.Alias != "" {
+		label = link.Alias
+	}
+	if r.plain {
+		return "[" + label + "]"
+	}
+	if src, ok := e.imageURL(ctx, target); ok {
+		return `<img src="` + src + `" alt="` + html.EscapeString(label) + `"` + width + `>`
+	}
+	return `<span class="internal-embed">` + html.EscapeString(label) + `</span>`
+}
+
+// imageURL returns the data URL of an image of the vault, so that the
+// document shows it outside the vault.
+func (e *exporter) imageURL(ctx context.Context, target string) (string, bool) {
+	if !isImage(target) || e.readFile == nil {
+		return "", false
+	}
+	if src, ok := e.images[target]; ok {
+		return src, src != ""
+	}
+	src := ""
+	if data, err := e.readFile(ctx, target); err == nil && len(data) <= maxInlineImageSize {
+		mimeType := mime.TypeByExtension(path.Ext(target))
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+		src = "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString([]byte(data))
+	}
+	e.images[target] = src
+	return src, src != ""
+}
+
+func isImage(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".bmp", ".svg", ".webp", ".avif":
+		return true
+	default:
+		return false
+	}
+}
+
+// body renders the body of a note.
+func (e *exporter) body(ctx context.Context, note *Note, plain bool, ids map[string]int) (string, []*mdBlock) {
+	_, body := SplitFrontmatter(note.Content)
+	blocks := parseMarkdown(body)
+	return e.renderer(ctx, note.Path, plain, ids).render(blocks), blocks
+}
+
+func (e *exporter) html(ctx context.Context, title string) string {
+	var sb strings.Builder
+	ids := make(map[string]int)
+	if e.multi {
+		sb.WriteString("<nav class=\"toc\">\n<ul>\n")
+		for _, note := range e.notes {
+			fmt.Fprintf(&sb, "<li><a href=\"#%s\">%s</a></li>\n",
+				html.EscapeString(e.noteAnchor(note.Path)), html.EscapeString(noteTitle(note.Path)))
+		}
+		sb.WriteString("</ul>\n</nav>\n")
+	}
+	for _, note := range e.notes {
+		body, blocks := e.body(ctx, note, false, ids)
+		if e.multi {
+			fmt.Fprintf(&sb, "<article id=\"%s\">\n", html.EscapeString(e.noteAnchor(note.Path)))
+		} else {
+			sb.WriteString("<article>\n")
+		}
+		if len(blocks) == 0 || blocks[0].kind != blockHeading || blocks[0].level != 1 {
+			fmt.Fprintf(&sb, "<h1 class=\"note-title\">%s</h1>\n", html.EscapeString(noteTitle(note.Path)))
+		}
+		if body != "" {
+			sb.WriteString(body + "\n")
+		}
+		sb.WriteString("</article>\n")
+	}
+	return htmlDocument(title, sb.String())
+}
+
+func (e *exporter) text(ctx context.Context) string {
+	parts := make([]string, 0, len(e.notes))
+	for _, note := range e.notes {
+		body, _ := e.body(ctx, note, true, nil)
+		if e.multi {
+			title := noteTitle(note.Path)
+			body = strings.TrimRight(title+"\n"+strings.Repeat("=", len([]rune(title)))+"\n\n"+body, "\n")
+		}
+		parts = append(parts, body)
+	}
+	return strings.Join(parts, "\n\n\n") + "\n"
+}
+
+func (e *exporter) bundle(ctx context.Context, now time.Time) *ExportBundle {
+	bundle := &ExportBundle{Exported: now.UTC().Truncate(time.Second), Notes: make([]ExportedNote, 0, len(e.notes))}
+	for _, note := range e.notes {
+		_, markdown := SplitFrontmatter(note.Content)
+		text, _ := e.body(ctx, note, true, nil)
+		exported := ExportedNote{
+			Path:        note.Path,
+			Title:       noteTitle(note.Path),
+			Frontmatter: note.Frontmatter,
+			Tags:        note.Tags,
+			Created:     statTime(note.Stat.Ctime),
+			Modified:    statTime(note.Stat.Mtime),
+			Markdown:    markdown,
+			Text:        text,
+		}
+		for _, link := range ParseLinks(note.Content) {
+			if target, ok := e.index.Resolve(link, note.Path); !ok {
+				exported.Unresolved = appendUnique(exported.Unresolved, link.Target)
+			} else if target != note.Path {
+				exported.Links = appendUnique(exported.Links, target)
+			}
+		}
+		bundle.Notes = append(bundle.Notes, exported)
+	}
+	return bundle
+}
+
+// statTime converts a file time of the API (milliseconds since the epoch).
+func statTime(ms float64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(ms)).UTC()
+}
+
+func appendUnique(list []string, s string) []string {
+	if slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
+
+// exportStyle is the stylesheet of HTML exports, after Obsidian's default theme.
+const exportStyle = `body { max-width: 46em; margin: 2em auto; padding: 0 1em; font: 16px/1.6 -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; color: #222; }
+nav.toc { border-bottom: 1px solid #ddd; margin-bottom: 2em; }
+article + article { border-top: 1px solid #ddd; margin-top: 3em; }
+a { color: #705dcf; }
+.internal-link.unresolved { color: #888; border-bottom: 1px dashed #aaa; }
+.tag { background: #efeafc; color: #705dcf; border-radius: 1em; padding: 0 .5em; font-size: .9em; }
+mark { background: #fff3a3; }
+code { background: #f3f3f3; border-radius: 3px; padding: .1em .3em; font-size: .9em; }
+pre { background: #f3f3f3; border-radius: 4px; padding: 1em; overflow-x: auto; }
+pre code { background: none; padding: 0; }
+blockquote { border-left: 3px solid #705dcf; margin-left: 0; padding-left: 1em; color: #555; }
+.callout { border-left: 4px solid #448aff; background: #f0f5ff; border-radius: 4px; padding: .5em 1em; margin: 1em 0; }
+.callout-title { font-weight: 600; }
+.internal-embed { display: block; border-left: 2px solid #ddd; padding-left: 1em; margin: 1em 0; }
+img { max-width: 100%; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ddd; padding: .3em .6em; }
+li.task-list-item { list-style: none; }
+li.task-list-item input { margin-left: -1.4em; }
+`
+
+// htmlDocument wraps the body of an HTML export in a standalone page.
+func htmlDocument(title, body string) string {
+	return "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n" +
+		"<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n" +
+		"<title>" + html.EscapeString(title) + "</title>\n<style>\n" + exportStyle + "</style>\n</head>\n<body>\n" +
+		body + "</body>\n</html>\n"
+}
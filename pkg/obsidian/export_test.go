@@ -0,0 +1,172 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportTestNotes() ([]string, []*Note) {
+	files := []string{"Projects/Alpha.md", "Projects/Beta.md", "Other.md", "assets/chart.png"}
+	notes := []*Note{
+		{
+			Path:        "Projects/Alpha.md",
+			Content:     "---\nstatus: active\n---\n# Alpha\n\nSee [[Beta#Risks]], [[Other]] and [[Gone]].\n\n![[chart.png]]\n",
+			Frontmatter: map[string]interface{}{"status": "active"},
+			Tags:        []string{"project"},
+			Stat:        FileStat{Ctime: 1700000000000, Mtime: 1700000600000},
+		},
+		{
+			Path:    "Projects/Beta.md",
+			Content: "## Risks\n\nBack to [[Alpha]].\n",
+		},
+	}
+	return files, notes
+}
+
+func TestExporter_HTML(t *testing.T) {
+	files, notes := exportTestNotes()
+	e := newExporter(files, notes)
+	e.readFile = func(_ context.Context, path string) (string, error) {
+		assert.Equal(t, "assets/chart.png", path)
+		return "PNG", nil
+	}
+	export, err := e.export(context.Background(), ExportHTML, "Projects", time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, "Projects.html", export.Filename)
+	assert.Equal(t, "text/html", export.MIMEType)
+	assert.Equal(t, []string{"Projects/Alpha.md", "Projects/Beta.md"}, export.Notes)
+	assert.Contains(t, export.Content, "<title>Projects</title>")
+	assert.Contains(t, export.Content, `<li><a href="#projects-alpha">Alpha</a></li>`)
+	assert.Contains(t, export.Content, "<article id=\"projects-alpha\">\n<h1 id=\"projects-alpha--alpha\">Alpha</h1>")
+	// Beta has no H1, so it gets its title.
+	assert.Contains(t, export.Content, "<article id=\"projects-beta\">\n<h1 class=\"note-title\">Beta</h1>\n<h2 id=\"projects-beta--risks\">Risks</h2>")
+	assert.Contains(t, export.Content, `<a class="internal-link" href="#projects-beta--risks">Beta &gt; Risks</a>`)
+	assert.Contains(t, export.Content, `<a class="internal-link" href="#projects-alpha">Alpha</a>`)
+	// Other is not exported, Gone does not exist.
+	assert.Contains(t, export.Content, `<span class="internal-link unresolved">Other</span>`)
+	assert.Contains(t, export.Content, `<span class="internal-link unresolved">Gone</span>`)
+	// Images are part of the document.
+	assert.Contains(t, export.Content, `<img src="data:image/png;base64,UE5H" alt="chart.png">`)
+	assert.NotContains(t, export.Content, "status: active")
+}
+
+func TestExporter_HTMLAttachments(t *testing.T) {
+	files := []string{"Note.md", "report.pdf", "big.png"}
+	notes := []*Note{{Path: "Note.md", Content: "See [[report.pdf]].\n\n![[report.pdf]]\n\n![[big.png|Chart]]\n"}}
+	e := newExporter(files, notes)
+	e.readFile = func(context.Context, string) (string, error) {
+		return strings.Repeat("x", maxInlineImageSize+1), nil
+	}
+	export, err := e.export(context.Background(), ExportHTML, "Note", time.Now())
+	require.NoError(t, err)
+
+	// Other attachments and images that are too large are not part of the
+	// document, so they are rendered as their names.
+	assert.Contains(t, export.Content, `<p>See <span class="internal-link unresolved">report.pdf</span>.</p>`)
+	assert.Contains(t, export.Content, `<span class="internal-embed">report.pdf</span>`)
+	assert.Contains(t, export.Content, `<span class="internal-embed">Chart</span>`)
+	assert.NotContains(t, export.Content, "<img")
+}
+
+func TestExporter_Text(t *testing.T) {
+	files, notes := exportTestNotes()
+	export, err := newExporter(files, notes).export(context.Background(), ExportText, "Projects", time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, "Projects.txt", export.Filename)
+	assert.Equal(t, "Alpha\n=====\n\nAlpha\n\nSee Beta > Risks, Other and Gone.\n\n[chart.png]\n\n\n"+
+		"Beta\n====\n\nRisks\n\nBack to Alpha.\n", export.Content)
+}
+
+func TestExporter_JSON(t *testing.T) {
+	files, notes := exportTestNotes()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	export, err := newExporter(files, notes).export(context.Background(), ExportJSON, "Projects", now)
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", export.MIMEType)
+
+	var bundle ExportBundle
+	require.NoError(t, json.Unmarshal([]byte(export.Content), &bundle))
+	assert.Equal(t, now, bundle.Exported)
+	require.Len(t, bundle.Notes, 2)
+	alpha := bundle.Notes[0]
+	assert.Equal(t, "Alpha", alpha.Title)
+	assert.Equal(t, map[string]interface{}{"status": "active"}, alpha.Frontmatter)
+	assert.Equal(t, []string{"project"}, alpha.Tags)
+	assert.Equal(t, time.UnixMilli(1700000600000).UTC(), alpha.Modified)
+	assert.Equal(t, []string{"Projects/Beta.md", "Other.md", "assets/chart.png"}, alpha.Links)
+	assert.Equal(t, []string{"Gone"}, alpha.Unresolved)
+	assert.Equal(t, "# Alpha\n\nSee [[Beta#Risks]], [[Other]] and [[Gone]].\n\n![[chart.png]]\n", alpha.Markdown)
+	assert.Equal(t, "Alpha\n\nSee Beta > Risks, Other and Gone.\n\n[chart.png]", alpha.Text)
+	assert.True(t, bundle.Notes[1].Modified.IsZero())
+}
+
+func TestExport_Note(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vault/":
+			_ = json.NewEncoder(w).Encode(map[string][]string{"files": {"Meeting.md", "Snippets/"}})
+		case "/vault/Snippets/":
+			_ = json.NewEncoder(w).Encode(map[string][]string{"files": {"Agenda.md"}})
+		case "/vault/Meeting.md":
+			_ = json.NewEncoder(w).Encode(Note{Path: "Meeting.md", Content: "Notes.\n\n![[Agenda]]\n"})
+		case "/vault/Snippets/Agenda.md":
+			w.Header().Set("Content-Type", "text/markdown")
+			fmt.Fprint(w, "---\ntype: snippet\n---\n1. Status\n2. [[Meeting|Back]]\n")
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	export, err := client.Export.Note(context.Background(), "Meeting.md", ExportHTML)
+	require.NoError(t, err)
+	assert.Equal(t, "Meeting.html", export.Filename)
+	assert.Contains(t, export.Content, "<article>\n<h1 class=\"note-title\">Meeting</h1>\n<p>Notes.</p>\n"+
+		"<div class=\"internal-embed\" data-src=\"Snippets/Agenda.md\">\n<ol>\n<li>Status</li>\n"+
+		"<li><a class=\"internal-link\" href=\"#\">Back</a></li>\n</ol>\n</div>\n</article>")
+
+	export, err = client.Export.Note(context.Background(), "Meeting.md", ExportText)
+	require.NoError(t, err)
+	assert.Equal(t, "Notes.\n\n1. Status\n2. Back\n", export.Content)
+
+	_, err = client.Export.Note(context.Background(), "Meeting.md", "pdf")
+	assert.ErrorIs(t, err, ErrExportFormat)
+}
+
+func TestExport_Folder(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vault/":
+			_ = json.NewEncoder(w).Encode(map[string][]string{"files": {"Index.md", "Empty/"}})
+		case "/vault/Empty/":
+			_ = json.NewEncoder(w).Encode(map[string][]string{"files": {"image.png"}})
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	_, err = client.Export.Folder(context.Background(), "Empty", ExportText)
+	assert.ErrorIs(t, err, ErrNoNotes)
+}
@@ -0,0 +1,66 @@
+package obsidian
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxFilenameLength is the maximum length in bytes of a name returned by
+// SanitizeFilename, well below the limits of file systems and sync services.
+const maxFilenameLength = 100
+
+// SanitizeFilename turns a title into a file name (without extension) that
+// is valid on all platforms and can be linked to. Characters that are not
+// allowed in file names (\ / : * ? " < > |) or that have a meaning in links
+// (# ^ [ ]) are replaced with spaces, whitespace is collapsed, leading and
+// trailing dots and spaces are removed, and long names are shortened. It
+// returns "" if nothing is left.
+func SanitizeFilename(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r < ' ' || strings.ContainsRune(`\/:*?"<>|#^[]`, r) {
+			return ' '
+		}
+		return r
+	}, name)
+	cleaned = strings.Join(strings.Fields(cleaned), " ")
+
+	if len(cleaned) > maxFilenameLength {
+		cut := maxFilenameLength
+		for cut > 0 && !utf8.RuneStart(cleaned[cut]) {
+			cut--
+		}
+		cleaned = cleaned[:cut]
+	}
+	return strings.Trim(cleaned, ". ")
+}
+
+// AvailablePath returns filePath if no file exists there, or else the first
+// free path with a number added to the name, as Obsidian does for new
+// files: "Note.md", "Note 1.md", "Note 2.md" and so on.
+func (s *VaultService) AvailablePath(ctx context.Context, filePath string) (string, error) {
+	dir := path.Dir(filePath)
+	listDir := ""
+	if dir != "." {
+		listDir = dir + "/"
+	}
+	files, err := s.List(ctx, listDir)
+	if err != nil && !IsNotFound(err) {
+		return "", err
+	}
+	existing := make(map[string]bool, len(files))
+	for _, f := range files {
+		existing[strings.ToLower(f)] = true
+	}
+
+	base := path.Base(filePath)
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	name := base
+	for i := 1; existing[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("%s %d%s", stem, i, ext)
+	}
+	return path.Join(dir, name), nil
+}
@@ -0,0 +1,52 @@
+package obsidian
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	assert.Equal(t, "Go Tips & Tricks", SanitizeFilename("Go: Tips & Tricks"))
+	assert.Equal(t, "a b c", SanitizeFilename("  a/b\\c.. "))
+	assert.Equal(t, "Issue 12 Fix", SanitizeFilename("[Issue #12] Fix"))
+	assert.Empty(t, SanitizeFilename("???"))
+	long := SanitizeFilename(strings.Repeat("é", 80))
+	assert.Len(t, long, 100)
+	assert.True(t, utf8.ValidString(long))
+}
+
+func TestVaultAvailablePath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/Notes/":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"files": ["Idea.md", "idea 1.md", "Sub/"]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errorCode": 40400, "message": "Not Found"}`)
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	p, err := client.Vault.AvailablePath(context.Background(), "Notes/Idea.md")
+	require.NoError(t, err)
+	assert.Equal(t, "Notes/Idea 2.md", p)
+
+	p, err = client.Vault.AvailablePath(context.Background(), "Notes/Other.md")
+	require.NoError(t, err)
+	assert.Equal(t, "Notes/Other.md", p)
+
+	p, err = client.Vault.AvailablePath(context.Background(), "New/Idea.md")
+	require.NoError(t, err)
+	assert.Equal(t, "New/Idea.md", p)
+}
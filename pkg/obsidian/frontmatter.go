@@ -0,0 +1,37 @@
+package obsidian
+
+import "strings"
+
+// SplitFrontmatter splits a note into its YAML frontmatter (without the
+// "---" delimiters) and the body after it. If the note has no frontmatter,
+// frontmatter is empty and body is the whole content.
+func SplitFrontmatter(content string) (frontmatter, body string) {
+	rest, ok := cutDelimiterLine(content)
+	if !ok {
+		return "", content
+	}
+	for offset := 0; offset < len(rest); {
+		end := strings.IndexByte(rest[offset:], '\n')
+		line := rest[offset:]
+		next := len(rest)
+		if end >= 0 {
+			line = rest[offset : offset+end]
+			next = offset + end + 1
+		}
+		if strings.TrimRight(line, " \t\r") == "---" {
+			return rest[:offset], rest[next:]
+		}
+		offset = next
+	}
+	// An unterminated block is not frontmatter.
+	return "", content
+}
+
+// cutDelimiterLine removes the opening "---" line of a frontmatter block.
+func cutDelimiterLine(content string) (string, bool) {
+	first, rest, found := strings.Cut(content, "\n")
+	if !found || strings.TrimRight(first, " \t\r") != "---" {
+		return "", false
+	}
+	return rest, true
+}
@@ -0,0 +1,31 @@
+package obsidian
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitFrontmatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		frontmatter string
+		body        string
+	}{
+		{"none", "# Title\n", "", "# Title\n"},
+		{"simple", "---\ntags: [a]\n---\n# Title\n", "tags: [a]\n", "# Title\n"},
+		{"crlf", "---\r\nk: v\r\n---\r\nbody", "k: v\r\n", "body"},
+		{"empty", "---\n---\nbody", "", "body"},
+		{"at end", "---\nk: v\n---", "k: v\n", ""},
+		{"unterminated", "---\nk: v\nbody", "", "---\nk: v\nbody"},
+		{"not at start", "text\n---\nk: v\n---\n", "", "text\n---\nk: v\n---\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frontmatter, body := SplitFrontmatter(tt.content)
+			assert.Equal(t, tt.frontmatter, frontmatter)
+			assert.Equal(t, tt.body, body)
+		})
+	}
+}
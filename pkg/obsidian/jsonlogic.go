@@ -0,0 +1,275 @@
+package obsidian
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidQuery is returned when a JsonLogic query fails local validation.
+var ErrInvalidQuery = errors.New("invalid JsonLogic query")
+
+// Logic is a JsonLogic expression, e.g. {"==": [{"var": "path"}, "a.md"]}.
+// It can be passed directly to SearchService.JSONLogic.
+type Logic map[string]interface{}
+
+// Note fields that can be referenced with Var in a JsonLogic query.
+const (
+	FieldContent     = "content"
+	FieldFrontmatter = "frontmatter"
+	FieldPath        = "path"
+	FieldStat        = "stat"
+	FieldTags        = "tags"
+)
+
+// Var references a field of the note, e.g. Var("frontmatter.status") or Var("stat.mtime").
+func Var(name string) Logic {
+	return Logic{"var": name}
+}
+
+// And is true if all the expressions are true.
+func And(exprs ...interface{}) Logic {
+	return Logic{"and": exprs}
+}
+
+// Or is true if any of the expressions is true.
+func Or(exprs ...interface{}) Logic {
+	return Logic{"or": exprs}
+}
+
+// Not negates the expression.
+func Not(expr interface{}) Logic {
+	return Logic{"!": []interface{}{expr}}
+}
+
+// Eq compares a and b with strict equality.
+func Eq(a, b interface{}) Logic {
+	return Logic{"===": []interface{}{a, b}}
+}
+
+// Gt is true if a > b.
+func Gt(a, b interface{}) Logic {
+	return Logic{">": []interface{}{a, b}}
+}
+
+// Gte is true if a >= b.
+func Gte(a, b interface{}) Logic {
+	return Logic{">=": []interface{}{a, b}}
+}
+
+// Lt is true if a < b.
+func Lt(a, b interface{}) Logic {
+	return Logic{"<": []interface{}{a, b}}
+}
+
+// Lte is true if a <= b.
+func Lte(a, b interface{}) Logic {
+	return Logic{"<=": []interface{}{a, b}}
+}
+
+// In is true if needle is an element of the haystack array, or a substring of the haystack string.
+func In(needle, haystack interface{}) Logic {
+	return Logic{"in": []interface{}{needle, haystack}}
+}
+
+// Glob is true if value matches the glob pattern (e.g. "Projects/**").
+func Glob(pattern string, value interface{}) Logic {
+	return Logic{"glob": []interface{}{pattern, value}}
+}
+
+// Regexp is true if value matches the regular expression.
+func Regexp(pattern string, value interface{}) Logic {
+	return Logic{"regexp": []interface{}{pattern, value}}
+}
+
+// isJSONLogicOperator reports whether op is understood by json-logic-js and the Local REST API.
+func isJSONLogicOperator(op string) bool {
+	switch op {
+	case "var", "missing", "missing_some",
+		"if", "?:", "==", "===", "!=", "!==", "!", "!!",
+		"or", "and", ">", ">=", "<", "<=",
+		"max", "min", "+", "-", "*", "/", "%",
+		"map", "filter", "reduce", "all", "none", "some",
+		"merge", "in", "cat", "substr", "log",
+		"glob", "regexp":
+		return true
+	default:
+		return false
+	}
+}
+
+// isIteratingOperator reports whether the later arguments of op are evaluated
+// against array elements rather than the note, so "var" there does not
+// reference note fields.
+func isIteratingOperator(op string) bool {
+	switch op {
+	case "map", "filter", "reduce", "all", "none", "some":
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidateJSONLogic checks a JsonLogic query before it is sent to Obsidian.
+// It reports unknown operators, malformed expressions, references to fields
+// that notes do not have and invalid glob patterns.
+func ValidateJSONLogic(query interface{}) error {
+	if _, ok := query.(map[string]interface{}); !ok {
+		if _, ok := query.(Logic); !ok {
+			return fmt.Errorf("%w: the query must be a JSON object", ErrInvalidQuery)
+		}
+	}
+	return validateLogic(query, "$", true)
+}
+
+func validateLogic(expr interface{}, where string, noteScope bool) error {
+	switch e := expr.(type) {
+	case Logic:
+		return validateLogic(map[string]interface{}(e), where, noteScope)
+	case []interface{}:
+		for i, item := range e {
+			if err := validateLogic(item, fmt.Sprintf("%s[%d]", where, i), noteScope); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		return validateOperation(e, where, noteScope)
+	default:
+		return nil
+	}
+}
+
+func validateOperation(e map[string]interface{}, where string, noteScope bool) error {
+	if len(e) != 1 {
+		keys := make([]string, 0, len(e))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return fmt.Errorf("%w: %s: an operation must have exactly one operator, got %v", ErrInvalidQuery, where, keys)
+	}
+
+	for op, args := range e {
+		if !isJSONLogicOperator(op) {
+			return fmt.Errorf("%w: %s: unknown operator %q", ErrInvalidQuery, where, op)
+		}
+		where = where + "." + op
+
+		switch op {
+		case "var":
+			if noteScope {
+				return validateVar(args, where)
+			}
+			return nil
+		case "glob":
+			if err := validateGlob(args, where); err != nil {
+				return err
+			}
+		}
+
+		list, isList := args.([]interface{})
+		if !isList {
+			return validateLogic(args, where, noteScope)
+		}
+		for i, arg := range list {
+			scope := noteScope && (i == 0 || !isIteratingOperator(op))
+			if err := validateLogic(arg, fmt.Sprintf("%s[%d]", where, i), scope); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateVar(args interface{}, where string) error {
+	name := args
+	if list, ok := args.([]interface{}); ok {
+		if len(list) == 0 {
+			return nil
+		}
+		name = list[0]
+	}
+
+	s, ok := name.(string)
+	if !ok || s == "" {
+		return nil
+	}
+	root, _, _ := strings.Cut(s, ".")
+	switch root {
+	case FieldContent, FieldFrontmatter, FieldPath, FieldStat, FieldTags:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s: unknown field %q (notes have content, frontmatter, path, stat and tags)", ErrInvalidQuery, where, s)
+	}
+}
+
+func validateGlob(args interface{}, where string) error {
+	list, ok := args.([]interface{})
+	if !ok || len(list) != 2 { //nolint:mnd // pattern and value
+		return fmt.Errorf("%w: %s: glob takes a pattern and a value", ErrInvalidQuery, where)
+	}
+	pattern, ok := list[0].(string)
+	if !ok {
+		return nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("%w: %s: bad glob pattern %q: %w", ErrInvalidQuery, where, pattern, err)
+	}
+	return nil
+}
+
+// NoteFilter is a set of simple conditions on notes that compiles to JsonLogic.
+// All non-empty conditions must match.
+type NoteFilter struct {
+	// Tags that the note must have (with or without the leading '#').
+	Tags []string
+	// Folder that the note must be in, including subfolders.
+	Folder string
+	// Frontmatter properties that must be equal to the given values.
+	Frontmatter map[string]interface{}
+	// ModifiedAfter and ModifiedBefore limit the modification time of the note.
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+}
+
+// JSONLogic compiles the filter to a JsonLogic query.
+func (f NoteFilter) JSONLogic() Logic {
+	conds := []interface{}{}
+
+	for _, tag := range f.Tags {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if tag != "" {
+			conds = append(conds, In(tag, Var(FieldTags)))
+		}
+	}
+
+	if folder := strings.Trim(f.Folder, "/"); folder != "" {
+		conds = append(conds, Glob(folder+"/**", Var(FieldPath)))
+	}
+
+	keys := make([]string, 0, len(f.Frontmatter))
+	for k := range f.Frontmatter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		conds = append(conds, Eq(Var(FieldFrontmatter+"."+k), f.Frontmatter[k]))
+	}
+
+	if !f.ModifiedAfter.IsZero() {
+		conds = append(conds, Gte(Var("stat.mtime"), f.ModifiedAfter.UnixMilli()))
+	}
+	if !f.ModifiedBefore.IsZero() {
+		conds = append(conds, Lt(Var("stat.mtime"), f.ModifiedBefore.UnixMilli()))
+	}
+
+	if len(conds) == 0 {
+		// Matches every note: all notes have a path.
+		return Logic{"!!": []interface{}{Var(FieldPath)}}
+	}
+	return And(conds...)
+}
@@ -0,0 +1,82 @@
+package obsidian
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogic_Builders(t *testing.T) {
+	query := Or(
+		Eq(Var("frontmatter.status"), "done"),
+		And(In("project", Var("tags")), Glob("Projects/**", Var("path"))),
+		Not(Regexp("^Draft", Var("path"))),
+	)
+
+	b, err := json.Marshal(query)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"or": [
+		{"===": [{"var": "frontmatter.status"}, "done"]},
+		{"and": [{"in": ["project", {"var": "tags"}]}, {"glob": ["Projects/**", {"var": "path"}]}]},
+		{"!": [{"regexp": ["^Draft", {"var": "path"}]}]}
+	]}`, string(b))
+	require.NoError(t, ValidateJSONLogic(query))
+}
+
+func TestValidateJSONLogic(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		valid bool
+	}{
+		{"valid", `{"===": [{"var": "frontmatter.url"}, "https://example.com"]}`, true},
+		{"iterating operator", `{"some": [{"var": "tags"}, {"==": [{"var": ""}, "x"]}]}`, true},
+		{"var with default", `{"var": ["frontmatter.x", 1]}`, true},
+		{"not an object", `["a"]`, false},
+		{"unknown operator", `{"like": [{"var": "path"}, "a%"]}`, false},
+		{"two operators", `{"and": [], "or": []}`, false},
+		{"unknown field", `{"===": [{"var": "title"}, "x"]}`, false},
+		{"bad glob", `{"glob": ["[a", {"var": "path"}]}`, false},
+		{"glob arity", `{"glob": ["a"]}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.query), &query))
+			err := ValidateJSONLogic(query)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidQuery)
+			}
+		})
+	}
+}
+
+func TestNoteFilter_JSONLogic(t *testing.T) {
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := NoteFilter{
+		Tags:          []string{"#project", " "},
+		Folder:        "/Work/",
+		Frontmatter:   map[string]interface{}{"status": "done"},
+		ModifiedAfter: after,
+	}
+
+	b, err := json.Marshal(filter.JSONLogic())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"and": [
+		{"in": ["project", {"var": "tags"}]},
+		{"glob": ["Work/**", {"var": "path"}]},
+		{"===": [{"var": "frontmatter.status"}, "done"]},
+		{">=": [{"var": "stat.mtime"}, 1704067200000]}
+	]}`, string(b))
+	require.NoError(t, ValidateJSONLogic(filter.JSONLogic()))
+
+	b, err = json.Marshal(NoteFilter{}.JSONLogic())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"!!": [{"var": "path"}]}`, string(b))
+}
@@ -0,0 +1,245 @@
+package obsidian
+
+import (
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Link is a link from a note to another note or file: a wikilink
+// ("[[Note#Heading|Alias]]"), an embed ("![[image.png]]") or a Markdown link
+// to a file in the vault ("[text](Folder/Note.md)").
+type Link struct {
+	// Target is the linked note or file without the subpath, as written
+	// (URL-decoded for Markdown links). It is empty for links within the note.
+	Target string `json:"target"`
+	// Subpath is the heading ("Heading", "H1#H2") or block ("^id") after "#".
+	Subpath string `json:"subpath,omitempty"`
+	Alias   string `json:"alias,omitempty"`
+	Embed   bool   `json:"embed,omitempty"`
+	// Markdown is true for [text](target) links, whose targets are paths
+	// relative to the note.
+	Markdown bool `json:"markdown,omitempty"`
+	// Line is the 1-based line number of the link.
+	Line int `json:"line"`
+}
+
+// ParseLinks returns the links of a note to notes and files, skipping code
+// blocks and inline code. Links to URLs are not included.
+func ParseLinks(content string) []Link {
+	var links []Link
+	inFence := false
+	for i, line := range strings.Split(content, "\n") {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			links = parseLineLinks(line, i+1, links)
+		}
+	}
+	return links
+}
+
+func parseLineLinks(line string, lineNo int, links []Link) []Link {
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '`':
+			i = codeSpanEnd(line, i)
+		case strings.HasPrefix(line[i:], "[["):
+			end := strings.Index(line[i+2:], "]]")
+			if end < 0 {
+				return links
+			}
+			link := parseWikilink(line[i+2 : i+2+end])
+			link.Embed = i > 0 && line[i-1] == '!'
+			link.Line = lineNo
+			links = append(links, link)
+			i += 2 + end + 1
+		case line[i] == '[':
+			link, n, ok := parseMarkdownLink(line[i:])
+			if !ok {
+				continue
+			}
+			link.Embed = i > 0 && line[i-1] == '!'
+			link.Line = lineNo
+			links = append(links, link)
+			i += n - 1
+		}
+	}
+	return links
+}
+
+// codeSpanEnd returns the index of the last backtick of the code span that
+// starts at line[i], which ends with a run of as many backticks. Without
+// one, it returns the end of the opening run.
+func codeSpanEnd(line string, i int) int {
+	n := len(line[i:]) - len(strings.TrimLeft(line[i:], "`"))
+	end := strings.Index(line[i+n:], line[i:i+n])
+	if end < 0 {
+		return i + n - 1
+	}
+	return i + n + end + n - 1
+}
+
+// parseWikilink parses the inside of "[[...]]".
+func parseWikilink(inner string) Link {
+	var link Link
+	target, alias, found := strings.Cut(inner, "|")
+	if found {
+		// In tables, the pipe is escaped: [[Note\|Alias]].
+		target = strings.TrimSuffix(target, `\`)
+		link.Alias = strings.TrimSpace(alias)
+	}
+	target, link.Subpath, _ = strings.Cut(target, "#")
+	link.Target = strings.TrimSpace(target)
+	link.Subpath = strings.TrimSpace(link.Subpath)
+	return link
+}
+
+// parseMarkdownLink parses a "[text](target)" link at the start of s and
+// returns it with its length. It rejects links to URLs.
+func parseMarkdownLink(s string) (Link, int, bool) {
+	text, dest, n, ok := splitMarkdownLink(s)
+	if !ok || strings.Contains(dest, ":") {
+		// A URL such as https://... or mailto:...
+		return Link{}, 0, false
+	}
+	if decoded, err := url.PathUnescape(dest); err == nil {
+		dest = decoded
+	}
+
+	link := Link{Alias: text, Markdown: true}
+	link.Target, link.Subpath, _ = strings.Cut(dest, "#")
+	return link, n, true
+}
+
+// splitMarkdownLink splits a "[text](destination "title")" link at the
+// start of s into its text and destination, and returns its length. As in
+// CommonMark, the destination may be enclosed in angle brackets, and
+// otherwise may contain balanced parentheses.
+func splitMarkdownLink(s string) (text, dest string, n int, ok bool) {
+	closeText := strings.Index(s, "](")
+	if closeText < 0 || strings.Contains(s[1:closeText], "[") {
+		return "", "", 0, false
+	}
+	i := skipSpaces(s, closeText+2)
+	dest, i, ok = linkDestination(s, i)
+	if !ok || dest == "" {
+		return "", "", 0, false
+	}
+	if j := skipSpaces(s, i); j > i && j < len(s) && strings.IndexByte(`"'(`, s[j]) >= 0 {
+		closer := s[j]
+		if closer == '(' {
+			closer = ')'
+		}
+		end := strings.IndexByte(s[j+1:], closer)
+		if end < 0 {
+			return "", "", 0, false
+		}
+		i = j + end + 2 //nolint:mnd // the quotes
+	}
+	i = skipSpaces(s, i)
+	if i >= len(s) || s[i] != ')' {
+		return "", "", 0, false
+	}
+	return s[1:closeText], dest, i + 1, true
+}
+
+// linkDestination returns the destination of a Markdown link that starts at
+// s[i] and the index after it.
+func linkDestination(s string, i int) (string, int, bool) {
+	if i < len(s) && s[i] == '<' {
+		end := strings.IndexAny(s[i+1:], "<>\n")
+		if end < 0 || s[i+1+end] != '>' {
+			return "", 0, false
+		}
+		return s[i+1 : i+1+end], i + end + 2, true //nolint:mnd // the brackets
+	}
+	depth := 0
+	start := i
+	for ; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth == 0 {
+				return s[start:i], i, true
+			}
+			depth--
+		case c == ' ' || c == '\t' || c == '\n':
+			return s[start:i], i, depth == 0
+		}
+	}
+	return "", 0, false
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// LinkIndex resolves link targets to the files of a vault.
+type LinkIndex struct {
+	// paths maps lowercased paths to paths.
+	paths map[string]string
+	// names maps lowercased file names to paths.
+	names map[string][]string
+}
+
+// NewLinkIndex indexes the paths of the files of a vault.
+func NewLinkIndex(files []string) *LinkIndex {
+	x := &LinkIndex{
+		paths: make(map[string]string, len(files)),
+		names: make(map[string][]string, len(files)),
+	}
+	for _, f := range files {
+		lower := strings.ToLower(f)
+		x.paths[lower] = f
+		x.names[path.Base(lower)] = append(x.names[path.Base(lower)], f)
+	}
+	return x
+}
+
+// Resolve returns the path of the file that a link in the note at source
+// points to. Like Obsidian, it matches case-insensitively, adds the ".md"
+// extension to targets without one, and resolves a wikilink that is not a
+// path from the vault root or from the note's folder to the file with the
+// shortest path that ends with the target.
+func (x *LinkIndex) Resolve(link Link, source string) (string, bool) {
+	if link.Target == "" {
+		return source, true
+	}
+	target := strings.ToLower(strings.TrimPrefix(link.Target, "/"))
+	candidates := []string{target, path.Join(path.Dir(strings.ToLower(source)), target)}
+	if link.Markdown {
+		candidates[0], candidates[1] = candidates[1], candidates[0]
+	}
+	for _, c := range candidates {
+		if found, ok := x.paths[c]; ok {
+			return found, true
+		}
+		if found, ok := x.paths[c+".md"]; ok {
+			return found, true
+		}
+	}
+	if link.Markdown {
+		return "", false
+	}
+
+	best := ""
+	name := path.Base(target)
+	for _, p := range slices.Concat(x.names[name], x.names[name+".md"]) {
+		lower := strings.ToLower(p)
+		matches := strings.HasSuffix("/"+lower, "/"+target) || strings.HasSuffix("/"+lower, "/"+target+".md")
+		if matches && (best == "" || len(p) < len(best)) {
+			best = p
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,58 @@
+package obsidian
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLinks(t *testing.T) {
+	content := "---\nrelated: \"[[Index]]\"\n---\n" +
+		"See [[Projects/Plan#Goals|the goals]] and ![[diagram.png]].\n" +
+		"| [[Table\\|alias]] | [doc](Docs/My%20Doc.md#Intro) |\n" +
+		"Skip `[[code]]`, [web](https://example.com) and [[#Local heading]].\n" +
+		"```\n[[fenced]]\n```\n"
+
+	assert.Equal(t, []Link{
+		{Target: "Index", Line: 2},
+		{Target: "Projects/Plan", Subpath: "Goals", Alias: "the goals", Line: 4},
+		{Target: "diagram.png", Embed: true, Line: 4},
+		{Target: "Table", Alias: "alias", Line: 5},
+		{Target: "Docs/My Doc.md", Subpath: "Intro", Alias: "doc", Markdown: true, Line: 5},
+		{Target: "", Subpath: "Local heading", Line: 6},
+	}, ParseLinks(content))
+}
+
+func TestLinkIndex_Resolve(t *testing.T) {
+	index := NewLinkIndex([]string{
+		"Index.md",
+		"Projects/Plan.md",
+		"Archive/Projects/Plan.md",
+		"Projects/Notes/Meeting.md",
+		"assets/diagram.png",
+	})
+
+	tests := []struct {
+		name   string
+		link   Link
+		source string
+		want   string
+	}{
+		{"vault path", Link{Target: "Projects/Plan"}, "Index.md", "Projects/Plan.md"},
+		{"case insensitive", Link{Target: "index"}, "Projects/Plan.md", "Index.md"},
+		{"shortest path", Link{Target: "Plan"}, "Index.md", "Projects/Plan.md"},
+		{"partial path", Link{Target: "Notes/Meeting"}, "Index.md", "Projects/Notes/Meeting.md"},
+		{"attachment", Link{Target: "diagram.png", Embed: true}, "Index.md", "assets/diagram.png"},
+		{"same note", Link{Subpath: "Goals"}, "Projects/Plan.md", "Projects/Plan.md"},
+		{"markdown relative", Link{Target: "Notes/Meeting.md", Markdown: true}, "Projects/Plan.md", "Projects/Notes/Meeting.md"},
+		{"missing", Link{Target: "Nowhere"}, "Index.md", ""},
+		{"markdown by name", Link{Target: "Meeting.md", Markdown: true}, "Index.md", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := index.Resolve(tt.link, tt.source)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want != "", ok)
+		})
+	}
+}
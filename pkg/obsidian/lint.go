@@ -0,0 +1,369 @@
+package obsidian
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lint checks.
+const (
+	LintBrokenLink = "broken_link"
+	// LintEmptyNote reports notes without content besides their
+	// frontmatter: notes that only hold properties are empty too.
+	LintEmptyNote          = "empty_note"
+	LintStubNote           = "stub_note"
+	LintInvalidFrontmatter = "invalid_frontmatter"
+	LintMissingProperty    = "missing_property"
+	LintDuplicateTitle     = "duplicate_title"
+	LintOrphanedAttachment = "orphaned_attachment"
+)
+
+// LintChecks returns the names of all lint checks.
+func LintChecks() []string {
+	return []string{
+		LintBrokenLink,
+		LintEmptyNote,
+		LintStubNote,
+		LintInvalidFrontmatter,
+		LintMissingProperty,
+		LintDuplicateTitle,
+		LintOrphanedAttachment,
+	}
+}
+
+// DefaultStubWords is the default word count below which a note is a stub.
+const DefaultStubWords = 10
+
+// LintOptions configures Lint.
+type LintOptions struct {
+	// Folder limits the checks to notes in the folder and its subfolders.
+	// Links are still resolved against the whole vault. Orphaned attachments
+	// are only reported when the whole vault is checked, because links from
+	// notes outside the folder are not known.
+	Folder string
+	// Checks selects the checks to run. All checks run if it is empty.
+	Checks []string
+	// StubWords is the word count below which a note is a stub.
+	StubWords int
+	// RequiredProperties maps folders to the frontmatter properties that
+	// notes in the folder (and its subfolders) must have. The "" folder
+	// applies to all notes.
+	RequiredProperties map[string][]string
+
+	// unreadNotes is set when some notes could not be read, so that their
+	// links are not known.
+	unreadNotes bool
+}
+
+// LintIssue is a problem found by Lint.
+type LintIssue struct {
+	Check string `json:"check"`
+	// Path is the note (or attachment) with the problem.
+	Path string `json:"path"`
+	// Line is the 1-based line of the problem, if it has one.
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+	// Target is the target of a broken link, or the missing property.
+	Target string `json:"target,omitempty"`
+	// Related lists the other notes with the same title.
+	Related []string `json:"related,omitempty"`
+}
+
+// LintReport is the result of Lint.
+type LintReport struct {
+	// Scanned is the number of notes that were checked.
+	Scanned int         `json:"scanned"`
+	Issues  []LintIssue `json:"issues"`
+	// Counts is the number of issues of each check.
+	Counts map[string]int `json:"counts"`
+	// Failed lists notes that could not be read.
+	Failed []ReadError `json:"failed,omitempty"`
+	// Skipped lists the checks that were not run because notes could not
+	// be read: attachments linked only from those notes would be reported
+	// as orphaned.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// Lint reads the notes of the vault (or of opts.Folder) and reports broken
+// links, empty and stub notes, invalid frontmatter, missing required
+// properties, notes with the same title and orphaned attachments.
+func (s *AnalysisService) Lint(ctx context.Context, opts LintOptions) (*LintReport, error) {
+	files, err := s.client.Vault.ListRecursive(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, f := range files {
+		if isMarkdown(f) && inFolder(f, opts.Folder) {
+			paths = append(paths, f)
+		}
+	}
+	notes, failed, err := readAll(ctx, paths, s.client.Vault.GetNote)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.unreadNotes = len(failed) > 0
+	report := LintNotes(files, notes, opts)
+	report.Failed = failed
+	return report, nil
+}
+
+// LintNotes runs the lint checks on notes, given as a map from path to note.
+// files are the paths of all files of the vault.
+func LintNotes(files []string, notes map[string]*Note, opts LintOptions) *LintReport {
+	if opts.StubWords <= 0 {
+		opts.StubWords = DefaultStubWords
+	}
+	l := &linter{
+		opts:    opts,
+		index:   NewLinkIndex(files),
+		checks:  make(map[string]bool),
+		linked:  make(map[string]bool),
+		headers: make(map[string][]Heading),
+		notes:   notes,
+	}
+	for _, c := range opts.Checks {
+		l.checks[c] = true
+	}
+
+	paths := make([]string, 0, len(notes))
+	for p := range notes {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		l.lintNote(p, notes[p])
+	}
+	l.lintTitles(files)
+	var skipped []string
+	switch {
+	case opts.Folder != "":
+		// Links from notes outside the folder are not known.
+	case opts.unreadNotes:
+		if l.enabled(LintOrphanedAttachment) {
+			skipped = append(skipped, LintOrphanedAttachment)
+		}
+	default:
+		l.lintAttachments(files)
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		if l.issues[i].Path != l.issues[j].Path {
+			return l.issues[i].Path < l.issues[j].Path
+		}
+		return l.issues[i].Line < l.issues[j].Line
+	})
+	report := &LintReport{Scanned: len(notes), Issues: l.issues, Counts: make(map[string]int), Skipped: skipped}
+	if report.Issues == nil {
+		report.Issues = []LintIssue{}
+	}
+	for _, issue := range report.Issues {
+		report.Counts[issue.Check]++
+	}
+	return report
+}
+
+type linter struct {
+	opts   LintOptions
+	index  *LinkIndex
+	checks map[string]bool
+	// linked is the set of files that scanned notes link to.
+	linked map[string]bool
+	// headers caches the headings of linked notes.
+	headers map[string][]Heading
+	notes   map[string]*Note
+	issues  []LintIssue
+}
+
+func (l *linter) enabled(check string) bool {
+	return len(l.checks) == 0 || l.checks[check]
+}
+
+func (l *linter) report(issue LintIssue) {
+	if l.enabled(issue.Check) {
+		l.issues = append(l.issues, issue)
+	}
+}
+
+func (l *linter) lintNote(notePath string, note *Note) {
+	frontmatter, body := SplitFrontmatter(note.Content)
+	validFrontmatter := true
+	if frontmatter != "" {
+		var props map[string]interface{}
+		if err := yaml.Unmarshal([]byte(frontmatter), &props); err != nil {
+			validFrontmatter = false
+			l.report(LintIssue{
+				Check:   LintInvalidFrontmatter,
+				Path:    notePath,
+				Line:    1,
+				Message: fmt.Sprintf("frontmatter is not valid YAML: %v", err),
+			})
+		}
+	}
+
+	if strings.TrimSpace(body) == "" {
+		message := "note has no content"
+		if frontmatter != "" {
+			message = "note has no content besides its frontmatter"
+		}
+		l.report(LintIssue{Check: LintEmptyNote, Path: notePath, Message: message})
+	} else if words := len(strings.Fields(NormalizeContent(body))); words < l.opts.StubWords {
+		l.report(LintIssue{Check: LintStubNote, Path: notePath, Message: fmt.Sprintf("note has only %d words", words)})
+	}
+
+	if validFrontmatter {
+		l.lintProperties(notePath, note.Frontmatter)
+	}
+	l.lintLinks(notePath, note.Content)
+}
+
+func (l *linter) lintProperties(notePath string, props map[string]interface{}) {
+	folders := make([]string, 0, len(l.opts.RequiredProperties))
+	for folder := range l.opts.RequiredProperties {
+		folders = append(folders, folder)
+	}
+	sort.Strings(folders)
+
+	for _, folder := range folders {
+		if !inFolder(notePath, folder) {
+			continue
+		}
+		for _, prop := range l.opts.RequiredProperties[folder] {
+			if !isEmptyValue(props[prop]) {
+				continue
+			}
+			l.report(LintIssue{
+				Check:   LintMissingProperty,
+				Path:    notePath,
+				Message: fmt.Sprintf("required property %q is missing or empty", prop),
+				Target:  prop,
+			})
+		}
+	}
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	case []interface{}:
+		return len(val) == 0
+	default:
+		return false
+	}
+}
+
+func (l *linter) lintLinks(notePath, content string) {
+	for _, link := range ParseLinks(content) {
+		target, ok := l.index.Resolve(link, notePath)
+		if !ok {
+			l.report(LintIssue{
+				Check:   LintBrokenLink,
+				Path:    notePath,
+				Line:    link.Line,
+				Message: fmt.Sprintf("link target %q does not exist", link.Target),
+				Target:  link.Target,
+			})
+			continue
+		}
+		l.linked[target] = true
+
+		if link.Subpath != "" && !l.hasSubpath(target, link.Subpath) {
+			l.report(LintIssue{
+				Check:   LintBrokenLink,
+				Path:    notePath,
+				Line:    link.Line,
+				Message: fmt.Sprintf("%q has no heading or block %q", target, link.Subpath),
+				Target:  link.Target + "#" + link.Subpath,
+			})
+		}
+	}
+}
+
+// hasSubpath reports whether the note at target has the heading or block
+// of subpath. It returns true if the note was not read.
+func (l *linter) hasSubpath(target, subpath string) bool {
+	note, ok := l.notes[target]
+	if !ok {
+		return true
+	}
+	if id, isBlock := strings.CutPrefix(subpath, "^"); isBlock {
+		return strings.Contains(note.Content, "^"+id)
+	}
+
+	headings, ok := l.headers[target]
+	if !ok {
+		headings = ParseHeadings(note.Content)
+		l.headers[target] = headings
+	}
+	// Nested headings ("H1#H2") are matched by their last part.
+	parts := strings.Split(subpath, "#")
+	want := strings.TrimSpace(parts[len(parts)-1])
+	for _, h := range headings {
+		if strings.EqualFold(h.Text, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintTitles reports scanned notes that have the same name as other notes
+// of the vault, which makes wikilinks to them ambiguous.
+func (l *linter) lintTitles(files []string) {
+	byTitle := make(map[string][]string)
+	for _, f := range files {
+		if isMarkdown(f) {
+			title := strings.ToLower(path.Base(f))
+			byTitle[title] = append(byTitle[title], f)
+		}
+	}
+	for _, f := range files {
+		same := byTitle[strings.ToLower(path.Base(f))]
+		if _, scanned := l.notes[f]; !scanned || len(same) < 2 { //nolint:mnd
+			continue
+		}
+		var related []string
+		for _, other := range same {
+			if other != f {
+				related = append(related, other)
+			}
+		}
+		l.report(LintIssue{
+			Check:   LintDuplicateTitle,
+			Path:    f,
+			Message: fmt.Sprintf("%d other notes are named %q", len(related), strings.TrimSuffix(path.Base(f), ".md")),
+			Related: related,
+		})
+	}
+}
+
+// lintAttachments reports files that are not notes and are not linked from
+// any note. Canvases are not attachments, but links from canvases are not
+// followed.
+func (l *linter) lintAttachments(files []string) {
+	for _, f := range files {
+		if isMarkdown(f) || strings.EqualFold(path.Ext(f), ".canvas") || l.linked[f] {
+			continue
+		}
+		l.report(LintIssue{Check: LintOrphanedAttachment, Path: f, Message: "attachment is not linked from any note"})
+	}
+}
+
+func isMarkdown(p string) bool {
+	return strings.EqualFold(path.Ext(p), ".md")
+}
+
+// inFolder reports whether p is in folder or its subfolders. Every path is
+// in the "" folder.
+func inFolder(p, folder string) bool {
+	folder = strings.Trim(folder, "/")
+	return folder == "" || strings.HasPrefix(p, folder+"/")
+}
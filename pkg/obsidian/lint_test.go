@@ -0,0 +1,137 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintNotes(t *testing.T) {
+	files := []string{
+		"Home.md",
+		"Projects/Alpha.md",
+		"Projects/Beta.md",
+		"Archive/Alpha.md",
+		"Empty.md",
+		"assets/used.png",
+		"assets/unused.pdf",
+		"Board.canvas",
+	}
+	body := "This note has enough words in it to not be considered a stub by the checks. "
+	notes := map[string]*Note{
+		"Home.md": {Content: body + "Links: [[Alpha]], [[Missing note]], [[Projects/Beta#Status]], [[Projects/Beta#Nope]]\n![[used.png]]\n"},
+		"Projects/Alpha.md": {
+			Content:     "---\nstatus: active\n---\n" + body,
+			Frontmatter: map[string]interface{}{"status": "active"},
+		},
+		"Projects/Beta.md": {Content: "---\nstatus: [unclosed\n---\n# Status\nShort.\n"},
+		"Archive/Alpha.md": {Content: "Old draft.\n"},
+		"Empty.md":         {Content: "---\ntags: []\n---\n\n"},
+	}
+	opts := LintOptions{RequiredProperties: map[string][]string{"Projects": {"status", "owner"}}}
+
+	report := LintNotes(files, notes, opts)
+	assert.Equal(t, 5, report.Scanned)
+
+	type issue struct{ check, path, target string }
+	var got []issue
+	for _, i := range report.Issues {
+		got = append(got, issue{i.Check, i.Path, i.Target})
+	}
+	assert.Equal(t, []issue{
+		{LintStubNote, "Archive/Alpha.md", ""},
+		{LintDuplicateTitle, "Archive/Alpha.md", ""},
+		{LintEmptyNote, "Empty.md", ""},
+		{LintBrokenLink, "Home.md", "Missing note"},
+		{LintBrokenLink, "Home.md", "Projects/Beta#Nope"},
+		{LintMissingProperty, "Projects/Alpha.md", "owner"},
+		{LintDuplicateTitle, "Projects/Alpha.md", ""},
+		{LintStubNote, "Projects/Beta.md", ""},
+		{LintInvalidFrontmatter, "Projects/Beta.md", ""},
+		{LintOrphanedAttachment, "assets/unused.pdf", ""},
+	}, got)
+	assert.Equal(t, 2, report.Counts[LintBrokenLink])
+	assert.Equal(t, []string{"Projects/Alpha.md"}, report.Issues[1].Related)
+	assert.Equal(t, 1, report.Issues[3].Line)
+	assert.Equal(t, "note has no content besides its frontmatter", report.Issues[2].Message)
+
+	// Selected checks only, and no orphan check for a folder.
+	opts.Checks = []string{LintBrokenLink, LintOrphanedAttachment}
+	opts.Folder = "Projects"
+	report = LintNotes(files, map[string]*Note{"Home.md": notes["Home.md"], "Projects/Beta.md": notes["Projects/Beta.md"]}, opts)
+	assert.Equal(t, 2, report.Counts[LintBrokenLink])
+	assert.Len(t, report.Issues, 2)
+}
+
+func TestAnalysis_Lint(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string][]string{"files": {"Index.md", "Notes/"}})
+		case "/vault/Notes/":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string][]string{"files": {"a.md"}})
+		case "/vault/Notes/a.md":
+			assert.Equal(t, "application/vnd.olrapi.note+json", r.Header.Get("Accept"))
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(Note{Path: "Notes/a.md", Content: "Links to [[Index]] and [[Gone]]."})
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errorCode": 40400, "message": "Not Found"}`)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	report, err := client.Analysis.Lint(context.Background(), LintOptions{Folder: "Notes", Checks: []string{LintBrokenLink}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Scanned)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, LintIssue{
+		Check:   LintBrokenLink,
+		Path:    "Notes/a.md",
+		Line:    1,
+		Message: `link target "Gone" does not exist`,
+		Target:  "Gone",
+	}, report.Issues[0])
+}
+
+func TestAnalysis_Lint_UnreadNotes(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string][]string{"files": {"Index.md", "Broken.md", "chart.png"}})
+		case "/vault/Index.md":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(Note{Path: "Index.md", Content: "No links here."})
+		case "/vault/Broken.md":
+			// Broken.md may embed chart.png.
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"errorCode": 50000, "message": "Internal Server Error"}`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	report, err := client.Analysis.Lint(context.Background(), LintOptions{Checks: []string{LintOrphanedAttachment}})
+	require.NoError(t, err)
+	assert.Empty(t, report.Issues)
+	require.Len(t, report.Failed, 1)
+	assert.Equal(t, []string{LintOrphanedAttachment}, report.Skipped)
+}
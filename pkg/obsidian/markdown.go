@@ -0,0 +1,74 @@
+package obsidian
+
+import (
+	"strings"
+)
+
+// Heading is an ATX heading ("## Title") in a Markdown document.
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	// Line is the 1-based line number of the heading.
+	Line int `json:"line"`
+}
+
+// ParseHeadings returns the headings of a Markdown document, skipping
+// fenced code blocks.
+func ParseHeadings(content string) []Heading {
+	var headings []Heading
+	inFence := false
+	for i, line := range strings.Split(content, "\n") {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if level, text, ok := parseHeading(line); ok {
+			headings = append(headings, Heading{Level: level, Text: text, Line: i + 1})
+		}
+	}
+	return headings
+}
+
+// HeadingAtLine returns the closest heading at or before the given 1-based line.
+func HeadingAtLine(content string, line int) (Heading, bool) {
+	var found Heading
+	ok := false
+	for _, h := range ParseHeadings(content) {
+		if h.Line > line {
+			break
+		}
+		found, ok = h, true
+	}
+	return found, ok
+}
+
+const maxHeadingLevel = 6
+
+func parseHeading(line string) (int, string, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > maxHeadingLevel {
+		return 0, "", false
+	}
+	if level < len(line) && line[level] != ' ' && line[level] != '\t' {
+		return 0, "", false
+	}
+
+	text := strings.TrimSpace(line[level:])
+	// Optional closing sequence: "## Title ##"
+	if trimmed := strings.TrimRight(text, "#"); trimmed != text && (trimmed == "" || strings.HasSuffix(trimmed, " ")) {
+		text = strings.TrimSpace(trimmed)
+	}
+	return level, text, true
+}
+
+func isFence(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
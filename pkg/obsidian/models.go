@@ -1,5 +1,7 @@
 package obsidian
 
+import "errors"
+
 // Note represents the JSON structure of a note returned by the API.
 // It corresponds to the 'NoteJson' schema in the OpenAPI spec.
 type Note struct {
@@ -28,6 +30,13 @@ func (e *ErrorResponse) Error() string {
 	return e.Message
 }
 
+// IsNotFound reports whether err is an API error for a missing file or note.
+func IsNotFound(err error) bool {
+	var apiErr *ErrorResponse
+	// Error codes are the HTTP status followed by two digits, e.g. 40400.
+	return errors.As(err, &apiErr) && apiErr.ErrorCode/100 == 404 //nolint:mnd
+}
+
 type PatchOperation string
 
 const (
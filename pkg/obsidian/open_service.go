@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 // OpenService handles opening files in the Obsidian UI.
@@ -28,3 +29,13 @@ func (s *OpenService) File(ctx context.Context, filename string, newLeaf bool) e
 
 	return s.client.do(req, nil)
 }
+
+// FileAt opens the specified file in Obsidian and scrolls to subpath,
+// which is a heading ("Heading", or "Heading#Subheading") or a block reference ("^block-id").
+func (s *OpenService) FileAt(ctx context.Context, filename, subpath string, newLeaf bool) error {
+	if subpath == "" {
+		return s.File(ctx, filename, newLeaf)
+	}
+	// Obsidian resolves the opened path as a link, so "file#subpath" works the same as in [[file#subpath]].
+	return s.File(ctx, filename+"#"+strings.TrimPrefix(subpath, "#"), newLeaf)
+}
@@ -32,3 +32,20 @@ func TestClient_Open_File(t *testing.T) {
 	err = client.Open.File(context.Background(), "my file.md", true)
 	require.NoError(t, err)
 }
+
+func TestClient_Open_FileAt(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/open/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/open/note.md#Heading#Sub", r.URL.Path)
+		assert.Empty(t, r.URL.Query().Get("newLeaf"))
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	err = client.Open.FileAt(context.Background(), "note.md", "#Heading#Sub", false)
+	require.NoError(t, err)
+}
@@ -0,0 +1,58 @@
+package obsidian
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PatchOption configures a PATCH request.
+type PatchOption func(*patchOptions)
+
+type patchOptions struct {
+	createTargetIfMissing bool
+	targetDelimiter       string
+}
+
+// WithCreateTargetIfMissing creates the target heading, block or frontmatter
+// field if it does not exist, instead of failing the request.
+func WithCreateTargetIfMissing() PatchOption {
+	return func(o *patchOptions) {
+		o.createTargetIfMissing = true
+	}
+}
+
+// WithTargetDelimiter sets the delimiter of nested heading targets (default "::").
+func WithTargetDelimiter(delimiter string) PatchOption {
+	return func(o *patchOptions) {
+		o.targetDelimiter = delimiter
+	}
+}
+
+// newPatchRequest creates a PATCH request for the API endpoint u.
+func newPatchRequest(ctx context.Context, u *url.URL, op PatchOperation, targetType TargetType, target string, content string, opts []PatchOption) (*http.Request, error) {
+	var o patchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PATCH", u.String(), strings.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Operation", string(op))
+	req.Header.Set("Target-Type", string(targetType))
+	req.Header.Set("Target", target)
+	// The API also accepts JSON (e.g. for table rows or frontmatter values),
+	// but markdown covers the common cases.
+	req.Header.Set("Content-Type", "text/markdown")
+	if o.createTargetIfMissing {
+		req.Header.Set("Create-Target-If-Missing", "true")
+	}
+	if o.targetDelimiter != "" {
+		req.Header.Set("Target-Delimiter", o.targetDelimiter)
+	}
+	return req, nil
+}
@@ -54,18 +54,13 @@ func (s *PeriodicService) AppendToCurrent(ctx context.Context, period, content s
 }
 
 // PatchCurrent updates the current periodic note.
-func (s *PeriodicService) PatchCurrent(ctx context.Context, period string, op PatchOperation, targetType TargetType, target string, content string) error {
+func (s *PeriodicService) PatchCurrent(ctx context.Context, period string, op PatchOperation, targetType TargetType, target string, content string, opts ...PatchOption) error {
 	u := s.client.baseURL.ResolveReference(&url.URL{Path: fmt.Sprintf("periodic/%s/", period)})
-	req, err := http.NewRequestWithContext(ctx, "PATCH", u.String(), strings.NewReader(content))
+	req, err := newPatchRequest(ctx, u, op, targetType, target, content, opts)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Operation", string(op))
-	req.Header.Set("Target-Type", string(targetType))
-	req.Header.Set("Target", target)
-	req.Header.Set("Content-Type", "text/markdown")
-
 	return s.client.do(req, nil)
 }
 
@@ -0,0 +1,606 @@
+package obsidian
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// renderer renders parsed Markdown as HTML or as plain text.
+type renderer struct {
+	plain bool
+	// idPrefix is prepended to the IDs of headings and blocks, to keep them
+	// unique in a document with several notes.
+	idPrefix string
+	// link returns the URL of a link to a note or file of the vault, or
+	// false if the link cannot be followed in the rendered document.
+	link func(Link) (string, bool)
+	// embed renders an embed. Embeds are rendered as links if it is nil.
+	embed func(Link) string
+	// ids counts the heading IDs in use.
+	ids map[string]int
+}
+
+// render renders blocks, separated by blank lines in plain text.
+func (r *renderer) render(blocks []*mdBlock) string {
+	return r.renderBlocks(blocks, "\n\n")
+}
+
+func (r *renderer) renderBlocks(blocks []*mdBlock, textSep string) string {
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		if r.plain {
+			parts = append(parts, r.blockText(b))
+		} else {
+			parts = append(parts, r.blockHTML(b))
+		}
+	}
+	if r.plain {
+		return strings.Join(parts, textSep)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func (r *renderer) blockHTML(b *mdBlock) string {
+	switch b.kind {
+	case blockHeading:
+		return fmt.Sprintf("<h%d%s>%s</h%d>", b.level, r.headingIDAttr(b.text), r.inline(b.text), b.level)
+	case blockParagraph:
+		if link, ok := soleEmbed(b.text); ok && b.id == "" && r.embed != nil {
+			// An embed on its own line is a block, not part of a paragraph.
+			return r.embed(link)
+		}
+		return "<p" + r.blockIDAttr(b.id) + ">" + r.inline(b.text) + "</p>"
+	case blockCode:
+		class := ""
+		if b.lang != "" {
+			class = ` class="language-` + html.EscapeString(b.lang) + `"`
+		}
+		return "<pre><code" + class + ">" + html.EscapeString(b.text) + "\n</code></pre>"
+	case blockMath:
+		return `<div class="math">$$` + html.EscapeString(b.text) + `$$</div>`
+	case blockQuote:
+		return "<blockquote>\n" + r.render(b.children) + "\n</blockquote>"
+	case blockCallout:
+		return fmt.Sprintf("<div class=\"callout\" data-callout=\"%s\">\n<div class=\"callout-title\">%s</div>\n"+
+			"<div class=\"callout-content\">\n%s\n</div>\n</div>", html.EscapeString(b.lang), r.inline(b.title), r.render(b.children))
+	case blockList:
+		return r.listHTML(b)
+	case blockTable:
+		return r.tableHTML(b)
+	case blockRule:
+		return "<hr>"
+	}
+	return ""
+}
+
+// headingIDAttr returns the id attribute of a heading, made unique in the document.
+func (r *renderer) headingIDAttr(text string) string {
+	id := headingID(text)
+	if id == "" {
+		return ""
+	}
+	id = r.idPrefix + id
+	if r.ids == nil {
+		r.ids = make(map[string]int)
+	}
+	n := r.ids[id]
+	r.ids[id]++
+	if n > 0 {
+		id += "-" + strconv.Itoa(n)
+	}
+	return ` id="` + html.EscapeString(id) + `"`
+}
+
+func (r *renderer) blockIDAttr(id string) string {
+	if id == "" {
+		return ""
+	}
+	return ` id="` + html.EscapeString(r.idPrefix+blockAnchor(id)) + `"`
+}
+
+// blockAnchor returns the HTML ID of a block ("^id").
+func blockAnchor(id string) string {
+	return "block-" + id
+}
+
+func (r *renderer) listHTML(b *mdBlock) string {
+	var sb strings.Builder
+	tag := "ul"
+	if b.ordered {
+		tag = "ol"
+	}
+	sb.WriteString("<" + tag)
+	if b.ordered && b.start != 1 {
+		fmt.Fprintf(&sb, ` start="%d"`, b.start)
+	}
+	sb.WriteString(">\n")
+	for _, item := range b.items {
+		if !item.task {
+			sb.WriteString("<li>")
+		} else {
+			checked := ""
+			if item.checked {
+				checked = " checked"
+			}
+			sb.WriteString(`<li class="task-list-item"><input type="checkbox" disabled` + checked + "> ")
+		}
+		sb.WriteString(r.itemHTML(item, b.loose))
+		sb.WriteString("</li>\n")
+	}
+	sb.WriteString("</" + tag + ">")
+	return sb.String()
+}
+
+// itemHTML renders the blocks of a list item. The paragraphs of items of
+// tight lists are not wrapped in <p>.
+func (r *renderer) itemHTML(item listItem, loose bool) string {
+	paragraphs := 0
+	for _, b := range item.blocks {
+		if b.kind == blockParagraph {
+			paragraphs++
+		}
+	}
+	parts := make([]string, 0, len(item.blocks))
+	for _, b := range item.blocks {
+		if b.kind != blockParagraph || loose || paragraphs > 1 {
+			parts = append(parts, r.blockHTML(b))
+			continue
+		}
+		text := r.inline(b.text)
+		if b.id != "" {
+			text = "<span" + r.blockIDAttr(b.id) + ">" + text + "</span>"
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func (r *renderer) tableHTML(b *mdBlock) string {
+	var sb strings.Builder
+	sb.WriteString("<table>\n<thead>\n")
+	for i, row := range b.rows {
+		cell := "td"
+		if i == 0 {
+			cell = "th"
+		}
+		if i == 1 {
+			sb.WriteString("</thead>\n<tbody>\n")
+		}
+		sb.WriteString("<tr>")
+		for j, align := range b.align {
+			text := ""
+			if j < len(row) {
+				text = row[j]
+			}
+			style := ""
+			if align != "" {
+				style = ` style="text-align: ` + align + `"`
+			}
+			sb.WriteString("<" + cell + style + ">" + r.inline(text) + "</" + cell + ">")
+		}
+		sb.WriteString("</tr>\n")
+	}
+	if len(b.rows) > 1 {
+		sb.WriteString("</tbody>\n</table>")
+	} else {
+		sb.WriteString("</thead>\n</table>")
+	}
+	return sb.String()
+}
+
+func (r *renderer) blockText(b *mdBlock) string {
+	switch b.kind {
+	case blockHeading, blockParagraph:
+		return r.inline(b.text)
+	case blockCode:
+		return indentLines(b.text, "    ", "    ")
+	case blockMath:
+		return b.text
+	case blockQuote:
+		return indentLines(r.render(b.children), "> ", "> ")
+	case blockCallout:
+		return indentLines(strings.TrimRight(r.inline(b.title)+"\n"+r.render(b.children), "\n"), "> ", "> ")
+	case blockList:
+		return r.listText(b)
+	case blockTable:
+		rows := make([]string, 0, len(b.rows))
+		for _, row := range b.rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = r.inline(cell)
+			}
+			rows = append(rows, strings.Join(cells, " | "))
+		}
+		return strings.Join(rows, "\n")
+	case blockRule:
+		return "---"
+	}
+	return ""
+}
+
+func (r *renderer) listText(b *mdBlock) string {
+	sep := "\n"
+	if b.loose {
+		sep = "\n\n"
+	}
+	items := make([]string, 0, len(b.items))
+	for i, item := range b.items {
+		marker := "- "
+		if b.ordered {
+			marker = strconv.Itoa(b.start+i) + ". "
+		}
+		indent := strings.Repeat(" ", len(marker))
+		if item.task && item.checked {
+			marker += "[x] "
+		} else if item.task {
+			marker += "[ ] "
+		}
+		content := r.renderBlocks(item.blocks, sep)
+		items = append(items, indentLines(content, marker, indent))
+	}
+	return strings.Join(items, sep)
+}
+
+// indentLines prefixes the first line of s with first and the others with
+// rest, without trailing spaces on empty lines.
+func indentLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" {
+			prefix = strings.TrimRight(prefix, " ")
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// soleEmbed returns the embed that is the whole text of a paragraph.
+func soleEmbed(text string) (Link, bool) {
+	inner, ok := strings.CutPrefix(text, "![[")
+	if !ok {
+		return Link{}, false
+	}
+	inner, ok = strings.CutSuffix(inner, "]]")
+	if !ok || strings.Contains(inner, "]]") || strings.Contains(inner, "\n") {
+		return Link{}, false
+	}
+	link := parseWikilink(inner)
+	link.Embed = true
+	return link, true
+}
+
+// inline renders inline Markdown.
+func (r *renderer) inline(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if out, n := r.inlineAt(s, i); n > 0 {
+			sb.WriteString(out)
+			i += n
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		sb.WriteString(r.text(s[i : i+size]))
+		i += size
+	}
+	return sb.String()
+}
+
+// text renders literal text.
+func (r *renderer) text(s string) string {
+	if r.plain {
+		return s
+	}
+	return html.EscapeString(s)
+}
+
+// inlineAt renders the inline element that starts at s[i], and returns the
+// output and the number of bytes it spans, or 0 if there is no element.
+func (r *renderer) inlineAt(s string, i int) (string, int) {
+	rest := s[i:]
+	switch rest[0] {
+	case '\\':
+		if len(rest) > 1 && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", rest[1]) >= 0 {
+			return r.text(rest[1:2]), 2
+		}
+	case '\n':
+		if r.plain {
+			return "\n", 1
+		}
+		return "<br>\n", 1
+	case '`':
+		return r.codeSpan(rest)
+	case '!', '[':
+		return r.linkAt(rest)
+	case '<':
+		return r.autolink(rest)
+	case '#':
+		if i == 0 || s[i-1] == ' ' || s[i-1] == '\t' || s[i-1] == '\n' {
+			return r.tag(rest)
+		}
+	case '$':
+		return r.math(rest)
+	case 'h':
+		if i == 0 || !isWordByte(s[i-1]) {
+			return r.bareURL(rest)
+		}
+	}
+	return r.emphasisAt(s, i)
+}
+
+func isWordByte(b byte) bool {
+	return b >= utf8.RuneSelf || b == '_' || unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b))
+}
+
+// codeSpanLength returns the length of the code span at the start of s, or
+// 0 if its backticks are not closed.
+func codeSpanLength(s string) int {
+	ticks := len(s) - len(strings.TrimLeft(s, "`"))
+	end := strings.Index(s[ticks:], s[:ticks])
+	if end < 0 {
+		return 0
+	}
+	return ticks + end + ticks
+}
+
+func (r *renderer) codeSpan(s string) (string, int) {
+	ticks := len(s) - len(strings.TrimLeft(s, "`"))
+	n := codeSpanLength(s)
+	if n == 0 {
+		return r.text(s[:ticks]), ticks
+	}
+	code := strings.ReplaceAll(s[ticks:n-ticks], "\n", " ")
+	if strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") && strings.TrimSpace(code) != "" {
+		code = code[1 : len(code)-1]
+	}
+	if r.plain {
+		return code, n
+	}
+	return "<code>" + html.EscapeString(code) + "</code>", n
+}
+
+// linkAt renders a wikilink, embed, Markdown link or image.
+func (r *renderer) linkAt(s string) (string, int) {
+	embed := s[0] == '!'
+	body := s
+	if embed {
+		body = s[1:]
+	}
+	skip := len(s) - len(body)
+
+	if strings.HasPrefix(body, "[[") {
+		end := strings.Index(body[2:], "]]")
+		if end < 0 {
+			return "", 0
+		}
+		link := parseWikilink(body[2 : 2+end])
+		link.Embed = embed
+		return r.internalLink(link), skip + end + 4 //nolint:mnd // "[[" and "]]"
+	}
+
+	if !strings.HasPrefix(body, "[") {
+		return "", 0
+	}
+	text, dest, n, ok := splitMarkdownLink(body)
+	if !ok {
+		return "", 0
+	}
+	if strings.Contains(dest, ":") {
+		if !embed {
+			return r.externalLink(dest, r.inline(text)), skip + n
+		}
+		if r.plain || !isWebURL(dest) {
+			return r.text(text), skip + n
+		}
+		return `<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(text) + `">`, skip + n
+	}
+	link, _, _ := parseMarkdownLink(body)
+	link.Embed = embed
+	return r.internalLink(link), skip + n
+}
+
+// internalLink renders a link to a note or file of the vault.
+func (r *renderer) internalLink(link Link) string {
+	if link.Embed && r.embed != nil {
+		return r.embed(link)
+	}
+	label := r.text(linkLabel(link))
+	if link.Alias != "" {
+		label = r.inline(link.Alias)
+	}
+	if r.plain {
+		return label
+	}
+	href, ok := "", false
+	if r.link != nil {
+		href, ok = r.link(link)
+	}
+	if !ok {
+		return `<span class="internal-link unresolved">` + label + `</span>`
+	}
+	return `<a class="internal-link" href="` + html.EscapeString(href) + `">` + label + `</a>`
+}
+
+// linkLabel returns the text that Obsidian shows for a link without an
+// alias: "Note", "Note > Heading" or "Heading".
+func linkLabel(link Link) string {
+	subpath := strings.ReplaceAll(link.Subpath, "#", " > ")
+	switch {
+	case link.Subpath == "":
+		return link.Target
+	case link.Target == "":
+		return subpath
+	default:
+		return link.Target + " > " + subpath
+	}
+}
+
+// externalLink renders a link to a web page or an email address. Other
+// URLs, like "javascript:" ones, are rendered as their label: exports are
+// shared outside the vault.
+func (r *renderer) externalLink(href, label string) string {
+	if !isURL(href) {
+		return label
+	}
+	if r.plain {
+		if label == href || strings.TrimPrefix(href, "mailto:") == label {
+			return label
+		}
+		return label + " (" + href + ")"
+	}
+	return `<a class="external-link" href="` + html.EscapeString(href) + `">` + label + `</a>`
+}
+
+// autolink renders "<https://example.com>".
+func (r *renderer) autolink(s string) (string, int) {
+	end := strings.IndexByte(s, '>')
+	if end < 0 {
+		return "", 0
+	}
+	href := s[1:end]
+	if strings.ContainsAny(href, " \t\n<") || !isURL(href) {
+		return "", 0
+	}
+	return r.externalLink(href, r.text(href)), end + 1
+}
+
+// bareURL renders a URL in the text, which Obsidian turns into a link.
+func (r *renderer) bareURL(s string) (string, int) {
+	if !strings.HasPrefix(s, "https://") && !strings.HasPrefix(s, "http://") {
+		return "", 0
+	}
+	end := strings.IndexAny(s, " \t\n<")
+	if end < 0 {
+		end = len(s)
+	}
+	href := strings.TrimRight(s[:end], ".,;:!?\"')*_~")
+	if !strings.Contains(href, "://") || strings.HasSuffix(href, "://") {
+		return "", 0
+	}
+	return r.externalLink(href, r.text(href)), len(href)
+}
+
+// isURL reports whether s is a URL that exports link to: a web page or an
+// email address.
+func isURL(s string) bool {
+	return isWebURL(s) || strings.HasPrefix(strings.ToLower(s), "mailto:")
+}
+
+func isWebURL(s string) bool {
+	lower := strings.ToLower(s)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// tag renders a tag ("#tag", "#nested/tag"). Tags must have a character
+// that is not a digit, so that "#1" is text.
+func (r *renderer) tag(s string) (string, int) {
+	n := 1
+	for n < len(s) {
+		c, size := utf8.DecodeRuneInString(s[n:])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '-' && c != '/' {
+			break
+		}
+		n += size
+	}
+	if strings.Trim(s[1:n], "0123456789") == "" {
+		return "", 0
+	}
+	if r.plain {
+		return s[:n], n
+	}
+	return `<span class="tag">` + html.EscapeString(s[:n]) + `</span>`, n
+}
+
+// math renders inline math ("$x^2$"). Like Obsidian, the dollar signs must
+// not be next to spaces on the inside, and the closing one must not be
+// followed by a digit, so that prices stay text.
+func (r *renderer) math(s string) (string, int) {
+	if len(s) < len("$x$") || s[1] == ' ' || s[1] == '$' {
+		return "", 0
+	}
+	for j := 1; j < len(s); j++ {
+		if s[j] != '$' || s[j-1] == ' ' || s[j-1] == '\\' || (j+1 < len(s) && s[j+1] >= '0' && s[j+1] <= '9') {
+			continue
+		}
+		if r.plain {
+			return s[:j+1], j + 1
+		}
+		return `<span class="math">` + html.EscapeString(s[:j+1]) + `</span>`, j + 1
+	}
+	return "", 0
+}
+
+// emphasisDelimiters returns the delimiters of inline formatting and their
+// HTML elements, longest first.
+func emphasisDelimiters() [][2]string {
+	return [][2]string{
+		{"**", "strong"}, {"__", "strong"}, {"~~", "del"}, {"==", "mark"}, {"*", "em"}, {"_", "em"},
+	}
+}
+
+func (r *renderer) emphasisAt(s string, i int) (string, int) {
+	rest := s[i:]
+	for _, e := range emphasisDelimiters() {
+		delim, tag := e[0], e[1]
+		if !strings.HasPrefix(rest, delim) {
+			continue
+		}
+		d := len(delim)
+		if len(rest) <= d || rest[d] == ' ' || rest[d] == '\n' {
+			return "", 0
+		}
+		if delim[0] == '_' && i > 0 && isWordByte(s[i-1]) {
+			// Underscores within words, as in snake_case, are text.
+			return "", 0
+		}
+		end := closingDelimiter(rest, d, delim)
+		if end < 0 {
+			continue
+		}
+		inner := r.inline(rest[d:end])
+		if r.plain {
+			return inner, end + d
+		}
+		return "<" + tag + ">" + inner + "</" + tag + ">", end + d
+	}
+	return "", 0
+}
+
+// closingDelimiter returns the index in s of the delimiter that closes the
+// one at the start of s, skipping escapes, code spans and wikilinks, or -1.
+func closingDelimiter(s string, from int, delim string) int {
+	for j := from; j < len(s); j++ {
+		switch {
+		case s[j] == '\\':
+			j++
+		case s[j] == '`':
+			if n := codeSpanLength(s[j:]); n > 0 {
+				j += n - 1
+			}
+		case strings.HasPrefix(s[j:], "[["):
+			if end := strings.Index(s[j:], "]]"); end > 0 {
+				j += end + 1
+			}
+		case strings.HasPrefix(s[j:], delim) && s[j-1] != ' ' && s[j-1] != '\n':
+			run := len(s[j:]) - len(strings.TrimLeft(s[j:], delim[:1]))
+			if len(delim) == 1 && run == len("**") {
+				// A "**" inside "*...*" opens or closes strong emphasis.
+				j++
+				continue
+			}
+			j += run - len(delim)
+			if delim == "_" && j+1 < len(s) && isWordByte(s[j+1]) {
+				continue
+			}
+			return j
+		}
+	}
+	return -1
+}
@@ -0,0 +1,122 @@
+package obsidian
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRenderer(plain bool) *renderer {
+	return &renderer{
+		plain: plain,
+		link: func(link Link) (string, bool) {
+			return "#" + headingID(link.Target), link.Target != "Missing"
+		},
+		embed: func(link Link) string { return "[embed " + link.Target + "]" },
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	tests := []struct {
+		name, markdown, want string
+	}{
+		{
+			name:     "heading",
+			markdown: "## Next steps ##",
+			want:     `<h2 id="next-steps">Next steps</h2>`,
+		},
+		{
+			name:     "emphasis",
+			markdown: "**bold** *em* _em_ ==mark== ~~del~~ **strong *and em***",
+			want:     "<p><strong>bold</strong> <em>em</em> <em>em</em> <mark>mark</mark> <del>del</del> <strong>strong <em>and em</em></strong></p>",
+		},
+		{
+			name:     "text stays text",
+			markdown: "snake_case, 2 * 3 * 4, costs $5 or $10, issue #12, a <b> tag & `*code*`",
+			want:     "<p>snake_case, 2 * 3 * 4, costs $5 or $10, issue #12, a &lt;b&gt; tag &amp; <code>*code*</code></p>",
+		},
+		{
+			name:     "links",
+			markdown: "[[Project Plan]], [[Plan#Goals|the goals]], [[Missing]], [site](https://example.com), <https://a.org>, see https://b.org/x.",
+			want: `<p><a class="internal-link" href="#project-plan">Project Plan</a>, <a class="internal-link" href="#plan">the goals</a>, ` +
+				`<span class="internal-link unresolved">Missing</span>, <a class="external-link" href="https://example.com">site</a>, ` +
+				`<a class="external-link" href="https://a.org">https://a.org</a>, see <a class="external-link" href="https://b.org/x">https://b.org/x</a>.</p>`,
+		},
+		{
+			name:     "only web and mail links",
+			markdown: "[click](javascript:alert(1)), [open](obsidian://open?vault=x), [mail](mailto:a@b.org), ![x](javascript:alert(1)) ![logo](https://a.org/l.png)",
+			want: `<p>click, open, <a class="external-link" href="mailto:a@b.org">mail</a>, x ` +
+				`<img src="https://a.org/l.png" alt="logo"></p>`,
+		},
+		{
+			name:     "link destinations",
+			markdown: "[Foo](https://en.wikipedia.org/wiki/Foo_(bar)) and [doc](<https://a.org/my doc> \"Doc (v2)\").",
+			want: `<p><a class="external-link" href="https://en.wikipedia.org/wiki/Foo_(bar)">Foo</a> and ` +
+				`<a class="external-link" href="https://a.org/my doc">doc</a>.</p>`,
+		},
+		{
+			name:     "tags, math and line breaks",
+			markdown: "#project/alpha and $e^{i\\pi}$\nnext line ^intro",
+			want:     `<p id="block-intro"><span class="tag">#project/alpha</span> and <span class="math">$e^{i\pi}$</span><br>` + "\nnext line</p>",
+		},
+		{
+			name:     "embed on its own line",
+			markdown: "![[Diagram.png|300]]",
+			want:     "[embed Diagram.png]",
+		},
+		{
+			name:     "lists",
+			markdown: "- one\n- [ ] two\n  1. nested\n- [x] three\n\n3. c\n4. d",
+			want: "<ul>\n<li>one</li>\n<li class=\"task-list-item\"><input type=\"checkbox\" disabled> two\n<ol>\n<li>nested</li>\n</ol></li>\n" +
+				"<li class=\"task-list-item\"><input type=\"checkbox\" disabled checked> three</li>\n</ul>\n" +
+				"<ol start=\"3\">\n<li>c</li>\n<li>d</li>\n</ol>",
+		},
+		{
+			name:     "loose list",
+			markdown: "- one\n\n- two",
+			want:     "<ul>\n<li><p>one</p></li>\n<li><p>two</p></li>\n</ul>",
+		},
+		{
+			name:     "code",
+			markdown: "```go\nif a < b {\n```",
+			want:     "<pre><code class=\"language-go\">if a &lt; b {\n</code></pre>",
+		},
+		{
+			name:     "callout and quote",
+			markdown: "> [!tip]\n> Use it.\n\n> Said.",
+			want: "<div class=\"callout\" data-callout=\"tip\">\n<div class=\"callout-title\">Tip</div>\n<div class=\"callout-content\">\n<p>Use it.</p>\n</div>\n</div>\n" +
+				"<blockquote>\n<p>Said.</p>\n</blockquote>",
+		},
+		{
+			name:     "table",
+			markdown: "| Name | Count |\n| :--- | ---: |\n| [[Missing\\|m]] | 2 |",
+			want: "<table>\n<thead>\n<tr><th style=\"text-align: left\">Name</th><th style=\"text-align: right\">Count</th></tr>\n</thead>\n" +
+				"<tbody>\n<tr><td style=\"text-align: left\"><span class=\"internal-link unresolved\">m</span></td><td style=\"text-align: right\">2</td></tr>\n</tbody>\n</table>",
+		},
+		{
+			name:     "comments and rules",
+			markdown: "Visible %%hidden%% text\n%%\nblock comment\n%%\n\n***",
+			want:     "<p>Visible  text</p>\n<hr>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, testRenderer(false).render(parseMarkdown(tt.markdown)))
+		})
+	}
+}
+
+func TestRenderHTML_UniqueHeadingIDs(t *testing.T) {
+	r := testRenderer(false)
+	r.idPrefix = "notes-a--"
+	got := r.render(parseMarkdown("# Notes\n# Notes"))
+	assert.Equal(t, "<h1 id=\"notes-a--notes\">Notes</h1>\n<h1 id=\"notes-a--notes-1\">Notes</h1>", got)
+}
+
+func TestRenderText(t *testing.T) {
+	markdown := "# Plan\n\nSee [[Goals|the goals]] and [docs](https://example.com), **now**.\n\n" +
+		"- [ ] write\n  - draft\n- [x] review\n\n> [!note] Remember\n> Be brief.\n\n```\ncode\n```\n\n| A | B |\n|---|---|\n| 1 | 2 |"
+	want := "Plan\n\nSee the goals and docs (https://example.com), now.\n\n" +
+		"- [ ] write\n  - draft\n- [x] review\n\n> Remember\n> Be brief.\n\n    code\n\nA | B\n1 | 2"
+	assert.Equal(t, want, testRenderer(true).render(parseMarkdown(markdown)))
+}
@@ -0,0 +1,262 @@
+package obsidian
+
+import (
+	"context"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Defaults for ResolveNote.
+const (
+	DefaultResolveLimit = 5
+	// DefaultResolveMinScore is the lowest score of a match.
+	DefaultResolveMinScore = 0.5
+)
+
+// Scores of a name whose words contain those of the query.
+const (
+	wordPrefixScore = 0.9
+	// minWordScore is the lowest similarity of a misspelled word.
+	minWordScore = 0.75
+	// maxWordsScore is the score of names that contain all words of the
+	// query and no others; only the exact name scores 1.
+	maxWordsScore = 0.95
+	// extraWordsWeight is the part of the score that drops as the name
+	// has more words than the query.
+	extraWordsWeight = 0.25
+)
+
+// Names that a query is matched against.
+const (
+	MatchFilename = "filename"
+	MatchAlias    = "alias"
+	MatchTitle    = "title"
+	// MatchPath is used for queries with a folder ("Projects/Plan").
+	MatchPath = "path"
+)
+
+// NoteMatch is a note whose name matches a query.
+type NoteMatch struct {
+	Path string `json:"path"`
+	// Matched is the name that matched: the file name, an alias or the
+	// title (the first H1) of the note.
+	Matched string `json:"matched"`
+	// Field is what Matched is: "filename", "alias", "title" or "path".
+	Field string `json:"field"`
+	// Score is 1 for an exact match (ignoring case and punctuation) and
+	// lower for partial and misspelled matches.
+	Score float64 `json:"score"`
+}
+
+// NoteResolution lists the notes that match a name, best first.
+type NoteResolution struct {
+	Query   string      `json:"query"`
+	Scanned int         `json:"scanned"`
+	Matches []NoteMatch `json:"matches"`
+	// Failed lists notes that could not be read.
+	Failed []ReadError `json:"failed,omitempty"`
+}
+
+// ResolveNote reads the notes of the vault and returns the limit notes
+// whose file name, frontmatter aliases or title best match name.
+func (s *AnalysisService) ResolveNote(ctx context.Context, name string, limit int) (*NoteResolution, error) {
+	paths, err := s.markdownFiles(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return s.resolve(ctx, name, limit, paths, paths)
+}
+
+// ResolveNoteIn is ResolveNote for the notes in folder and its subfolders.
+// Only the notes directly in folder are read: the notes of its subfolders
+// are matched by their file name.
+func (s *AnalysisService) ResolveNoteIn(ctx context.Context, folder, name string, limit int) (*NoteResolution, error) {
+	paths, err := s.markdownFiles(ctx, folder)
+	if err != nil {
+		return nil, err
+	}
+	dir := strings.Trim(folder, "/")
+	if dir == "" {
+		dir = "."
+	}
+	var direct []string
+	for _, p := range paths {
+		if path.Dir(p) == dir {
+			direct = append(direct, p)
+		}
+	}
+	return s.resolve(ctx, name, limit, paths, direct)
+}
+
+// resolve matches name against the notes at paths, reading those in read.
+func (s *AnalysisService) resolve(ctx context.Context, name string, limit int, paths, read []string) (*NoteResolution, error) {
+	notes, failed, err := readAll(ctx, read, s.client.Vault.GetNote)
+	if err != nil {
+		return nil, err
+	}
+
+	// Notes that were not read, or could not be read, can still match by
+	// file name.
+	for _, p := range paths {
+		if notes[p] == nil {
+			notes[p] = &Note{}
+		}
+	}
+	return &NoteResolution{
+		Query:   name,
+		Scanned: len(paths),
+		Matches: MatchNotes(name, notes, limit),
+		Failed:  failed,
+	}, nil
+}
+
+// MatchNotes scores the names of notes, given as a map from path to note,
+// against query and returns the limit best matches with a score of at
+// least DefaultResolveMinScore. Each note is listed once, with its best
+// matching name.
+func MatchNotes(query string, notes map[string]*Note, limit int) []NoteMatch {
+	if limit <= 0 {
+		limit = DefaultResolveLimit
+	}
+	q := normalizeName(strings.TrimSuffix(query, ".md"))
+	if q == "" {
+		return nil
+	}
+
+	var matches []NoteMatch
+	for p, note := range notes {
+		names := noteNames(p, note)
+		if strings.Contains(query, "/") {
+			names = append(names, NoteMatch{Matched: strings.TrimSuffix(p, path.Ext(p)), Field: MatchPath})
+		}
+		best := NoteMatch{Path: p}
+		for _, name := range names {
+			if score := nameScore(q, normalizeName(name.Matched)); score > best.Score {
+				best.Matched, best.Field, best.Score = name.Matched, name.Field, score
+			}
+		}
+		if best.Score >= DefaultResolveMinScore {
+			best.Score = math.Round(best.Score*1000) / 1000 //nolint:mnd
+			matches = append(matches, best)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Path < matches[j].Path
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// noteNames returns the names of a note, in order of preference when they
+// match equally well.
+func noteNames(notePath string, note *Note) []NoteMatch {
+	names := []NoteMatch{{Matched: noteTitle(notePath), Field: MatchFilename}}
+	for _, key := range []string{"aliases", "alias"} {
+		switch v := note.Frontmatter[key].(type) {
+		case string:
+			// Old notes have comma-separated aliases.
+			for _, alias := range strings.Split(v, ",") {
+				names = append(names, NoteMatch{Matched: strings.TrimSpace(alias), Field: MatchAlias})
+			}
+		case []interface{}:
+			for _, alias := range v {
+				if s, ok := alias.(string); ok {
+					names = append(names, NoteMatch{Matched: s, Field: MatchAlias})
+				}
+			}
+		}
+	}
+	_, body := SplitFrontmatter(note.Content)
+	for _, h := range ParseHeadings(body) {
+		if h.Level == 1 {
+			names = append(names, NoteMatch{Matched: h.Text, Field: MatchTitle})
+			break
+		}
+	}
+	return names
+}
+
+// normalizeName lowercases a name and collapses punctuation and
+// whitespace to single spaces.
+func normalizeName(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// nameScore scores how well a normalized name matches a normalized query:
+// by the edit distance of the whole name, or by how many words of the
+// query the name contains, exactly, as a prefix or misspelled.
+func nameScore(query, name string) float64 {
+	if query == name {
+		return 1
+	}
+	if name == "" {
+		return 0
+	}
+	score := similarity(query, name)
+
+	queryWords, nameWords := strings.Fields(query), strings.Fields(name)
+	found := 0.0
+	for _, qw := range queryWords {
+		best := 0.0
+		for _, nw := range nameWords {
+			switch {
+			case qw == nw:
+				best = 1
+			case strings.HasPrefix(nw, qw):
+				best = max(best, wordPrefixScore)
+			default:
+				if s := similarity(qw, nw); s >= minWordScore {
+					best = max(best, s)
+				}
+			}
+		}
+		found += best
+	}
+	coverage := found / float64(len(queryWords))
+	extra := min(1, float64(len(queryWords))/float64(len(nameWords)))
+	return max(score, maxWordsScore*coverage*(1-extraWordsWeight+extraWordsWeight*extra))
+}
+
+// similarity is 1 minus the edit distance of a and b relative to the
+// length of the longer one.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the number of single-rune insertions, deletions and
+// substitutions that turn a into b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range a {
+		cur[0] = i + 1
+		for j := range b {
+			cost := 1
+			if a[i] == b[j] {
+				cost = 0
+			}
+			cur[j+1] = min(prev[j+1]+1, cur[j]+1, prev[j]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,87 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resolveTestNotes() map[string]*Note {
+	return map[string]*Note{
+		"Work/2024-Q3 Planning.md": {Content: "# Planning for the third quarter\n"},
+		"Work/Q3 Review.md":        {Content: "Notes.\n"},
+		"People/Jane Doe.md": {
+			Content:     "---\naliases: [JD, Jane]\n---\n# Jane\n",
+			Frontmatter: map[string]interface{}{"aliases": []interface{}{"JD", "Jane"}},
+		},
+		"Ideas/untitled 3.md": {Content: "# Garden automation\n\nWater the plants.\n"},
+		"Old/Recipes.md": {
+			Content:     "---\nalias: Cookbook, Kitchen\n---\n",
+			Frontmatter: map[string]interface{}{"alias": "Cookbook, Kitchen"},
+		},
+	}
+}
+
+func TestMatchNotes(t *testing.T) {
+	tests := []struct {
+		query, path, matched, field string
+		score                       float64
+	}{
+		{query: "Q3 review", path: "Work/Q3 Review.md", matched: "Q3 Review", field: MatchFilename, score: 1},
+		{query: "q3-review.md", path: "Work/Q3 Review.md", matched: "Q3 Review", field: MatchFilename, score: 1},
+		{query: "JD", path: "People/Jane Doe.md", matched: "JD", field: MatchAlias, score: 1},
+		{query: "kitchen", path: "Old/Recipes.md", matched: "Kitchen", field: MatchAlias, score: 1},
+		{query: "garden automation", path: "Ideas/untitled 3.md", matched: "Garden automation", field: MatchTitle, score: 1},
+		{query: "Q3 planing", path: "Work/2024-Q3 Planning.md", matched: "2024-Q3 Planning", field: MatchFilename, score: 0.816},
+		{query: "Work/Q3 Review", path: "Work/Q3 Review.md", matched: "Work/Q3 Review", field: MatchPath, score: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			matches := MatchNotes(tt.query, resolveTestNotes(), 0)
+			require.NotEmpty(t, matches)
+			assert.Equal(t, NoteMatch{Path: tt.path, Matched: tt.matched, Field: tt.field, Score: tt.score}, matches[0])
+		})
+	}
+}
+
+func TestMatchNotes_Ranking(t *testing.T) {
+	matches := MatchNotes("q3", resolveTestNotes(), 1)
+	assert.Equal(t, []NoteMatch{{Path: "Work/Q3 Review.md", Matched: "Q3 Review", Field: MatchFilename, Score: 0.831}}, matches)
+
+	assert.Empty(t, MatchNotes("zebra", resolveTestNotes(), 0))
+	assert.Empty(t, MatchNotes("!?", resolveTestNotes(), 0))
+}
+
+func TestAnalysis_ResolveNote(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vault/":
+			_ = json.NewEncoder(w).Encode(map[string][]string{"files": {"Meeting notes.md", "image.png", "Broken.md"}})
+		case "/vault/Meeting notes.md":
+			_ = json.NewEncoder(w).Encode(Note{Path: "Meeting notes.md", Content: "# Weekly sync\n"})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	res, err := client.Analysis.ResolveNote(context.Background(), "weekly sync", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Scanned)
+	assert.Equal(t, []NoteMatch{{Path: "Meeting notes.md", Matched: "Weekly sync", Field: MatchTitle, Score: 1}}, res.Matches)
+	require.Len(t, res.Failed, 1)
+
+	// Notes that cannot be read still match by file name.
+	res, err = client.Analysis.ResolveNote(context.Background(), "broken", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Broken.md", res.Matches[0].Path)
+}
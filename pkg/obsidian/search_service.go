@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 )
 
@@ -16,15 +17,60 @@ type SearchService struct {
 
 // SearchResult represents a single match in Simple search.
 type SearchResult struct {
-	Filename string  `json:"filename"`
-	Score    float64 `json:"score"`
-	Matches  []struct {
-		Context string `json:"context"`
-		Match   struct {
-			Start int `json:"start"`
-			End   int `json:"end"`
-		} `json:"match"`
-	} `json:"matches"`
+	Filename string        `json:"filename"`
+	Score    float64       `json:"score"`
+	Matches  []SearchMatch `json:"matches"`
+}
+
+// SearchMatch is a single occurrence of the query within a file.
+type SearchMatch struct {
+	Context string    `json:"context"`
+	Match   MatchSpan `json:"match"`
+}
+
+// MatchSpan is the position of a match within the file content.
+type MatchSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// DedupeMatches removes matches whose context overlaps the context of an
+// earlier match. contextLength is the value that was passed to Simple.
+func (r *SearchResult) DedupeMatches(contextLength int) {
+	if len(r.Matches) < 2 { //nolint:mnd
+		return
+	}
+
+	matches := make([]SearchMatch, len(r.Matches))
+	copy(matches, r.Matches)
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Match.Start < matches[j].Match.Start
+	})
+
+	kept := matches[:1]
+	for _, m := range matches[1:] {
+		last := kept[len(kept)-1]
+		if m.Context == last.Context || m.Match.Start-contextLength < last.Match.End+contextLength {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	r.Matches = kept
+}
+
+// SortSearchResults orders results by descending score, then by the number
+// of matches and the filename.
+func SortSearchResults(results []SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if len(a.Matches) != len(b.Matches) {
+			return len(a.Matches) > len(b.Matches)
+		}
+		return a.Filename < b.Filename
+	})
 }
 
 // Simple performs a simple text search.
@@ -51,6 +97,29 @@ func (s *SearchService) Simple(ctx context.Context, query string, contextLength
 type JSONLogicResult struct {
 	Filename string      `json:"filename"`
 	Result   interface{} `json:"result"`
+
+	// raw keeps the undecoded result, so that the order of object keys
+	// (e.g. Dataview TABLE columns) is not lost.
+	raw json.RawMessage
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *JSONLogicResult) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Filename string          `json:"filename"`
+		Result   json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	r.Filename = aux.Filename
+	r.Result = nil
+	r.raw = aux.Result
+	if len(aux.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(aux.Result, &r.Result)
 }
 
 // JSONLogic performs a structured search using JsonLogic.
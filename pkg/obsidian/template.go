@@ -0,0 +1,17 @@
+package obsidian
+
+import (
+	"strings"
+	"time"
+)
+
+// RenderTemplate fills in the core Templates plugin variables of a note
+// template: {{date}}, {{time}} and {{title}}. Formats such as
+// {{date:YYYY-MM-DD}} are not supported and are left as they are.
+func RenderTemplate(tmpl, title string, now time.Time) string {
+	return strings.NewReplacer(
+		"{{date}}", now.Format(time.DateOnly),
+		"{{time}}", now.Format("15:04"),
+		"{{title}}", title,
+	).Replace(tmpl)
+}
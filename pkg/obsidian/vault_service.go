@@ -1,9 +1,11 @@
 package obsidian
 
 import (
+	"bytes"
 	"context"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 )
 
@@ -31,6 +33,36 @@ func (s *VaultService) List(ctx context.Context, path string) ([]string, error)
 	return resp.Files, err
 }
 
+// ListRecursive lists all files under a directory (the whole vault if dir is
+// empty), including files in subdirectories. Paths are relative to the vault root.
+func (s *VaultService) ListRecursive(ctx context.Context, dir string) ([]string, error) {
+	dir = strings.Trim(dir, "/")
+	if dir != "" {
+		dir += "/"
+	}
+
+	var files []string
+	pending := []string{dir}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+
+		entries, err := s.List(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if strings.HasSuffix(entry, "/") {
+				pending = append(pending, current+entry)
+			} else {
+				files = append(files, current+entry)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
 // Get returns the content of a file in the vault.
 func (s *VaultService) Get(ctx context.Context, path string) (string, error) {
 	u := s.client.baseURL.ResolveReference(&url.URL{Path: "vault/" + path})
@@ -70,6 +102,43 @@ func (s *VaultService) Create(ctx context.Context, path, content string) error {
 	return s.client.do(req, nil)
 }
 
+// Upload creates a new file or replaces an existing one with data of any
+// type, like an image or a PDF document.
+func (s *VaultService) Upload(ctx context.Context, path string, data []byte, contentType string) error {
+	u := s.client.baseURL.ResolveReference(&url.URL{Path: "vault/" + path})
+	req, err := http.NewRequestWithContext(ctx, "PUT", u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	return s.client.do(req, nil)
+}
+
+// Append appends content to the end of a file in the vault.
+// The file is created if it does not exist.
+func (s *VaultService) Append(ctx context.Context, path, content string) error {
+	u := s.client.baseURL.ResolveReference(&url.URL{Path: "vault/" + path})
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), strings.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/markdown")
+
+	return s.client.do(req, nil)
+}
+
+// Patch inserts content into a file relative to a heading, block reference or frontmatter field.
+func (s *VaultService) Patch(ctx context.Context, path string, op PatchOperation, targetType TargetType, target string, content string, opts ...PatchOption) error {
+	u := s.client.baseURL.ResolveReference(&url.URL{Path: "vault/" + path})
+	req, err := newPatchRequest(ctx, u, op, targetType, target, content, opts)
+	if err != nil {
+		return err
+	}
+
+	return s.client.do(req, nil)
+}
+
 // Delete deletes a file in the vault.
 func (s *VaultService) Delete(ctx context.Context, path string) error {
 	u := s.client.baseURL.ResolveReference(&url.URL{Path: "vault/" + path})
@@ -0,0 +1,132 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// DefaultConfigDir is the default name of the vault's configuration folder.
+const DefaultConfigDir = ".obsidian"
+
+// WorkspaceService reads the layout of the Obsidian workspace.
+//
+// The Local REST API has no endpoint for the workspace, so the layout is read
+// from the workspace.json file that Obsidian saves in the configuration folder.
+// Obsidian saves it shortly after every change, so it may lag behind the UI.
+type WorkspaceService struct {
+	client *Client
+}
+
+// WorkspaceLayout describes the open tabs of the main area.
+type WorkspaceLayout struct {
+	// Tabs are the leaves of the main area, grouped by tab group.
+	Tabs []WorkspaceLeaf `json:"tabs"`
+	// ActiveLeaf is the focused leaf, which may be in a sidebar.
+	ActiveLeaf *WorkspaceLeaf `json:"active_leaf,omitempty"`
+	// RecentFiles are the most recently opened files.
+	RecentFiles []string `json:"recent_files,omitempty"`
+}
+
+// WorkspaceLeaf is a single tab (view) in the workspace.
+type WorkspaceLeaf struct {
+	ID string `json:"id"`
+	// Type is the view type, e.g. "markdown", "graph" or "empty".
+	Type string `json:"type"`
+	File string `json:"file,omitempty"`
+	// Group is the index of the tab group (split) in the main area.
+	Group int `json:"group"`
+	// Visible is true for the selected tab of its group.
+	Visible bool `json:"visible"`
+	Active  bool `json:"active"`
+	Pinned  bool `json:"pinned,omitempty"`
+}
+
+type workspaceItem struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Children   []workspaceItem `json:"children"`
+	CurrentTab int             `json:"currentTab"`
+	Pinned     bool            `json:"pinned"`
+	State      *struct {
+		Type  string `json:"type"`
+		State struct {
+			File string `json:"file"`
+		} `json:"state"`
+	} `json:"state"`
+}
+
+type workspaceFile struct {
+	Main          *workspaceItem `json:"main"`
+	Left          *workspaceItem `json:"left"`
+	Right         *workspaceItem `json:"right"`
+	Active        string         `json:"active"`
+	LastOpenFiles []string       `json:"lastOpenFiles"`
+}
+
+// Layout returns the current workspace layout.
+func (s *WorkspaceService) Layout(ctx context.Context) (*WorkspaceLayout, error) {
+	raw, err := s.client.Vault.Get(ctx, DefaultConfigDir+"/workspace.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var ws workspaceFile
+	if err := json.Unmarshal([]byte(raw), &ws); err != nil {
+		return nil, fmt.Errorf("failed to parse workspace.json: %w", err)
+	}
+
+	layout := &WorkspaceLayout{
+		Tabs:        []WorkspaceLeaf{},
+		RecentFiles: ws.LastOpenFiles,
+	}
+	group := -1
+	collectLeaves(ws.Main, &group, false, &layout.Tabs)
+
+	for i := range layout.Tabs {
+		if layout.Tabs[i].ID == ws.Active {
+			layout.Tabs[i].Active = true
+			leaf := layout.Tabs[i]
+			layout.ActiveLeaf = &leaf
+		}
+	}
+	if layout.ActiveLeaf == nil && ws.Active != "" {
+		var side []WorkspaceLeaf
+		g := -1
+		collectLeaves(ws.Left, &g, false, &side)
+		collectLeaves(ws.Right, &g, false, &side)
+		for _, leaf := range side {
+			if leaf.ID == ws.Active {
+				leaf.Active = true
+				leaf.Group = -1
+				layout.ActiveLeaf = &leaf
+			}
+		}
+	}
+	return layout, nil
+}
+
+func collectLeaves(item *workspaceItem, group *int, visible bool, out *[]WorkspaceLeaf) {
+	if item == nil {
+		return
+	}
+
+	switch item.Type {
+	case "leaf":
+		leaf := WorkspaceLeaf{ID: item.ID, Group: *group, Visible: visible, Pinned: item.Pinned}
+		if item.State != nil {
+			leaf.Type = item.State.Type
+			leaf.File = item.State.State.File
+		}
+		*out = append(*out, leaf)
+	case "tabs":
+		*group++
+		for i := range item.Children {
+			collectLeaves(&item.Children[i], group, i == item.CurrentTab, out)
+		}
+	default:
+		for i := range item.Children {
+			collectLeaves(&item.Children[i], group, visible, out)
+		}
+	}
+}
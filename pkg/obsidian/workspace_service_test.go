@@ -0,0 +1,73 @@
+package obsidian
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspace_Layout(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/vault/.obsidian/workspace.json", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{
+  "main": {"id": "m", "type": "split", "children": [
+    {"id": "t1", "type": "tabs", "currentTab": 1, "children": [
+      {"id": "a", "type": "leaf", "state": {"type": "markdown", "state": {"file": "a.md"}}},
+      {"id": "b", "type": "leaf", "pinned": true, "state": {"type": "markdown", "state": {"file": "b.md"}}}
+    ]},
+    {"id": "t2", "type": "tabs", "children": [
+      {"id": "c", "type": "leaf", "state": {"type": "graph", "state": {}}}
+    ]}
+  ]},
+  "left": {"id": "l", "type": "split", "children": [
+    {"id": "t3", "type": "tabs", "children": [
+      {"id": "e", "type": "leaf", "state": {"type": "file-explorer", "state": {}}}
+    ]}
+  ]},
+  "active": "b",
+  "lastOpenFiles": ["b.md", "a.md"]
+}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token")
+	require.NoError(t, err)
+
+	layout, err := client.Workspace.Layout(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []WorkspaceLeaf{
+		{ID: "a", Type: "markdown", File: "a.md", Group: 0},
+		{ID: "b", Type: "markdown", File: "b.md", Group: 0, Visible: true, Active: true, Pinned: true},
+		{ID: "c", Type: "graph", Group: 1, Visible: true},
+	}, layout.Tabs)
+	require.NotNil(t, layout.ActiveLeaf)
+	assert.Equal(t, "b.md", layout.ActiveLeaf.File)
+	assert.Equal(t, []string{"b.md", "a.md"}, layout.RecentFiles)
+}
+
+func TestHeadingAtLine(t *testing.T) {
+	content := "# Title\n\n```\n# not a heading\n```\n\n## Section ##\ntext\n#tag\n"
+
+	assert.Equal(t, []Heading{
+		{Level: 1, Text: "Title", Line: 1},
+		{Level: 2, Text: "Section", Line: 7},
+	}, ParseHeadings(content))
+
+	h, ok := HeadingAtLine(content, 8)
+	require.True(t, ok)
+	assert.Equal(t, "Section", h.Text)
+
+	h, ok = HeadingAtLine(content, 4)
+	require.True(t, ok)
+	assert.Equal(t, "Title", h.Text)
+
+	_, ok = HeadingAtLine("text\n# Later", 1)
+	assert.False(t, ok)
+}
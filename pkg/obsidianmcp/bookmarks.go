@@ -0,0 +1,143 @@
+package obsidianmcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// bookmarkEntry is a bookmark flattened for the tool response.
+type bookmarkEntry struct {
+	// Group is the path of the groups containing the bookmark, e.g. "Work/Projects".
+	Group   string `json:"group,omitempty"`
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	Path    string `json:"path,omitempty"`
+	Subpath string `json:"subpath,omitempty"`
+	Query   string `json:"query,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+func flattenBookmarks(items []obsidian.Bookmark, group string, out []bookmarkEntry) []bookmarkEntry {
+	for _, b := range items {
+		if b.Type == obsidian.BookmarkGroup {
+			out = flattenBookmarks(b.Items, strings.TrimPrefix(group+"/"+b.Title, "/"), out)
+			continue
+		}
+		out = append(out, bookmarkEntry{
+			Group:   group,
+			Type:    b.Type,
+			Title:   b.DisplayTitle(),
+			Path:    b.Path,
+			Subpath: b.Subpath,
+			Query:   b.Query,
+			URL:     b.URL,
+		})
+	}
+	return out
+}
+
+// ListBookmarksTool returns the tool definition
+func ListBookmarksTool() mcp.Tool {
+	return mcp.NewTool("obsidian_list_bookmarks",
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("List the bookmarked notes, folders, headings and searches of the vault, with their bookmark groups. "+
+			"Bookmarks are the user's curated key notes."),
+		mcp.WithString("group", mcp.Description("Only list bookmarks in this group (and its subgroups), e.g. \"Work\"")),
+	)
+}
+
+// ListBookmarksHandler returns the tool handler
+func ListBookmarksHandler(client *obsidian.Client) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+		group := strings.Trim(stringArg(args, "group"), "/")
+
+		bookmarks, err := client.Bookmarks.List(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list bookmarks: %v", err)), nil
+		}
+
+		entries := flattenBookmarks(bookmarks, "", []bookmarkEntry{})
+		if group != "" {
+			filtered := []bookmarkEntry{}
+			for _, e := range entries {
+				if e.Group == group || strings.HasPrefix(e.Group, group+"/") {
+					filtered = append(filtered, e)
+				}
+			}
+			entries = filtered
+		}
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"bookmarks": entries,
+		})
+	}
+}
+
+// AddBookmarkTool returns the tool definition
+func AddBookmarkTool() mcp.Tool {
+	return mcp.NewTool("obsidian_add_bookmark",
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithDescription("Bookmark a note (or a heading or block in it). "+
+			"Obsidian picks up the change when the Bookmarks plugin is reloaded."),
+		mcp.WithString("path", mcp.Required(), mcp.Description("Path to the note")),
+		mcp.WithString("subpath", mcp.Description("Heading (\"#Heading\") or block (\"#^id\") to bookmark")),
+		mcp.WithString("title", mcp.Description("Title of the bookmark (defaults to the note name)")),
+		mcp.WithString("group", mcp.Description("Group to add the bookmark to; nested groups are separated by \"/\"")),
+	)
+}
+
+// AddBookmarkHandler returns the tool handler
+func AddBookmarkHandler(client *obsidian.Client) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+		bookmark := obsidian.Bookmark{
+			Type:  obsidian.BookmarkFile,
+			Path:  stringArg(args, "path"),
+			Title: stringArg(args, "title"),
+		}
+		if subpath := stringArg(args, "subpath"); subpath != "" {
+			bookmark.Subpath = "#" + strings.TrimPrefix(subpath, "#")
+			bookmark.Type = obsidian.BookmarkHeading
+			if strings.HasPrefix(bookmark.Subpath, "#^") {
+				bookmark.Type = obsidian.BookmarkBlock
+			}
+		}
+
+		if err := client.Bookmarks.Add(ctx, bookmark, stringArg(args, "group")); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to add bookmark: %v", err)), nil
+		}
+		return mcp.NewToolResultText("Bookmark added successfully"), nil
+	}
+}
+
+// RemoveBookmarkTool returns the tool definition
+func RemoveBookmarkTool() mcp.Tool {
+	return mcp.NewTool("obsidian_remove_bookmark",
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("Remove all bookmarks of a note, in any group"),
+		mcp.WithString("path", mcp.Required(), mcp.Description("Path to the note")),
+	)
+}
+
+// RemoveBookmarkHandler returns the tool handler
+func RemoveBookmarkHandler(client *obsidian.Client) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+		removed, err := client.Bookmarks.Remove(ctx, stringArg(args, "path"))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to remove bookmark: %v", err)), nil
+		}
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"removed": removed,
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package obsidianmcp
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListBookmarks(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/vault/.obsidian/bookmarks.json", r.URL.Path)
+		fmt.Fprint(w, `{"items": [
+			{"type": "file", "path": "Home.md"},
+			{"type": "group", "title": "Work", "items": [
+				{"type": "group", "title": "Projects", "items": [
+					{"type": "file", "path": "Work/Alpha.md", "title": "Project Alpha"}
+				]}
+			]}
+		]}`)
+	}
+
+	res := testTool(t, ListBookmarksTool(), ListBookmarksHandler, "obsidian_list_bookmarks", map[string]interface{}{
+		"group": "Work",
+	}, handler)
+	logMsg(t, res)
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, `"group":"Work/Projects"`)
+	assert.Contains(t, text.Text, `"title":"Project Alpha"`)
+	assert.NotContains(t, text.Text, "Home.md")
+}
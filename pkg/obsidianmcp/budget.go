@@ -0,0 +1,201 @@
+package obsidianmcp
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Tools that can return large responses accept max_bytes (or max_tokens) and
+// offset arguments. Content that does not fit is cut at a heading or paragraph
+// boundary, and the response carries "truncated": true and "next_offset",
+// which can be passed back as offset to get the rest.
+
+const (
+	defaultMaxResponseBytes = 20000
+	bytesPerToken           = 4
+	maxFitAttempts          = 3
+)
+
+// budget is the maximum size of a tool response in bytes.
+type budget int
+
+// withBudgetArgs adds the max_bytes and max_tokens arguments to a tool.
+func withBudgetArgs() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithNumber("max_bytes", mcp.Description("Maximum size of the response in bytes"),
+			mcp.DefaultNumber(defaultMaxResponseBytes))(t)
+		mcp.WithNumber("max_tokens", mcp.Description("Maximum size of the response in tokens (overrides max_bytes)"))(t)
+	}
+}
+
+// withOffsetArg adds the offset argument used to continue a truncated response.
+func withOffsetArg(description string) mcp.ToolOption {
+	return mcp.WithNumber("offset", mcp.Description(description), mcp.DefaultNumber(0))
+}
+
+func budgetFromArgs(args map[string]interface{}) budget {
+	if tokens := intArg(args, "max_tokens", 0); tokens > 0 {
+		return budget(tokens * bytesPerToken)
+	}
+	if b := intArg(args, "max_bytes", 0); b > 0 {
+		return budget(b)
+	}
+	return defaultMaxResponseBytes
+}
+
+// textChunk is the part of a text that fits in a budget.
+type textChunk struct {
+	Text       string
+	Truncated  bool
+	NextOffset int
+}
+
+// text returns the part of content starting at byte offset that fits in b.
+func (b budget) text(content string, offset int) textChunk {
+	if offset > len(content) {
+		offset = len(content)
+	}
+	for offset > 0 && offset < len(content) && !utf8.RuneStart(content[offset]) {
+		offset--
+	}
+
+	rest := content[offset:]
+	cut := cutPoint(rest, int(b))
+	if cut >= len(rest) {
+		return textChunk{Text: rest}
+	}
+	return textChunk{Text: rest[:cut], Truncated: true, NextOffset: offset + cut}
+}
+
+// cutPoint returns where to cut s so that it is at most maxBytes bytes long.
+// It prefers to cut before a heading, then at a paragraph, line or word
+// boundary, as long as that keeps at least half of the budget.
+func cutPoint(s string, maxBytes int) int {
+	if len(s) <= maxBytes {
+		return len(s)
+	}
+	if maxBytes <= 0 {
+		return 0
+	}
+
+	window := s[:maxBytes]
+	minCut := maxBytes / 2 //nolint:mnd
+	if i := strings.LastIndex(window, "\n#"); i >= minCut {
+		return i + 1
+	}
+	for _, sep := range []string{"\n\n", "\n", " "} {
+		if i := strings.LastIndex(window, sep); i >= minCut {
+			return i + len(sep)
+		}
+	}
+
+	for maxBytes > 0 && !utf8.RuneStart(s[maxBytes]) {
+		maxBytes--
+	}
+	return maxBytes
+}
+
+// fitItems returns the leading items whose JSON encoding fits in b.
+// At least one item is returned (if there are any) so that paging always
+// makes progress.
+func fitItems[T any](items []T, b budget) []T {
+	used := 0
+	for i, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return items[:i]
+		}
+		used += len(data) + 1
+		if used > int(b) && i > 0 {
+			return items[:i]
+		}
+	}
+	return items
+}
+
+// fitLines is fitItems for items that are rendered as text by render.
+func fitLines[T any](items []T, b budget, render func(T) string) []T {
+	used := 0
+	for i, item := range items {
+		used += len(render(item))
+		if used > int(b) && i > 0 {
+			return items[:i]
+		}
+	}
+	return items
+}
+
+// noteResponse is a note whose content may have been truncated to fit a budget.
+type noteResponse struct {
+	*obsidian.Note
+	Offset     int  `json:"offset,omitempty"`
+	TotalBytes int  `json:"total_bytes"`
+	Truncated  bool `json:"truncated"`
+	NextOffset int  `json:"next_offset,omitempty"`
+	// Embeds are the expanded note embeds, if embeds were expanded.
+	Embeds []obsidian.Embed `json:"embeds,omitempty"`
+	// Resolved is the match for a path that does not exist, whose note
+	// was returned instead.
+	Resolved *obsidian.NoteMatch `json:"resolved,omitempty"`
+}
+
+// newNoteResponse fits the content of note, starting at offset, in b.
+func newNoteResponse(note *obsidian.Note, offset int, b budget) *noteResponse {
+	resp := &noteResponse{
+		Note:       note,
+		Offset:     offset,
+		TotalBytes: len(note.Content),
+	}
+
+	content := note.Content
+	note.Content = ""
+	overhead := 0
+	if data, err := json.Marshal(resp); err == nil {
+		overhead = len(data)
+	}
+
+	contentBudget := b - budget(overhead)
+	if minBudget := b / 4; contentBudget < minBudget { //nolint:mnd
+		contentBudget = minBudget
+	}
+
+	// JSON escaping makes the encoded content larger than the text itself,
+	// so shrink the content budget until the encoded response fits.
+	var chunk textChunk
+	for range maxFitAttempts {
+		chunk = contentBudget.text(content, offset)
+		note.Content = chunk.Text
+		resp.Truncated = chunk.Truncated
+		resp.NextOffset = chunk.NextOffset
+
+		data, err := json.Marshal(resp)
+		if err != nil {
+			break
+		}
+		excess := budget(len(data)) - b
+		if excess <= 0 || contentBudget-excess < b/4 { //nolint:mnd
+			break
+		}
+		contentBudget -= excess
+	}
+	return resp
+}
+
+// pageResponse builds the response for a page of items starting at offset,
+// out of total items. truncated reports whether the budget cut the page short.
+func pageResponse[T any](key string, items []T, offset, total int, truncated bool) map[string]interface{} {
+	resp := map[string]interface{}{
+		key:         items,
+		"total":     total,
+		"offset":    offset,
+		"truncated": truncated,
+	}
+	if next := offset + len(items); next < total {
+		resp["next_offset"] = next
+	}
+	return resp
+}
@@ -0,0 +1,51 @@
+package obsidianmcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudget_Text(t *testing.T) {
+	content := "# Title\n\nFirst paragraph.\n\n## Section\n\nSecond paragraph."
+
+	chunk := budget(40).text(content, 0)
+	assert.True(t, chunk.Truncated)
+	assert.Equal(t, "# Title\n\nFirst paragraph.\n\n", chunk.Text)
+	assert.Equal(t, len(chunk.Text), chunk.NextOffset)
+
+	rest := budget(40).text(content, chunk.NextOffset)
+	assert.False(t, rest.Truncated)
+	assert.Equal(t, "## Section\n\nSecond paragraph.", rest.Text)
+
+	assert.Equal(t, content, budget(1000).text(content, 0).Text)
+	assert.Empty(t, budget(10).text(content, len(content)+5).Text)
+}
+
+func TestCutPoint(t *testing.T) {
+	// No boundary in the second half of the window: cut on a rune boundary.
+	s := strings.Repeat("é", 10)
+	cut := cutPoint(s, 5)
+	assert.Equal(t, 4, cut)
+	assert.Equal(t, len(s), cutPoint(s, 100))
+	assert.Equal(t, 6, cutPoint("hello world again", 10))
+}
+
+func TestFitItems(t *testing.T) {
+	items := []string{"aaaa", "bbbb", "cccc"}
+	assert.Equal(t, []string{"aaaa", "bbbb"}, fitItems(items, 15))
+	assert.Equal(t, []string{"aaaa"}, fitItems(items, 1))
+	assert.Equal(t, items, fitItems(items, 1000))
+}
+
+func TestNewNoteResponse(t *testing.T) {
+	note := &obsidian.Note{Path: "a.md", Content: strings.Repeat("line\n", 100)}
+
+	resp := newNoteResponse(note, 0, 200)
+	assert.True(t, resp.Truncated)
+	assert.Equal(t, 500, resp.TotalBytes)
+	assert.Equal(t, len(resp.Content), resp.NextOffset)
+	assert.True(t, strings.HasSuffix(resp.Content, "\n"))
+}
@@ -0,0 +1,143 @@
+package obsidianmcp
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	defaultCaptureHeading   = "Log"
+	defaultCaptureTimestamp = "15:04"
+)
+
+// CaptureConfig configures the obsidian_capture tool.
+type CaptureConfig struct {
+	// Heading that captures go under when the tool call does not name one.
+	Heading string
+	// Template is the vault path of the note used to create a missing daily note.
+	// If it is empty, Obsidian creates the note with its own daily note template.
+	Template string
+	// TimestampFormat is the Go time layout of the timestamp prefix.
+	TimestampFormat string
+}
+
+// CaptureTool returns the tool definition
+func CaptureTool() mcp.Tool {
+	return mcp.NewTool("obsidian_capture",
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithDescription("Capture a thought, task or log entry into a section of today's daily note. "+
+			"The entry is prefixed with the current time and added at the end of the section under the heading; "+
+			"the heading (and the daily note) are created if they do not exist."),
+		mcp.WithString("content", mcp.Required(), mcp.Description("Text to capture, e.g. \"Call Bob\" or \"- [ ] Call Bob\"")),
+		mcp.WithString("heading", mcp.Description("Heading of the section, e.g. \"Tasks\" or \"Ideas\" (use \"H1::H2\" for nested headings). Defaults to the configured capture heading.")),
+		mcp.WithBoolean("timestamp", mcp.Description("Prefix the entry with the current time"), mcp.DefaultBool(true)),
+	)
+}
+
+// CaptureHandler returns the tool handler
+func CaptureHandler(client *obsidian.Client, cfg CaptureConfig) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+		content := stringArg(args, "content")
+		if strings.TrimSpace(content) == "" {
+			return mcp.NewToolResultError("content is required"), nil
+		}
+		heading := stringArg(args, "heading")
+		if heading == "" {
+			heading = cfg.Heading
+		}
+		if heading == "" {
+			heading = defaultCaptureHeading
+		}
+
+		now := time.Now()
+		stamp := ""
+		if withTimestamp, ok := args["timestamp"].(bool); !ok || withTimestamp {
+			layout := cfg.TimestampFormat
+			if layout == "" {
+				layout = defaultCaptureTimestamp
+			}
+			stamp = now.Format(layout)
+		}
+
+		created, err := ensureDailyNote(ctx, client, cfg.Template, now)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create daily note: %v", err)), nil
+		}
+
+		entry := captureEntry(content, stamp)
+		err = client.Periodic.PatchCurrent(ctx, "daily", obsidian.PatchAppend, obsidian.TargetHeading, heading, entry,
+			obsidian.WithCreateTargetIfMissing())
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to capture to daily note: %v", err)), nil
+		}
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"heading":      heading,
+			"entry":        entry,
+			"created_note": created,
+		})
+	}
+}
+
+// ensureDailyNote creates today's daily note if it does not exist yet. With
+// a template, the note is created at the path of the daily note settings;
+// without one, Obsidian creates it with its own daily note template. It
+// reports whether the note was created.
+func ensureDailyNote(ctx context.Context, client *obsidian.Client, template string, now time.Time) (bool, error) {
+	_, err := client.Periodic.GetCurrent(ctx, "daily")
+	if err == nil {
+		return false, nil
+	}
+	if !obsidian.IsNotFound(err) {
+		return false, err
+	}
+
+	if template == "" {
+		// Appending to the current periodic note creates it.
+		if err := client.Periodic.AppendToCurrent(ctx, "daily", ""); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	tmpl, err := client.Vault.Get(ctx, template)
+	if err != nil {
+		return false, fmt.Errorf("failed to read template %s: %w", template, err)
+	}
+	notePath, err := client.Periodic.DailyNotePath(ctx, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to find the path of the daily note: %w", err)
+	}
+	title := strings.TrimSuffix(path.Base(notePath), ".md")
+	if err := client.Vault.Create(ctx, notePath, obsidian.RenderTemplate(tmpl, title, now)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// captureEntry formats content as an entry of the daily note. With a
+// timestamp the entry becomes a list item ("- 14:05 Call Bob"); existing
+// list and task markers are kept in front of the timestamp.
+func captureEntry(content, stamp string) string {
+	content = strings.TrimRight(content, "\r\n")
+	if stamp != "" {
+		marker := "- "
+		for _, m := range []string{"- [ ] ", "- [x] ", "- ", "* "} {
+			if strings.HasPrefix(content, m) {
+				marker = m
+				content = content[len(m):]
+				break
+			}
+		}
+		content = marker + stamp + " " + content
+	}
+	return content + "\n"
+}
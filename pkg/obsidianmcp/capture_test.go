@@ -0,0 +1,99 @@
+package obsidianmcp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapture_CreatesNoteFromTemplate(t *testing.T) {
+	var createdPath, created, patched string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/periodic/daily/":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errorCode": 40400, "message": "Not Found"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/vault/Templates/Daily.md":
+			fmt.Fprint(w, "# {{title}}\n\n## Log\n")
+		case r.Method == http.MethodGet && r.URL.Path == "/vault/.obsidian/plugins/periodic-notes/data.json":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errorCode": 40400, "message": "Not Found"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/vault/.obsidian/daily-notes.json":
+			fmt.Fprint(w, `{"folder": "Journal/", "format": "[Day] YYYY-MM-DD"}`)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/vault/Journal/Day "):
+			// The template is the only content: the note is not created
+			// through the periodic notes endpoint.
+			body, _ := io.ReadAll(r.Body)
+			createdPath, created = r.URL.Path, string(body)
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPatch && r.URL.Path == "/periodic/daily/":
+			assert.Equal(t, "append", r.Header.Get("Operation"))
+			assert.Equal(t, "heading", r.Header.Get("Target-Type"))
+			assert.Equal(t, "Tasks", r.Header.Get("Target"))
+			assert.Equal(t, "true", r.Header.Get("Create-Target-If-Missing"))
+			body, _ := io.ReadAll(r.Body)
+			patched = string(body)
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	createHandler := func(client *obsidian.Client) server.ToolHandlerFunc {
+		return CaptureHandler(client, CaptureConfig{Heading: "Log", Template: "Templates/Daily.md", TimestampFormat: "15:04"})
+	}
+	res := testTool(t, CaptureTool(), createHandler, "obsidian_capture", map[string]interface{}{
+		"content": "- [ ] Call Bob",
+		"heading": "Tasks",
+	}, handler)
+	logMsg(t, res)
+
+	assert.Regexp(t, `^/vault/Journal/Day \d{4}-\d\d-\d\d\.md$`, createdPath)
+	assert.Regexp(t, `^# Day \d{4}-\d\d-\d\d\n\n## Log\n$`, created)
+	assert.Regexp(t, `^- \[ \] \d\d:\d\d Call Bob\n$`, patched)
+
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, `"created_note":true`)
+}
+
+func TestCapture_DefaultHeading(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, "# Today\n")
+		case http.MethodPatch:
+			assert.Equal(t, "Inbox", r.Header.Get("Target"))
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, "Plain note\n", string(body))
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+
+	createHandler := func(client *obsidian.Client) server.ToolHandlerFunc {
+		return CaptureHandler(client, CaptureConfig{Heading: "Inbox"})
+	}
+	res := testTool(t, CaptureTool(), createHandler, "obsidian_capture", map[string]interface{}{
+		"content":   "Plain note",
+		"timestamp": false,
+	}, handler)
+	logMsg(t, res)
+}
+
+func TestCaptureEntry(t *testing.T) {
+	assert.Equal(t, "- 09:30 Idea\n", captureEntry("Idea\n", "09:30"))
+	assert.Equal(t, "* 09:30 Idea\n", captureEntry("* Idea", "09:30"))
+	assert.Equal(t, "- [x] 09:30 Done\n", captureEntry("- [x] Done", "09:30"))
+	assert.Equal(t, "Line one\nLine two\n", captureEntry("Line one\nLine two", ""))
+}
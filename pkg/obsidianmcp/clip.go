@@ -0,0 +1,132 @@
+package obsidianmcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bttk/bttk-mcp/internal/htmlmd"
+	"github.com/bttk/bttk-mcp/internal/toolargs"
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultClipFolder = "Clippings"
+	clippedFormat     = "2006-01-02T15:04:05"
+)
+
+// ClipConfig configures the obsidian_clip tool.
+type ClipConfig struct {
+	// Folder that clippings are saved to when the tool call does not name one.
+	Folder string
+}
+
+// clipFrontmatter is the frontmatter of a clipped note.
+type clipFrontmatter struct {
+	Title   string   `yaml:"title"`
+	Source  string   `yaml:"source"`
+	Clipped string   `yaml:"clipped"`
+	Tags    []string `yaml:"tags,omitempty"`
+}
+
+// ClipTool returns the tool definition
+func ClipTool() mcp.Tool {
+	return mcp.NewTool("obsidian_clip",
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithDescription("Save a web page to the vault as a clean Markdown note. "+
+			"Pass the HTML of the page (this tool does not fetch it) and its URL; the main content is extracted "+
+			"and converted to Markdown with links and remote images, and the note gets source, clipped and title properties. "+
+			"The note is saved in the clippings folder, named after the title; existing notes are never overwritten."),
+		mcp.WithString("html", mcp.Required(), mcp.Description("HTML of the page or of the part to clip")),
+		mcp.WithString("url", mcp.Required(), mcp.Description("URL of the page, used as the source and to resolve relative links")),
+		mcp.WithString("title", mcp.Description("Title of the note (defaults to the title of the page)")),
+		mcp.WithString("folder", mcp.Description("Folder to save the note in (defaults to the configured clippings folder)")),
+		mcp.WithArray("tags", mcp.Description("Tags to add to the note"), mcp.WithStringItems()),
+		mcp.WithBoolean("full_page", mcp.Description("Convert the whole page instead of extracting the main content"),
+			mcp.DefaultBool(false)),
+	)
+}
+
+// ClipHandler returns the tool handler
+func ClipHandler(client *obsidian.Client, cfg ClipConfig) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+		source := strings.TrimSpace(stringArg(args, "url"))
+		if stringArg(args, "html") == "" || source == "" {
+			return mcp.NewToolResultError("html and url are required"), nil
+		}
+		fullPage, _ := args["full_page"].(bool)
+
+		doc, err := htmlmd.Convert(stringArg(args, "html"), htmlmd.Options{BaseURL: source, MainContent: !fullPage})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to convert HTML: %v", err)), nil
+		}
+		if strings.TrimSpace(doc.Markdown) == "" {
+			return mcp.NewToolResultError("the HTML has no content to clip"), nil
+		}
+
+		now := time.Now()
+		title := clipTitle(stringArg(args, "title"), doc.Title, source, now)
+		content, err := clipNote(clipFrontmatter{
+			Title:   title,
+			Source:  source,
+			Clipped: now.Format(clippedFormat),
+			Tags:    toolargs.StringSlice(args["tags"]),
+		}, doc.Markdown)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create note: %v", err)), nil
+		}
+
+		folder := strings.Trim(stringArg(args, "folder"), "/")
+		if folder == "" {
+			folder = cfg.Folder
+		}
+		if folder == "" {
+			folder = defaultClipFolder
+		}
+		notePath, err := client.Vault.AvailablePath(ctx, path.Join(folder, obsidian.SanitizeFilename(title)+".md"))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to choose a file name: %v", err)), nil
+		}
+		if err := client.Vault.Create(ctx, notePath, content); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to save clipping: %v", err)), nil
+		}
+
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"path":  notePath,
+			"title": title,
+			"bytes": len(content),
+		})
+	}
+}
+
+// clipTitle picks the title of a clipping: the requested title, the title
+// of the page, or the host name and date, so that the file name is never empty.
+func clipTitle(requested, page, source string, now time.Time) string {
+	for _, title := range []string{requested, page} {
+		if obsidian.SanitizeFilename(title) != "" {
+			return strings.TrimSpace(title)
+		}
+	}
+	host := "Clipping"
+	if u, err := url.Parse(source); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	return host + " " + now.Format(time.DateOnly)
+}
+
+func clipNote(fm clipFrontmatter, markdown string) (string, error) {
+	data, err := yaml.Marshal(fm)
+	if err != nil {
+		return "", err
+	}
+	return "---\n" + string(data) + "---\n" + markdown, nil
+}
@@ -0,0 +1,62 @@
+package obsidianmcp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClip(t *testing.T) {
+	var saved string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/vault/Web/":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"files": ["Go: Tips & Tricks.md", "Go Tips & Tricks.md"]}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/vault/Web/Go Tips & Tricks 1.md":
+			body, _ := io.ReadAll(r.Body)
+			saved = string(body)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	createHandler := func(client *obsidian.Client) server.ToolHandlerFunc {
+		return ClipHandler(client, ClipConfig{Folder: "Web"})
+	}
+	res := testTool(t, ClipTool(), createHandler, "obsidian_clip", map[string]interface{}{
+		"url": "https://example.com/blog/go-tips",
+		"html": `<html><head><title>Go: Tips & Tricks</title></head><body>
+			<nav><a href="/">Home</a></nav>
+			<article><p>Use <code>go vet</code> often. See <a href="/blog/vet">the vet post</a>.</p>
+			<img src="/img/gopher.png" alt="Gopher"></article></body></html>`,
+		"tags": []interface{}{"clippings", "go"},
+	}, handler)
+	logMsg(t, res)
+	require.False(t, res.IsError)
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, `"path":"Web/Go Tips \u0026 Tricks 1.md"`)
+
+	assert.Contains(t, saved, "---\ntitle: 'Go: Tips & Tricks'\nsource: https://example.com/blog/go-tips\nclipped: "+time.Now().Format("2006-01-02"))
+	assert.Contains(t, saved, "tags:\n    - clippings\n    - go\n---\n")
+	assert.Contains(t, saved, "Use `go vet` often. See [the vet post](https://example.com/blog/vet).\n\n![Gopher](https://example.com/img/gopher.png)\n")
+	assert.NotContains(t, saved, "Home")
+}
+
+func TestClipTitle(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "Mine", clipTitle("Mine", "Page", "https://example.com", now))
+	assert.Equal(t, "Page", clipTitle("", "Page", "https://example.com", now))
+	assert.Equal(t, "example.com 2024-05-01", clipTitle("", "???", "https://example.com/x", now))
+}
@@ -0,0 +1,67 @@
+package obsidianmcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const defaultDuplicateClusterLimit = 20
+
+// FindDuplicatesTool returns the tool definition
+func FindDuplicatesTool() mcp.Tool {
+	return mcp.NewTool("obsidian_find_duplicates",
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("Find notes that are exact or near duplicates of each other, e.g. to merge or clean them up. "+
+			"Reads every note in the folder, so scope large vaults with folder. "+
+			"Clusters are sorted by size; use next_offset to get the next page."),
+		mcp.WithString("folder", mcp.Description("Only compare notes in this folder, including subfolders")),
+		mcp.WithNumber("threshold", mcp.Description("Minimum similarity (0-1) of near-duplicates. "+
+			"Below 0.5, every pair of notes is compared, which is slow in large folders"),
+			mcp.DefaultNumber(obsidian.DefaultDuplicateThreshold)),
+		mcp.WithNumber("min_words", mcp.Description("Skip notes with fewer words"),
+			mcp.DefaultNumber(obsidian.DefaultMinWords)),
+		mcp.WithNumber("shingle_size", mcp.Description("Number of consecutive words compared as a unit; smaller values find looser matches"),
+			mcp.DefaultNumber(obsidian.DefaultShingleSize)),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of clusters to return (0 for all)"),
+			mcp.DefaultNumber(defaultDuplicateClusterLimit)),
+		withOffsetArg("Number of clusters to skip (use next_offset of a truncated response)"),
+		withBudgetArgs(),
+	)
+}
+
+// FindDuplicatesHandler returns the tool handler
+func FindDuplicatesHandler(client *obsidian.Client) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+		opts := obsidian.DuplicateOptions{
+			Folder:      stringArg(args, "folder"),
+			MinWords:    intArg(args, "min_words", 0),
+			ShingleSize: intArg(args, "shingle_size", 0),
+		}
+		if threshold, ok := args["threshold"].(float64); ok {
+			opts.Threshold = threshold
+		}
+		limit := intArg(args, "limit", defaultDuplicateClusterLimit)
+		offset := intArg(args, "offset", 0)
+
+		report, err := client.Analysis.FindDuplicates(ctx, opts)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to find duplicates: %v", err)), nil
+		}
+
+		page := paginate(report.Clusters, offset, limit)
+		fit := fitItems(page, budgetFromArgs(args))
+		resp := pageResponse("clusters", fit, offset, len(report.Clusters), len(fit) < len(page))
+		resp["scanned"] = report.Scanned
+		if len(report.Failed) > 0 {
+			resp["failed"] = report.Failed
+		}
+		return mcp.NewToolResultJSON(resp)
+	}
+}
@@ -0,0 +1,42 @@
+package obsidianmcp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	note := strings.Repeat("weekly review of open projects and next actions ", 3)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/Inbox/":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"files": ["Review.md", "Review 1.md", "Other.md"]}`)
+		case "/vault/Inbox/Review.md", "/vault/Inbox/Review 1.md":
+			fmt.Fprint(w, note)
+		case "/vault/Inbox/Other.md":
+			fmt.Fprint(w, "Packing list for the trip: passport, charger, walking shoes, rain jacket and a good book.")
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}
+
+	res := testTool(t, FindDuplicatesTool(), FindDuplicatesHandler, "obsidian_find_duplicates", map[string]interface{}{
+		"folder":    "Inbox",
+		"threshold": 0.9,
+	}, handler)
+	logMsg(t, res)
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, `"scanned":3`)
+	assert.Contains(t, text.Text, `"total":1`)
+	assert.Contains(t, text.Text, `"exact":true`)
+	assert.Contains(t, text.Text, `"path":"Inbox/Review 1.md"`)
+	assert.NotContains(t, text.Text, "Other.md")
+}
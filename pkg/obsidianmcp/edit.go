@@ -0,0 +1,103 @@
+package obsidianmcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const diffContextLines = 3
+
+// ErrInvalidEdits is returned when the edits argument is malformed.
+var ErrInvalidEdits = errors.New("invalid edits")
+
+// EditFileTool returns the tool definition
+func EditFileTool() mcp.Tool {
+	return mcp.NewTool("obsidian_edit_file",
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithDescription("Edit a file in the vault without sending its whole content. "+
+			"Pass either a list of search/replace edits (each search text must occur exactly once) or a unified diff. "+
+			"If any edit or hunk does not match, the file is left unchanged. Returns the unified diff of the change."),
+		mcp.WithString("path", mcp.Required(), mcp.Description("Path to the file")),
+		mcp.WithArray("edits", mcp.Description("Edits applied in order"),
+			mcp.Items(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"search":  map[string]interface{}{"type": "string", "description": "Exact text to find; include enough context to make it unique"},
+					"replace": map[string]interface{}{"type": "string", "description": "Text to put in its place"},
+				},
+				"required": []string{"search", "replace"},
+			})),
+		mcp.WithString("diff", mcp.Description("Unified diff to apply (alternative to edits)")),
+		mcp.WithBoolean("dry_run", mcp.Description("Return the diff without saving the file")),
+	)
+}
+
+// EditFileHandler returns the tool handler
+func EditFileHandler(client *obsidian.Client) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+		path := stringArg(args, "path")
+		diff := stringArg(args, "diff")
+		dryRun, _ := args["dry_run"].(bool)
+
+		edits, err := editsArg(args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if (len(edits) == 0) == (diff == "") {
+			return mcp.NewToolResultError("pass either edits or diff"), nil
+		}
+
+		content, err := client.Vault.Get(ctx, path)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read file: %v", err)), nil
+		}
+
+		var updated string
+		if diff != "" {
+			updated, err = obsidian.ApplyUnifiedDiff(content, diff)
+		} else {
+			updated, err = obsidian.ApplyEdits(content, edits)
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("file not changed: %v", err)), nil
+		}
+
+		changed := updated != content
+		if changed && !dryRun {
+			if err := client.Vault.Create(ctx, path, updated); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to save file: %v", err)), nil
+			}
+		}
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"path":    path,
+			"changed": changed,
+			"saved":   changed && !dryRun,
+			"diff":    obsidian.UnifiedDiff("a/"+path, "b/"+path, content, updated, diffContextLines),
+		})
+	}
+}
+
+func editsArg(args map[string]interface{}) ([]obsidian.Edit, error) {
+	raw, ok := args["edits"].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+	edits := make([]obsidian.Edit, 0, len(raw))
+	for i, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: edit %d must be an object with search and replace", ErrInvalidEdits, i+1)
+		}
+		search, _ := m["search"].(string)
+		replace, _ := m["replace"].(string)
+		edits = append(edits, obsidian.Edit{Search: search, Replace: replace})
+	}
+	return edits, nil
+}
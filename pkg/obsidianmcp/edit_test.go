@@ -0,0 +1,68 @@
+package obsidianmcp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func editFileServer(t *testing.T, content string, saved *string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/vault/note.md", r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, content)
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			*saved = string(body)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+}
+
+func TestEditFile_Edits(t *testing.T) {
+	var saved string
+	res := testTool(t, EditFileTool(), EditFileHandler, "obsidian_edit_file", map[string]interface{}{
+		"path": "note.md",
+		"edits": []interface{}{
+			map[string]interface{}{"search": "- [ ] one", "replace": "- [x] one"},
+		},
+	}, editFileServer(t, "# Plan\n- [ ] one\n- [ ] two\n", &saved))
+	logMsg(t, res)
+
+	assert.Equal(t, "# Plan\n- [x] one\n- [ ] two\n", saved)
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, `-- [ ] one\n+- [x] one`)
+}
+
+func TestEditFile_Diff(t *testing.T) {
+	var saved string
+	res := testTool(t, EditFileTool(), EditFileHandler, "obsidian_edit_file", map[string]interface{}{
+		"path": "note.md",
+		"diff": "--- a/note.md\n+++ b/note.md\n@@ -2 +2 @@\n-- [ ] two\n+- [x] two\n",
+	}, editFileServer(t, "# Plan\n- [ ] two\n", &saved))
+	logMsg(t, res)
+	assert.Equal(t, "# Plan\n- [x] two\n", saved)
+}
+
+func TestEditFile_Conflict(t *testing.T) {
+	saved := "unchanged"
+	res := testTool(t, EditFileTool(), EditFileHandler, "obsidian_edit_file", map[string]interface{}{
+		"path": "note.md",
+		"edits": []interface{}{
+			map[string]interface{}{"search": "# Plan", "replace": "# Weekly plan"},
+			map[string]interface{}{"search": "- [ ]", "replace": "- [x]"},
+		},
+	}, editFileServer(t, "# Plan\n- [ ] one\n- [ ] two\n", &saved))
+
+	assert.True(t, res.IsError)
+	assert.Equal(t, "unchanged", saved)
+}
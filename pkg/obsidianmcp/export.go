@@ -0,0 +1,71 @@
+package obsidianmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ExportNoteTool returns the tool definition
+func ExportNoteTool() mcp.Tool {
+	return mcp.NewTool("obsidian_export_note",
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("Export a note, or all notes of a folder, for people who do not use Obsidian: "+
+			"a standalone HTML page (wikilinks between exported notes become links, embedded notes and images are included; other attachments are not), "+
+			"plain text without Markdown syntax, or a JSON bundle with the frontmatter, tags, dates, links and content of each note. "+
+			"The rendered document is returned as an embedded resource, with its metadata as JSON text."),
+		mcp.WithString("path", mcp.Description("Path of the note to export")),
+		mcp.WithString("folder", mcp.Description("Export all notes in this folder and its subfolders instead (\"/\" for the whole vault)")),
+		mcp.WithString("format", mcp.Description("Format of the export"),
+			mcp.Enum(obsidian.ExportFormats()...), mcp.DefaultString(obsidian.ExportHTML)),
+	)
+}
+
+// ExportNoteHandler returns the tool handler
+func ExportNoteHandler(client *obsidian.Client) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+		notePath, folder := stringArg(args, "path"), stringArg(args, "folder")
+		if (notePath == "") == (folder == "") {
+			return mcp.NewToolResultError("exactly one of path and folder is required"), nil
+		}
+		format := stringArg(args, "format")
+		if format == "" {
+			format = obsidian.ExportHTML
+		}
+
+		var export *obsidian.Export
+		var err error
+		if notePath != "" {
+			export, err = client.Export.Note(ctx, notePath, format)
+		} else {
+			export, err = client.Export.Folder(ctx, folder, format)
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to export: %v", err)), nil
+		}
+
+		meta, err := json.Marshal(map[string]interface{}{
+			"format":    export.Format,
+			"filename":  export.Filename,
+			"mime_type": export.MIMEType,
+			"notes":     export.Notes,
+			"bytes":     len(export.Content),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultResource(string(meta), mcp.TextResourceContents{
+			URI:      "obsidian-export:///" + url.PathEscape(export.Filename),
+			MIMEType: export.MIMEType,
+			Text:     export.Content,
+		}), nil
+	}
+}
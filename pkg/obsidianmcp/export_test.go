@@ -0,0 +1,51 @@
+package obsidianmcp
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportNote(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vault/":
+			fmt.Fprint(w, `{"files": ["Plan.md", "Goals.md"]}`)
+		case "/vault/Plan.md":
+			fmt.Fprint(w, `{"path": "Plan.md", "content": "# Plan\n\nShip **v2**, see [[Goals]].\n"}`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	res := testTool(t, ExportNoteTool(), ExportNoteHandler, "obsidian_export_note", map[string]interface{}{
+		"path":   "Plan.md",
+		"format": "text",
+	}, handler)
+	logMsg(t, res)
+	require.False(t, res.IsError)
+	require.Len(t, res.Content, 2)
+	meta, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.JSONEq(t, `{"format": "text", "filename": "Plan.txt", "mime_type": "text/plain", "notes": ["Plan.md"], "bytes": 26}`, meta.Text)
+	embedded, ok := res.Content[1].(mcp.EmbeddedResource)
+	require.True(t, ok)
+	resource, ok := embedded.Resource.(mcp.TextResourceContents)
+	require.True(t, ok)
+	assert.Equal(t, "obsidian-export:///Plan.txt", resource.URI)
+	assert.Equal(t, "Plan\n\nShip v2, see Goals.\n", resource.Text)
+}
+
+func TestExportNote_Arguments(t *testing.T) {
+	res := testTool(t, ExportNoteTool(), ExportNoteHandler, "obsidian_export_note", map[string]interface{}{
+		"path":   "Plan.md",
+		"folder": "Projects",
+	}, nil)
+	assert.True(t, res.IsError)
+}
@@ -0,0 +1,75 @@
+package obsidianmcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bttk/bttk-mcp/internal/toolargs"
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const defaultHealthIssueLimit = 50
+
+// VaultHealthConfig configures the obsidian_vault_health tool.
+type VaultHealthConfig struct {
+	// StubWords is the word count below which a note is a stub.
+	StubWords int
+	// RequiredProperties maps folders to the frontmatter properties their
+	// notes must have; "" applies to all notes.
+	RequiredProperties map[string][]string
+}
+
+// VaultHealthTool returns the tool definition
+func VaultHealthTool() mcp.Tool {
+	return mcp.NewTool("obsidian_vault_health",
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("Check the vault for problems: broken links (including links to missing headings and blocks), "+
+			"empty and stub notes, frontmatter that is not valid YAML, notes missing required properties, "+
+			"notes with the same name (ambiguous links) and attachments that no note links to. "+
+			"Each issue has the check, the path and line to fix, and the link target or property involved. "+
+			"Orphaned attachments are only checked when no folder is given."),
+		mcp.WithString("folder", mcp.Description("Only check notes in this folder, including subfolders")),
+		mcp.WithArray("checks", mcp.Description("Checks to run (default all)"),
+			mcp.Items(map[string]interface{}{"type": "string", "enum": obsidian.LintChecks()})),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of issues to return (0 for all)"),
+			mcp.DefaultNumber(defaultHealthIssueLimit)),
+		withOffsetArg("Number of issues to skip (use next_offset of a truncated response)"),
+		withBudgetArgs(),
+	)
+}
+
+// VaultHealthHandler returns the tool handler
+func VaultHealthHandler(client *obsidian.Client, cfg VaultHealthConfig) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+		limit := intArg(args, "limit", defaultHealthIssueLimit)
+		offset := intArg(args, "offset", 0)
+
+		report, err := client.Analysis.Lint(ctx, obsidian.LintOptions{
+			Folder:             stringArg(args, "folder"),
+			Checks:             toolargs.StringSlice(args["checks"]),
+			StubWords:          cfg.StubWords,
+			RequiredProperties: cfg.RequiredProperties,
+		})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to check vault: %v", err)), nil
+		}
+
+		page := paginate(report.Issues, offset, limit)
+		fit := fitItems(page, budgetFromArgs(args))
+		resp := pageResponse("issues", fit, offset, len(report.Issues), len(fit) < len(page))
+		resp["scanned"] = report.Scanned
+		resp["counts"] = report.Counts
+		if len(report.Failed) > 0 {
+			resp["failed"] = report.Failed
+		}
+		if len(report.Skipped) > 0 {
+			resp["skipped"] = report.Skipped
+		}
+		return mcp.NewToolResultJSON(resp)
+	}
+}
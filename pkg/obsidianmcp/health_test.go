@@ -0,0 +1,45 @@
+package obsidianmcp
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultHealth(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vault/":
+			fmt.Fprint(w, `{"files": ["Projects/"]}`)
+		case "/vault/Projects/":
+			fmt.Fprint(w, `{"files": ["Alpha.md", "logo.png"]}`)
+		case "/vault/Projects/Alpha.md":
+			fmt.Fprint(w, `{"path": "Projects/Alpha.md", "frontmatter": {"status": ""},
+				"content": "---\nstatus:\n---\nAlpha is the first project. See [[Beta]] for the follow-up work."}`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}
+
+	createHandler := func(client *obsidian.Client) server.ToolHandlerFunc {
+		return VaultHealthHandler(client, VaultHealthConfig{RequiredProperties: map[string][]string{"Projects": {"status"}}})
+	}
+	res := testTool(t, VaultHealthTool(), createHandler, "obsidian_vault_health", map[string]interface{}{
+		"checks": []interface{}{"broken_link", "missing_property", "orphaned_attachment"},
+	}, handler)
+	logMsg(t, res)
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, `"scanned":1`)
+	assert.Contains(t, text.Text, `"total":3`)
+	assert.Contains(t, text.Text, `"counts":{"broken_link":1,"missing_property":1,"orphaned_attachment":1}`)
+	assert.Contains(t, text.Text, `"target":"Beta"`)
+	assert.Contains(t, text.Text, `"path":"Projects/logo.png"`)
+}
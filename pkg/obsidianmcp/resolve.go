@@ -0,0 +1,94 @@
+package obsidianmcp
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ResolveNoteTool returns the tool definition
+func ResolveNoteTool() mcp.Tool {
+	return mcp.NewTool("obsidian_resolve_note",
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("Find the path of a note from its name or title, e.g. \"Q3 planning\". "+
+			"Matches file names, frontmatter aliases and first-level headings, tolerating partial names and typos. "+
+			"Returns the best matches with a score (1 for an exact match); include a folder (\"Projects/Plan\") to match paths. "+
+			"Reads every note, so prefer a known path when there is one."),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name, alias or title of the note")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of matches to return"),
+			mcp.DefaultNumber(obsidian.DefaultResolveLimit)),
+	)
+}
+
+// ResolveNoteHandler returns the tool handler
+func ResolveNoteHandler(client *obsidian.Client) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+		name := strings.TrimSpace(stringArg(args, "name"))
+		if name == "" {
+			return mcp.NewToolResultError("name is required"), nil
+		}
+
+		res, err := client.Analysis.ResolveNote(ctx, name, intArg(args, "limit", obsidian.DefaultResolveLimit))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve note: %v", err)), nil
+		}
+		if res.Matches == nil {
+			res.Matches = []obsidian.NoteMatch{}
+		}
+		return mcp.NewToolResultJSON(res)
+	}
+}
+
+// resolveMissingNote finds the note that a path that does not exist refers
+// to: a note in the same folder whose name, alias or title is the name of
+// the path, ignoring case and punctuation. Only the notes in the folder of
+// the path are read; the notes of its subfolders are candidates by their
+// file names. It returns nil and the candidates when no note matches
+// exactly, or several do, and when the path is not a note.
+func resolveMissingNote(ctx context.Context, client *obsidian.Client, notePath string) (*obsidian.NoteMatch, []obsidian.NoteMatch, error) {
+	if ext := path.Ext(notePath); ext != "" && ext != ".md" {
+		return nil, nil, nil
+	}
+	folder := path.Dir(notePath)
+	if folder == "." {
+		folder = ""
+	}
+	name := strings.TrimSuffix(path.Base(notePath), ".md")
+	res, err := client.Analysis.ResolveNoteIn(ctx, folder, name, obsidian.DefaultResolveLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	var match *obsidian.NoteMatch
+	for i, m := range res.Matches {
+		if m.Score < 1 || path.Dir(m.Path) != path.Dir(notePath) {
+			continue
+		}
+		if match != nil {
+			return nil, res.Matches, nil
+		}
+		match = &res.Matches[i]
+	}
+	return match, res.Matches, nil
+}
+
+// notFoundMessage describes a path that does not exist, with the notes it
+// might refer to.
+func notFoundMessage(err error, candidates []obsidian.NoteMatch) string {
+	msg := fmt.Sprintf("failed to get file: %v", err)
+	if len(candidates) == 0 {
+		return msg
+	}
+	paths := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		paths = append(paths, c.Path)
+	}
+	return msg + "; did you mean: " + strings.Join(paths, ", ")
+}
@@ -0,0 +1,121 @@
+package obsidianmcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resolveTestHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/vault/":
+			fmt.Fprint(w, `{"files": ["Work/", "Home/"]}`)
+		case "/vault/Work/":
+			fmt.Fprint(w, `{"files": ["2024-Q3 Planning.md", "Sync.md"]}`)
+		case "/vault/Home/":
+			fmt.Fprint(w, `{"files": ["Sync.md"]}`)
+		case "/vault/Work/2024-Q3 Planning.md":
+			_ = json.NewEncoder(w).Encode(obsidian.Note{
+				Path:        "Work/2024-Q3 Planning.md",
+				Content:     "---\naliases: [Q3 planning]\n---\n# Third quarter\n",
+				Frontmatter: map[string]interface{}{"aliases": []interface{}{"Q3 planning"}},
+			})
+		case "/vault/Work/Sync.md", "/vault/Home/Sync.md":
+			_ = json.NewEncoder(w).Encode(obsidian.Note{Path: r.URL.Path[len("/vault/"):], Content: "Sync notes.\n"})
+		case "/vault/Work/Q3 planning.md", "/vault/Work/Q3 planing.md", "/vault/Q3 planning.md", "/vault/Sync.md", "/vault/Nothing like it.md":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errorCode": 40400, "message": "Not Found"}`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestResolveNote(t *testing.T) {
+	res := testTool(t, ResolveNoteTool(), ResolveNoteHandler, "obsidian_resolve_note", map[string]interface{}{
+		"name":  "q3 planning",
+		"limit": 1,
+	}, resolveTestHandler(t))
+	logMsg(t, res)
+	require.False(t, res.IsError)
+
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.JSONEq(t, `{"query": "q3 planning", "scanned": 3, "matches": [
+		{"path": "Work/2024-Q3 Planning.md", "matched": "Q3 planning", "field": "alias", "score": 1}
+	]}`, text.Text)
+}
+
+func TestGetFile_ResolvesMissingPath(t *testing.T) {
+	res := testTool(t, GetFileTool(), GetFileHandler, "obsidian_get_file", map[string]interface{}{
+		"path": "Work/Q3 planning.md",
+	}, resolveTestHandler(t))
+	logMsg(t, res)
+	require.False(t, res.IsError)
+
+	var resp struct {
+		Path     string              `json:"path"`
+		Content  string              `json:"content"`
+		Resolved *obsidian.NoteMatch `json:"resolved"`
+	}
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &resp))
+	assert.Equal(t, "Work/2024-Q3 Planning.md", resp.Path)
+	assert.Equal(t, &obsidian.NoteMatch{Path: "Work/2024-Q3 Planning.md", Matched: "Q3 planning", Field: obsidian.MatchAlias, Score: 1}, resp.Resolved)
+}
+
+func TestGetFile_AmbiguousMissingPath(t *testing.T) {
+	// The notes of subfolders are suggested by their file names, without
+	// being read.
+	var mu sync.Mutex
+	var read []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".md") {
+			mu.Lock()
+			read = append(read, r.URL.Path)
+			mu.Unlock()
+		}
+		resolveTestHandler(t)(w, r)
+	}
+	res := testTool(t, GetFileTool(), GetFileHandler, "obsidian_get_file", map[string]interface{}{
+		"path": "Sync.md",
+	}, handler)
+	require.True(t, res.IsError)
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, "did you mean: Home/Sync.md, Work/Sync.md")
+	assert.Equal(t, []string{"/vault/Sync.md"}, read)
+
+	res = testTool(t, GetFileTool(), GetFileHandler, "obsidian_get_file", map[string]interface{}{
+		"path": "Nothing like it.md",
+	}, resolveTestHandler(t))
+	require.True(t, res.IsError)
+	text, ok = res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.NotContains(t, text.Text, "did you mean")
+}
+
+func TestGetFile_InexactMissingPath(t *testing.T) {
+	// Only exact names in the same folder are read in place of the path.
+	for _, p := range []string{"Q3 planning.md", "Work/Q3 planing.md"} {
+		res := testTool(t, GetFileTool(), GetFileHandler, "obsidian_get_file", map[string]interface{}{
+			"path": p,
+		}, resolveTestHandler(t))
+		require.True(t, res.IsError, p)
+		text, ok := res.Content[0].(mcp.TextContent)
+		require.True(t, ok)
+		assert.Contains(t, text.Text, "did you mean: Work/2024-Q3 Planning.md", p)
+	}
+}
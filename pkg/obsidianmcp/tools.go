@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
+	"strings"
+	"time"
 
+	"github.com/bttk/bttk-mcp/internal/toolargs"
 	"github.com/bttk/bttk-mcp/pkg/obsidian"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -19,6 +23,33 @@ func getArgs(req mcp.CallToolRequest) map[string]interface{} {
 	return args
 }
 
+// stringArg returns a string argument, or "" if it is missing or not a string.
+func stringArg(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+// intArg returns a non-negative numeric argument as an int, or def if it is missing.
+func intArg(args map[string]interface{}, name string, def int) int {
+	v, ok := args[name].(float64)
+	if !ok || v < 0 {
+		return def
+	}
+	return int(v)
+}
+
+// parseTimeArg parses a date (YYYY-MM-DD, local time) or an RFC3339 timestamp.
+// An empty string yields the zero time.
+func parseTimeArg(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(time.DateOnly, s, time.Local)
+}
+
 // GetActiveFileTool returns the tool definition
 func GetActiveFileTool() mcp.Tool {
 	return mcp.NewTool("obsidian_get_active_file",
@@ -26,17 +57,20 @@ func GetActiveFileTool() mcp.Tool {
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithIdempotentHintAnnotation(false),
 		mcp.WithDescription("Get the content of the currently active file in Obsidian"),
+		withOffsetArg(contentOffsetDescription),
+		withBudgetArgs(),
 	)
 }
 
 // GetActiveFileHandler returns the tool handler
 func GetActiveFileHandler(client *obsidian.Client) server.ToolHandlerFunc {
-	return func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
 		content, err := client.ActiveFile.GetNote(ctx)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get active file: %v", err)), nil
 		}
-		return mcp.NewToolResultJSON(content)
+		return mcp.NewToolResultJSON(newNoteResponse(content, intArg(args, "offset", 0), budgetFromArgs(args)))
 	}
 }
 
@@ -99,7 +133,18 @@ func PatchActiveFileHandler(client *obsidian.Client) server.ToolHandlerFunc {
 
 const (
 	defaultSearchContextLength = 100
-	maxMatchesPerFile          = 3
+	defaultMaxMatchesPerFile   = 3
+	defaultSearchLimit         = 20
+)
+
+const (
+	formatJSON     = "json"
+	formatMarkdown = "markdown"
+)
+
+const (
+	contentOffsetDescription = "Byte offset in the content to start from (use next_offset of a truncated response)"
+	resultOffsetDescription  = "Number of results to skip (use next_offset of a truncated response)"
 )
 
 // SearchSimpleTool returns the tool definition
@@ -108,9 +153,17 @@ func SearchSimpleTool() mcp.Tool {
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithIdempotentHintAnnotation(true),
-		mcp.WithDescription("Search the vault for files matching a query"),
+		mcp.WithDescription("Search the vault for files matching a query. "+
+			"Results are ranked by score and paged; use next_offset to get the next page."),
 		mcp.WithString("query", mcp.Required(), mcp.Description("Search query")),
 		mcp.WithNumber("context_length", mcp.Description("Length of context to return"), mcp.DefaultNumber(defaultSearchContextLength)),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of files to return (0 for all)"), mcp.DefaultNumber(defaultSearchLimit)),
+		mcp.WithNumber("offset", mcp.Description("Number of files to skip"), mcp.DefaultNumber(0)),
+		mcp.WithNumber("max_matches_per_file", mcp.Description("Maximum number of matches to return per file (0 for all)"),
+			mcp.DefaultNumber(defaultMaxMatchesPerFile)),
+		mcp.WithString("folder", mcp.Description("Only return files in this folder, including subfolders")),
+		mcp.WithArray("extensions", mcp.Description("Only return files with these extensions, e.g. [\"md\"]"), mcp.WithStringItems()),
+		withBudgetArgs(),
 	)
 }
 
@@ -119,25 +172,72 @@ func SearchSimpleHandler(client *obsidian.Client) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args := getArgs(request)
 		query, _ := args["query"].(string)
-		contextLen, _ := args["context_length"].(float64)
+		contextLen := intArg(args, "context_length", defaultSearchContextLength)
+		limit := intArg(args, "limit", defaultSearchLimit)
+		offset := intArg(args, "offset", 0)
+		maxMatches := intArg(args, "max_matches_per_file", defaultMaxMatchesPerFile)
 
-		results, err := client.Search.Simple(ctx, query, int(contextLen))
+		results, err := client.Search.Simple(ctx, query, contextLen)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to search: %v", err)), nil
 		}
 
-		for i := range results {
-			if len(results[i].Matches) > maxMatchesPerFile {
-				results[i].Matches = results[i].Matches[:maxMatchesPerFile]
+		results = filterSearchResults(results, stringArg(args, "folder"), toolargs.StringSlice(args["extensions"]))
+		obsidian.SortSearchResults(results)
+
+		total := len(results)
+		page := paginate(results, offset, limit)
+		for i := range page {
+			page[i].DedupeMatches(contextLen)
+			if maxMatches > 0 && len(page[i].Matches) > maxMatches {
+				page[i].Matches = page[i].Matches[:maxMatches]
 			}
 		}
 
-		return mcp.NewToolResultJSON(map[string]interface{}{
-			"results": results,
-		})
+		fit := fitItems(page, budgetFromArgs(args))
+		return mcp.NewToolResultJSON(pageResponse("results", fit, offset, total, len(fit) < len(page)))
 	}
 }
 
+// filterSearchResults keeps the results in folder (if not empty) whose
+// extension is one of extensions (if not empty).
+func filterSearchResults(results []obsidian.SearchResult, folder string, extensions []string) []obsidian.SearchResult {
+	folder = strings.Trim(folder, "/")
+	filtered := results[:0]
+	for _, r := range results {
+		if folder != "" && !strings.HasPrefix(r.Filename, folder+"/") {
+			continue
+		}
+		if len(extensions) > 0 && !hasExtension(r.Filename, extensions) {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	return filtered
+}
+
+func hasExtension(filename string, extensions []string) bool {
+	ext := strings.TrimPrefix(path.Ext(filename), ".")
+	for _, e := range extensions {
+		if strings.EqualFold(ext, strings.TrimPrefix(e, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// paginate returns at most limit items starting at offset.
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
 // SearchJSONLogicTool returns the tool definition
 func SearchJSONLogicTool() mcp.Tool {
 	return mcp.NewTool("obsidian_search_json_logic",
@@ -165,6 +265,8 @@ func SearchJSONLogicTool() mcp.Tool {
     }
   ]
 }`)),
+		withOffsetArg(resultOffsetDescription),
+		withBudgetArgs(),
 	)
 }
 
@@ -178,15 +280,78 @@ func SearchJSONLogicHandler(client *obsidian.Client) server.ToolHandlerFunc {
 		if err := json.Unmarshal([]byte(queryStr), &query); err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("invalid JSON logic query: %v", err)), nil
 		}
+		if err := obsidian.ValidateJSONLogic(query); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 
 		results, err := client.Search.JSONLogic(ctx, query)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to search: %v", err)), nil
 		}
 
-		return mcp.NewToolResultJSON(map[string]interface{}{
-			"results": results,
-		})
+		offset := intArg(args, "offset", 0)
+		page := paginate(results, offset, 0)
+		fit := fitItems(page, budgetFromArgs(args))
+		return mcp.NewToolResultJSON(pageResponse("results", fit, offset, len(results), len(fit) < len(page)))
+	}
+}
+
+// FindNotesTool returns the tool definition
+func FindNotesTool() mcp.Tool {
+	return mcp.NewTool("obsidian_find_notes",
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("Find notes matching simple filters. All given filters must match."),
+		mcp.WithArray("tags", mcp.Description("Tags the note must have, e.g. [\"project\", \"work/meeting\"]"), mcp.WithStringItems()),
+		mcp.WithString("folder", mcp.Description("Folder the note must be in, including subfolders")),
+		mcp.WithObject("frontmatter", mcp.Description("Frontmatter properties that must equal the given values, e.g. {\"status\": \"done\"}")),
+		mcp.WithString("modified_after", mcp.Description("Only notes modified at or after this time (YYYY-MM-DD or RFC3339)")),
+		mcp.WithString("modified_before", mcp.Description("Only notes modified before this time (YYYY-MM-DD or RFC3339)")),
+		withOffsetArg(resultOffsetDescription),
+		withBudgetArgs(),
+	)
+}
+
+// FindNotesHandler returns the tool handler
+func FindNotesHandler(client *obsidian.Client) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+
+		filter := obsidian.NoteFilter{
+			Tags:   toolargs.StringSlice(args["tags"]),
+			Folder: stringArg(args, "folder"),
+		}
+		if fm, ok := args["frontmatter"].(map[string]interface{}); ok {
+			filter.Frontmatter = fm
+		}
+
+		var err error
+		if filter.ModifiedAfter, err = parseTimeArg(stringArg(args, "modified_after")); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid modified_after: %v", err)), nil
+		}
+		if filter.ModifiedBefore, err = parseTimeArg(stringArg(args, "modified_before")); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid modified_before: %v", err)), nil
+		}
+
+		query := filter.JSONLogic()
+		results, err := client.Search.JSONLogic(ctx, query)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to search: %v", err)), nil
+		}
+
+		files := make([]string, 0, len(results))
+		for _, r := range results {
+			files = append(files, r.Filename)
+		}
+
+		offset := intArg(args, "offset", 0)
+		page := paginate(files, offset, 0)
+		fit := fitItems(page, budgetFromArgs(args))
+		resp := pageResponse("files", fit, offset, len(files), len(fit) < len(page))
+		resp["count"] = len(files)
+		resp["query"] = query
+		return mcp.NewToolResultJSON(resp)
 	}
 }
 
@@ -198,6 +363,10 @@ func SearchDQLTool() mcp.Tool {
 		mcp.WithIdempotentHintAnnotation(true),
 		mcp.WithDescription("Search the vault using Dataview Query Language (DQL)"),
 		mcp.WithString("query", mcp.Required(), mcp.Description("DQL query (e.g., 'TABLE file.mtime FROM \"folder\"')")),
+		mcp.WithString("format", mcp.Description("Output format: json (columns and rows) or markdown (a Markdown table)"),
+			mcp.Enum(formatJSON, formatMarkdown), mcp.DefaultString(formatJSON)),
+		withOffsetArg(resultOffsetDescription),
+		withBudgetArgs(),
 	)
 }
 
@@ -206,15 +375,36 @@ func SearchDQLHandler(client *obsidian.Client) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args := getArgs(request)
 		query, _ := args["query"].(string)
+		format, _ := args["format"].(string)
 
-		results, err := client.Search.Dataview(ctx, query)
+		table, err := client.Search.DataviewTable(ctx, query)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to search: %v", err)), nil
 		}
 
-		return mcp.NewToolResultJSON(map[string]interface{}{
-			"results": results,
-		})
+		offset := intArg(args, "offset", 0)
+		rows := paginate(table.Rows, offset, 0)
+		var fit [][]interface{}
+		if format == formatMarkdown {
+			fit = fitLines(rows, budgetFromArgs(args), obsidian.MarkdownRow)
+		} else {
+			fit = fitItems(rows, budgetFromArgs(args))
+		}
+		total := len(table.Rows)
+		table.Rows = fit
+
+		if format == formatMarkdown {
+			md := table.Markdown()
+			if next := offset + len(fit); next < total {
+				md += fmt.Sprintf("\n(showing rows %d-%d of %d; truncated, next_offset: %d)\n", offset+1, next, total, next)
+			}
+			return mcp.NewToolResultText(md), nil
+		}
+
+		resp := pageResponse("rows", fit, offset, total, len(fit) < len(rows))
+		resp["type"] = table.Type
+		resp["columns"] = table.Columns
+		return mcp.NewToolResultJSON(resp)
 	}
 }
 
@@ -225,17 +415,20 @@ func GetDailyNoteTool() mcp.Tool {
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithIdempotentHintAnnotation(true),
 		mcp.WithDescription("Get the content of today's daily note"),
+		withOffsetArg(contentOffsetDescription),
+		withBudgetArgs(),
 	)
 }
 
 // GetDailyNoteHandler returns the tool handler
 func GetDailyNoteHandler(client *obsidian.Client) server.ToolHandlerFunc {
-	return func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
 		content, err := client.Periodic.GetCurrentNote(ctx, "daily")
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get daily note: %v", err)), nil
 		}
-		return mcp.NewToolResultJSON(content)
+		return mcp.NewToolResultJSON(newNoteResponse(content, intArg(args, "offset", 0), budgetFromArgs(args)))
 	}
 }
 
@@ -245,8 +438,16 @@ func GetFileTool() mcp.Tool {
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithIdempotentHintAnnotation(true),
-		mcp.WithDescription("Get the content of a specific file in the vault"),
+		mcp.WithDescription("Get the content of a specific file in the vault. "+
+			"If no note exists at path, a note in the same folder whose name, alias or title is exactly that name is returned, as \"resolved\"; "+
+			"otherwise the error lists similar notes."),
 		mcp.WithString("path", mcp.Required(), mcp.Description("Path to the file")),
+		mcp.WithBoolean("expand_embeds", mcp.Description("Replace embedded notes (![[Note]], ![[Note#Heading]], ![[Note#^block]]) "+
+			"with their content, as Obsidian shows them; the embeds are listed in \"embeds\", with the reason for those left as they are. Embeds are expanded up to the size of the response")),
+		mcp.WithNumber("embed_depth", mcp.Description(fmt.Sprintf("Number of levels of nested embeds to expand (default %d, at most %d)",
+			obsidian.DefaultEmbedDepth, obsidian.MaxEmbedDepth))),
+		withOffsetArg(contentOffsetDescription),
+		withBudgetArgs(),
 	)
 }
 
@@ -256,11 +457,51 @@ func GetFileHandler(client *obsidian.Client) server.ToolHandlerFunc {
 		args := getArgs(request)
 		path, _ := args["path"].(string)
 		content, err := client.Vault.GetNote(ctx, path)
+		var resolved *obsidian.NoteMatch
+		if obsidian.IsNotFound(err) {
+			match, candidates, resolveErr := resolveMissingNote(ctx, client, path)
+			if resolveErr != nil || match == nil {
+				return mcp.NewToolResultError(notFoundMessage(err, candidates)), nil
+			}
+			resolved, path = match, match.Path
+			content, err = client.Vault.GetNote(ctx, path)
+		}
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get file: %v", err)), nil
 		}
-		return mcp.NewToolResultJSON(content)
+		offset, b := intArg(args, "offset", 0), budgetFromArgs(args)
+		var embeds []obsidian.Embed
+		if expand, _ := args["expand_embeds"].(bool); expand {
+			// Content past the page that is returned is not expanded.
+			embeds, err = expandEmbeds(ctx, client, path, content, intArg(args, "embed_depth", obsidian.DefaultEmbedDepth), max(offset, 0)+int(b))
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to expand embeds: %v", err)), nil
+			}
+		}
+		resp := newNoteResponse(content, offset, b)
+		resp.Embeds = embeds
+		resp.Resolved = resolved
+		return mcp.NewToolResultJSON(resp)
+	}
+}
+
+// expandEmbeds replaces the embeds in the body of the note at notePath
+// with what they embed, up to maxSize bytes of embedded content.
+func expandEmbeds(ctx context.Context, client *obsidian.Client, notePath string, note *obsidian.Note, depth, maxSize int) ([]obsidian.Embed, error) {
+	resolver, err := client.Vault.EmbedResolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolver.MaxDepth = min(max(depth, 0), obsidian.MaxEmbedDepth)
+	resolver.MaxSize = maxSize
+
+	_, body := obsidian.SplitFrontmatter(note.Content)
+	expanded, embeds, err := resolver.Expand(ctx, notePath, body)
+	if err != nil {
+		return nil, err
 	}
+	note.Content = note.Content[:len(note.Content)-len(body)] + expanded
+	return embeds, nil
 }
 
 // ListFilesTool returns the tool definition
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/bttk/bttk-mcp/pkg/obsidian"
@@ -103,6 +104,82 @@ func TestSearchSimple(t *testing.T) {
 	logMsg(t, res)
 }
 
+func TestSearchSimple_Paging(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "10", r.URL.Query().Get("contextLength"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `[
+			{"filename": "Work/low.md", "score": -3, "matches": []},
+			{"filename": "Work/high.md", "score": -1, "matches": [
+				{"context": "aaa", "match": {"start": 100, "end": 105}},
+				{"context": "bbb", "match": {"start": 110, "end": 115}},
+				{"context": "ccc", "match": {"start": 200, "end": 205}},
+				{"context": "ddd", "match": {"start": 300, "end": 305}}
+			]},
+			{"filename": "Work/mid.md", "score": -2, "matches": []},
+			{"filename": "Work/image.png", "score": 0, "matches": []},
+			{"filename": "Home/other.md", "score": 0, "matches": []}
+		]`)
+	}
+
+	res := testTool(t, SearchSimpleTool(), SearchSimpleHandler, "obsidian_search_simple", map[string]interface{}{
+		"query":                "test",
+		"context_length":       10,
+		"folder":               "Work",
+		"extensions":           []interface{}{".md"},
+		"limit":                2,
+		"max_matches_per_file": 2,
+	}, handler)
+	logMsg(t, res)
+
+	var resp struct {
+		Results    []obsidian.SearchResult `json:"results"`
+		Total      int                     `json:"total"`
+		NextOffset int                     `json:"next_offset"`
+	}
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &resp))
+
+	assert.Equal(t, 3, resp.Total)
+	assert.Equal(t, 2, resp.NextOffset)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, "Work/high.md", resp.Results[0].Filename)
+	assert.Equal(t, "Work/mid.md", resp.Results[1].Filename)
+
+	// The second match overlaps the context of the first one and is dropped.
+	require.Len(t, resp.Results[0].Matches, 2)
+	assert.Equal(t, "aaa", resp.Results[0].Matches[0].Context)
+	assert.Equal(t, "ccc", resp.Results[0].Matches[1].Context)
+
+	res = testTool(t, SearchSimpleTool(), SearchSimpleHandler, "obsidian_search_simple", map[string]interface{}{
+		"query":          "test",
+		"context_length": 10,
+		"folder":         "Work",
+		"extensions":     []interface{}{"md"},
+		"offset":         2,
+	}, handler)
+	text, ok = res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, `"filename":"Work/low.md"`)
+	assert.NotContains(t, text.Text, "next_offset")
+
+	// As with limit, 0 matches per file means all of them.
+	res = testTool(t, SearchSimpleTool(), SearchSimpleHandler, "obsidian_search_simple", map[string]interface{}{
+		"query":                "test",
+		"context_length":       10,
+		"folder":               "Work",
+		"extensions":           []interface{}{"md"},
+		"limit":                1,
+		"max_matches_per_file": 0,
+	}, handler)
+	text, ok = res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &resp))
+	require.Len(t, resp.Results, 1)
+	assert.Len(t, resp.Results[0].Matches, 3)
+}
+
 func TestSearchJSONLogic(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, http.MethodPost, r.Method)
@@ -119,6 +196,98 @@ func TestSearchJSONLogic(t *testing.T) {
 	}, handler)
 }
 
+func TestSearchJSONLogic_Invalid(t *testing.T) {
+	handler := func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("invalid query should not be sent")
+	}
+
+	res := testTool(t, SearchJSONLogicTool(), SearchJSONLogicHandler, "obsidian_search_json_logic", map[string]interface{}{
+		"query": `{"===": [{"var": "title"}, "x"]}`,
+	}, handler)
+	assert.True(t, res.IsError)
+}
+
+func TestFindNotes(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/search/", r.URL.Path)
+
+		var query map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+		assert.Contains(t, query, "and")
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `[{"filename": "Work/a.md", "result": true}]`)
+	}
+
+	res := testTool(t, FindNotesTool(), FindNotesHandler, "obsidian_find_notes", map[string]interface{}{
+		"tags":           []interface{}{"project"},
+		"folder":         "Work",
+		"frontmatter":    map[string]interface{}{"status": "done"},
+		"modified_after": "2024-01-01",
+	}, handler)
+	logMsg(t, res)
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, `"files":["Work/a.md"]`)
+
+	res = testTool(t, FindNotesTool(), FindNotesHandler, "obsidian_find_notes", map[string]interface{}{
+		"modified_before": "yesterday",
+	}, handler)
+	assert.True(t, res.IsError)
+}
+
+func TestSearchDQL(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/search/", r.URL.Path)
+		assert.Equal(t, "application/vnd.olrapi.dataview.dql+txt", r.Header.Get("Content-Type"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `[{"filename": "test.md", "result": {"status": "done"}}]`)
+	}
+
+	res := testTool(t, SearchDQLTool(), SearchDQLHandler, "obsidian_search_dql", map[string]interface{}{
+		"query": "TABLE status",
+	}, handler)
+	logMsg(t, res)
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, `"columns":["File","status"]`)
+
+	res = testTool(t, SearchDQLTool(), SearchDQLHandler, "obsidian_search_dql", map[string]interface{}{
+		"query":  "TABLE status",
+		"format": "markdown",
+	}, handler)
+	text, ok = res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, "| [[test.md]] | done |")
+}
+
+func TestSearchDQL_MarkdownBudget(t *testing.T) {
+	// Pipes are escaped in Markdown cells, so the rows are larger as Markdown
+	// than as JSON.
+	value := strings.Repeat("|", 100)
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		results := make([]map[string]interface{}, 20)
+		for i := range results {
+			results[i] = map[string]interface{}{"filename": fmt.Sprintf("%d.md", i), "result": map[string]string{"v": value}}
+		}
+		_ = json.NewEncoder(w).Encode(results)
+	}
+
+	res := testTool(t, SearchDQLTool(), SearchDQLHandler, "obsidian_search_dql", map[string]interface{}{
+		"query":     "TABLE v",
+		"format":    "markdown",
+		"max_bytes": 1000,
+	}, handler)
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, "truncated, next_offset: 4")
+	assert.LessOrEqual(t, len(text.Text), 1100)
+}
+
 func TestGetDailyNote(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, http.MethodGet, r.Method)
@@ -144,6 +313,81 @@ func TestGetFile(t *testing.T) {
 	}, handler)
 }
 
+func TestGetFile_Truncated(t *testing.T) {
+	content := strings.Repeat("A paragraph of text.\n\n", 50)
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(obsidian.Note{Path: "big.md", Content: content})
+	}
+
+	res := testTool(t, GetFileTool(), GetFileHandler, "obsidian_get_file", map[string]interface{}{
+		"path":       "big.md",
+		"max_tokens": 100,
+	}, handler)
+	logMsg(t, res)
+
+	var resp struct {
+		Content    string `json:"content"`
+		Truncated  bool   `json:"truncated"`
+		NextOffset int    `json:"next_offset"`
+	}
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &resp))
+	assert.True(t, resp.Truncated)
+	assert.LessOrEqual(t, len(text.Text), 400)
+	assert.Equal(t, content[:resp.NextOffset], resp.Content)
+
+	res = testTool(t, GetFileTool(), GetFileHandler, "obsidian_get_file", map[string]interface{}{
+		"path":   "big.md",
+		"offset": resp.NextOffset,
+	}, handler)
+	text, ok = res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &resp))
+	assert.False(t, resp.Truncated)
+	assert.Equal(t, content[len(content)-len(resp.Content):], resp.Content)
+}
+
+func TestGetFile_ExpandEmbeds(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"files": ["Meeting.md", "Agenda.md"]}`)
+		case "/vault/Meeting.md":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(obsidian.Note{Path: "Meeting.md", Content: "---\ntype: meeting\n---\n![[Agenda#Status]]\n\n![[Minutes]]\n"})
+		case "/vault/Agenda.md":
+			w.Header().Set("Content-Type", "text/markdown")
+			fmt.Fprint(w, "# Agenda\n\n## Status\n\nAll green.\n\n## Risks\n")
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	res := testTool(t, GetFileTool(), GetFileHandler, "obsidian_get_file", map[string]interface{}{
+		"path":          "Meeting.md",
+		"expand_embeds": true,
+	}, handler)
+	logMsg(t, res)
+	require.False(t, res.IsError)
+
+	var resp struct {
+		Content string           `json:"content"`
+		Embeds  []obsidian.Embed `json:"embeds"`
+	}
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &resp))
+	assert.Equal(t, "---\ntype: meeting\n---\n## Status\n\nAll green.\n\n![[Minutes]]\n", resp.Content)
+	assert.Equal(t, []obsidian.Embed{
+		{Target: "Agenda#Status", Path: "Agenda.md", Depth: 1},
+		{Target: "Minutes", Depth: 1, Error: obsidian.ErrEmbedNotFound.Error()},
+	}, resp.Embeds)
+}
+
 func TestListFiles(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, http.MethodGet, r.Method)
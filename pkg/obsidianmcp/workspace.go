@@ -0,0 +1,199 @@
+package obsidianmcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bttk/bttk-mcp/pkg/obsidian"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Obsidian command IDs used to control the workspace.
+const (
+	commandSplitVertical   = "workspace:split-vertical"
+	commandSplitHorizontal = "workspace:split-horizontal"
+	commandRevealFile      = "file-explorer:reveal-active-file"
+)
+
+const (
+	directionVertical   = "vertical"
+	directionHorizontal = "horizontal"
+)
+
+// executeIfAvailable executes the command if Obsidian has it. It returns
+// false (and no error) if the command is not available, e.g. because the
+// plugin providing it is disabled.
+func executeIfAvailable(ctx context.Context, client *obsidian.Client, commandID string) (bool, error) {
+	commands, err := client.Commands.List(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range commands {
+		if c.ID == commandID {
+			return true, client.Commands.Execute(ctx, commandID)
+		}
+	}
+	return false, nil
+}
+
+// OpenAtTool returns the tool definition
+func OpenAtTool() mcp.Tool {
+	return mcp.NewTool("obsidian_open_at",
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("Open a file in Obsidian UI, scrolled to a heading or block. "+
+			"A line number is mapped to the closest heading before it."),
+		mcp.WithString("path", mcp.Required(), mcp.Description("Path to the file")),
+		mcp.WithString("heading", mcp.Description("Heading to scroll to (use \"Heading#Subheading\" for nested headings, or \"^id\" for a block)")),
+		mcp.WithNumber("line", mcp.Description("1-based line to scroll to, if heading is not given")),
+		mcp.WithBoolean("new_leaf", mcp.Description("Open in a new leaf (tab)")),
+	)
+}
+
+// OpenAtHandler returns the tool handler
+func OpenAtHandler(client *obsidian.Client) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+		path := stringArg(args, "path")
+		heading := stringArg(args, "heading")
+		line := intArg(args, "line", 0)
+		newLeaf, _ := args["new_leaf"].(bool)
+
+		warnings := []string{}
+		if heading == "" && line > 0 {
+			content, err := client.Vault.Get(ctx, path)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to read file: %v", err)), nil
+			}
+			if h, ok := obsidian.HeadingAtLine(content, line); ok {
+				heading = h.Text
+				if h.Line != line {
+					warnings = append(warnings, fmt.Sprintf("Obsidian cannot scroll to a line; opened at heading %q on line %d", h.Text, h.Line))
+				}
+			} else {
+				warnings = append(warnings, fmt.Sprintf("no heading before line %d; opened at the top of the file", line))
+			}
+		}
+
+		if err := client.Open.FileAt(ctx, path, heading, newLeaf); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to open file: %v", err)), nil
+		}
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"opened":   path,
+			"heading":  heading,
+			"warnings": warnings,
+		})
+	}
+}
+
+// OpenSplitTool returns the tool definition
+func OpenSplitTool() mcp.Tool {
+	return mcp.NewTool("obsidian_open_split",
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(false),
+		mcp.WithDescription("Open a file in a new split next to the active tab in Obsidian UI"),
+		mcp.WithString("path", mcp.Required(), mcp.Description("Path to the file")),
+		mcp.WithString("direction", mcp.Description("Split direction"),
+			mcp.Enum(directionVertical, directionHorizontal), mcp.DefaultString(directionVertical)),
+	)
+}
+
+// OpenSplitHandler returns the tool handler
+func OpenSplitHandler(client *obsidian.Client) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+		path := stringArg(args, "path")
+
+		commandID := commandSplitVertical
+		if stringArg(args, "direction") == directionHorizontal {
+			commandID = commandSplitHorizontal
+		}
+
+		warnings := []string{}
+		split, err := executeIfAvailable(ctx, client, commandID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to split: %v", err)), nil
+		}
+		if !split {
+			warnings = append(warnings, fmt.Sprintf("command %s is not available; opened in a new tab instead", commandID))
+		}
+
+		// After a split the new pane is active, so open the file there.
+		if err := client.Open.File(ctx, path, !split); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to open file: %v", err)), nil
+		}
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"opened":   path,
+			"split":    split,
+			"warnings": warnings,
+		})
+	}
+}
+
+// RevealFileTool returns the tool definition
+func RevealFileTool() mcp.Tool {
+	return mcp.NewTool("obsidian_reveal_file",
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("Open a file in Obsidian UI and reveal it in the file explorer"),
+		mcp.WithString("path", mcp.Required(), mcp.Description("Path to the file")),
+	)
+}
+
+// RevealFileHandler returns the tool handler
+func RevealFileHandler(client *obsidian.Client) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := getArgs(request)
+		path := stringArg(args, "path")
+
+		if err := client.Open.File(ctx, path, false); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to open file: %v", err)), nil
+		}
+
+		warnings := []string{}
+		revealed, err := executeIfAvailable(ctx, client, commandRevealFile)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to reveal file: %v", err)), nil
+		}
+		if !revealed {
+			warnings = append(warnings, fmt.Sprintf("command %s is not available (is the File explorer plugin enabled?); the file was only opened", commandRevealFile))
+		}
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"opened":   path,
+			"revealed": revealed,
+			"warnings": warnings,
+		})
+	}
+}
+
+// GetWorkspaceTool returns the tool definition
+func GetWorkspaceTool() mcp.Tool {
+	return mcp.NewTool("obsidian_get_workspace",
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDescription("Get the layout of the Obsidian workspace: open tabs, the active tab and recently opened files"),
+	)
+}
+
+// GetWorkspaceHandler returns the tool handler
+func GetWorkspaceHandler(client *obsidian.Client) server.ToolHandlerFunc {
+	return func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		layout, err := client.Workspace.Layout(ctx)
+		if err == nil {
+			return mcp.NewToolResultJSON(layout)
+		}
+
+		// Fall back to the active file, which the REST API always knows.
+		note, activeErr := client.ActiveFile.GetNote(ctx)
+		if activeErr != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get workspace: %v", err)), nil
+		}
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"tabs":        []obsidian.WorkspaceLeaf{},
+			"active_leaf": obsidian.WorkspaceLeaf{Type: "markdown", File: note.Path, Visible: true, Active: true},
+			"warnings":    []string{fmt.Sprintf("workspace layout is unavailable (%v); only the active file is known", err)},
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package obsidianmcp
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAt_Line(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/note.md":
+			fmt.Fprint(w, "# Title\n\ntext\n\n## Tasks\n\n- one\n- two\n")
+		case "/open/note.md#Tasks":
+			assert.Equal(t, http.MethodPost, r.Method)
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	res := testTool(t, OpenAtTool(), OpenAtHandler, "obsidian_open_at", map[string]interface{}{
+		"path": "note.md",
+		"line": 7,
+	}, handler)
+	logMsg(t, res)
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, `"heading":"Tasks"`)
+}
+
+func TestOpenSplit_Fallback(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/commands/":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"commands": [{"id": "editor:save-file", "name": "Save"}]}`)
+		case "/open/note.md":
+			assert.Equal(t, "true", r.URL.Query().Get("newLeaf"))
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	res := testTool(t, OpenSplitTool(), OpenSplitHandler, "obsidian_open_split", map[string]interface{}{
+		"path": "note.md",
+	}, handler)
+	logMsg(t, res)
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, `"split":false`)
+	assert.Contains(t, text.Text, commandSplitVertical)
+}
+
+func TestRevealFile(t *testing.T) {
+	executed := false
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/commands/":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"commands": [{"id": %q, "name": "Reveal"}]}`, commandRevealFile)
+		case "/commands/" + commandRevealFile + "/":
+			executed = true
+			w.WriteHeader(http.StatusNoContent)
+		case "/open/note.md":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	res := testTool(t, RevealFileTool(), RevealFileHandler, "obsidian_reveal_file", map[string]interface{}{
+		"path": "note.md",
+	}, handler)
+	logMsg(t, res)
+	assert.True(t, executed)
+}
+
+func TestGetWorkspace_Fallback(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vault/.obsidian/workspace.json":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errorCode": 40400, "message": "File not found"}`)
+		case "/active/":
+			w.Header().Set("Content-Type", "application/vnd.olrapi.note+json")
+			fmt.Fprint(w, `{"path": "note.md", "content": "", "tags": [], "frontmatter": {}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	res := testTool(t, GetWorkspaceTool(), GetWorkspaceHandler, "obsidian_get_workspace", map[string]interface{}{}, handler)
+	logMsg(t, res)
+	text, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, `"file":"note.md"`)
+	assert.Contains(t, text.Text, "warnings")
+}